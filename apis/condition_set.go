@@ -20,6 +20,7 @@ import (
 	"reflect"
 	"sort"
 	"time"
+	"unicode/utf8"
 
 	"fmt"
 
@@ -43,6 +44,26 @@ type ConditionsAccessor interface {
 type ConditionSet struct {
 	happy      ConditionType
 	dependents []ConditionType
+	limits     ConditionLimits
+}
+
+// ConditionLimits bounds the growth of the Conditions managed through a
+// ConditionSet, for resources that accumulate many transient condition types.
+// The zero value imposes no limits.
+// +k8s:deepcopy-gen=false
+type ConditionLimits struct {
+	// MaxConditions caps the number of conditions.  When it is exceeded the
+	// non-terminal conditions with the oldest LastTransitionTime are dropped.
+	// Terminal conditions are never dropped.
+	MaxConditions int
+
+	// MaxMessageLength is the maximum length (in bytes) of condition
+	// messages; longer messages are truncated.
+	MaxMessageLength int
+
+	// StaleUnknownTTL is the age after which non-terminal conditions with an
+	// Unknown status are removed by PruneConditions.
+	StaleUnknownTTL time.Duration
 }
 
 // ConditionManager allows a resource to operate on its Conditions using higher
@@ -111,6 +132,13 @@ func newConditionSet(happy ConditionType, dependents ...ConditionType) Condition
 	}
 }
 
+// WithLimits returns a copy of the ConditionSet that enforces the provided
+// limits on the Conditions it manages.
+func (r ConditionSet) WithLimits(limits ConditionLimits) ConditionSet {
+	r.limits = limits
+	return r
+}
+
 func contains(ct []ConditionType, t ConditionType) bool {
 	for _, c := range ct {
 		if c == t {
@@ -170,6 +198,7 @@ func (r conditionsImpl) SetCondition(new Condition) {
 		return
 	}
 	t := new.Type
+	new.Message = r.truncateMessage(new.Message)
 	var conditions Conditions
 	for _, c := range r.accessor.GetConditions() {
 		if c.Type != t {
@@ -183,12 +212,89 @@ func (r conditionsImpl) SetCondition(new Condition) {
 		}
 	}
 	new.LastTransitionTime = VolatileTime{Inner: metav1.NewTime(time.Now())}
-	conditions = append(conditions, new)
+	conditions = r.enforceMaxConditions(append(conditions, new))
 	// Sorted for convenience of the consumer, i.e. kubectl.
 	sort.Slice(conditions, func(i, j int) bool { return conditions[i].Type < conditions[j].Type })
 	r.accessor.SetConditions(conditions)
 }
 
+// PruneConditions removes the non-terminal conditions of status that have
+// been Unknown for longer than the StaleUnknownTTL, and enforces the
+// MaxConditions and MaxMessageLength limits of the ConditionSet on its
+// existing Conditions.
+func (r ConditionSet) PruneConditions(status ConditionsAccessor) {
+	conditionsImpl{ConditionSet: r, accessor: status}.pruneConditions()
+}
+
+func (r conditionsImpl) pruneConditions() {
+	if r.accessor == nil {
+		return
+	}
+	now := time.Now()
+	var conditions Conditions
+	for _, c := range r.accessor.GetConditions() {
+		if r.limits.StaleUnknownTTL > 0 && !r.isTerminal(c.Type) && c.IsUnknown() &&
+			now.Sub(c.LastTransitionTime.Inner.Time) > r.limits.StaleUnknownTTL {
+			continue
+		}
+		c.Message = r.truncateMessage(c.Message)
+		conditions = append(conditions, c)
+	}
+	conditions = r.enforceMaxConditions(conditions)
+	// Sorted for convenience of the consumer, i.e. kubectl.
+	sort.Slice(conditions, func(i, j int) bool { return conditions[i].Type < conditions[j].Type })
+	r.accessor.SetConditions(conditions)
+}
+
+// truncateMessage shortens the message to MaxMessageLength bytes, taking
+// care not to split a multi-byte character.
+func (r conditionsImpl) truncateMessage(msg string) string {
+	max := r.limits.MaxMessageLength
+	if max <= 0 || len(msg) <= max {
+		return msg
+	}
+	for max > 0 && !utf8.RuneStart(msg[max]) {
+		max--
+	}
+	return msg[:max]
+}
+
+// enforceMaxConditions drops the oldest non-terminal conditions until
+// there are no more than MaxConditions left.
+func (r conditionsImpl) enforceMaxConditions(conditions Conditions) Conditions {
+	excess := len(conditions) - r.limits.MaxConditions
+	if r.limits.MaxConditions <= 0 || excess <= 0 {
+		return conditions
+	}
+	var candidates Conditions
+	for _, c := range conditions {
+		if !r.isTerminal(c.Type) {
+			candidates = append(candidates, c)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		lhs, rhs := candidates[i].LastTransitionTime.Inner, candidates[j].LastTransitionTime.Inner
+		if !lhs.Equal(&rhs) {
+			return lhs.Before(&rhs)
+		}
+		return candidates[i].Type < candidates[j].Type
+	})
+	if excess > len(candidates) {
+		excess = len(candidates)
+	}
+	drop := make(map[ConditionType]struct{}, excess)
+	for _, c := range candidates[:excess] {
+		drop[c.Type] = struct{}{}
+	}
+	kept := make(Conditions, 0, len(conditions)-excess)
+	for _, c := range conditions {
+		if _, ok := drop[c.Type]; !ok {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
 func (r conditionsImpl) isTerminal(t ConditionType) bool {
 	for _, cond := range r.dependents {
 		if cond == t {
@@ -869,3 +869,96 @@ func TestRemoveNonTerminalConditions(t *testing.T) {
 		t.Error("IsHappy() = false, wanted true")
 	}
 }
+
+func TestConditionLimits(t *testing.T) {
+	set := NewLivingConditionSet("Foo").WithLimits(ConditionLimits{
+		MaxConditions:    3,
+		MaxMessageLength: 5,
+	})
+	status := &TestStatus{}
+	manager := set.Manage(status)
+
+	manager.InitializeConditions()
+	manager.MarkFalse("Transient1", "Reason", "message is too long")
+	if got, want := manager.GetCondition("Transient1").Message, "messa"; got != want {
+		t.Errorf("GetCondition(Transient1).Message = %q, wanted %q", got, want)
+	}
+
+	// Adding another non-terminal condition evicts the oldest one.
+	manager.MarkTrue("Transient2")
+	if got, want := len(status.c), 3; got != want {
+		t.Fatalf("len(conditions) = %d, wanted %d", got, want)
+	}
+	if c := manager.GetCondition("Transient1"); c != nil {
+		t.Errorf("GetCondition(Transient1) = %v, wanted nil", c)
+	}
+	for _, ct := range []ConditionType{ConditionReady, "Foo", "Transient2"} {
+		if c := manager.GetCondition(ct); c == nil {
+			t.Errorf("GetCondition(%s) = nil, wanted non-nil", ct)
+		}
+	}
+}
+
+func TestConditionLimitsTruncatesOnRuneBoundary(t *testing.T) {
+	set := NewLivingConditionSet().WithLimits(ConditionLimits{MaxMessageLength: 4})
+	status := &TestStatus{}
+	set.Manage(status).MarkFalse(ConditionReady, "Reason", "aaé")
+	if got, want := set.Manage(status).GetCondition(ConditionReady).Message, "aaé"; got != want {
+		t.Errorf("Message = %q, wanted %q", got, want)
+	}
+	set.Manage(status).MarkFalse(ConditionReady, "Reason", "aaaé")
+	if got, want := set.Manage(status).GetCondition(ConditionReady).Message, "aaa"; got != want {
+		t.Errorf("Message = %q, wanted %q", got, want)
+	}
+}
+
+func TestPruneConditions(t *testing.T) {
+	set := NewLivingConditionSet("Foo").WithLimits(ConditionLimits{
+		StaleUnknownTTL:  time.Hour,
+		MaxMessageLength: 3,
+	})
+	stale := VolatileTime{Inner: metav1.NewTime(time.Now().Add(-2 * time.Hour))}
+	fresh := VolatileTime{Inner: metav1.NewTime(time.Now())}
+	status := &TestStatus{c: Conditions{{
+		Type:               ConditionReady,
+		Status:             corev1.ConditionUnknown,
+		LastTransitionTime: stale,
+	}, {
+		Type:               "Foo",
+		Status:             corev1.ConditionUnknown,
+		LastTransitionTime: stale,
+	}, {
+		Type:               "StaleUnknown",
+		Status:             corev1.ConditionUnknown,
+		LastTransitionTime: stale,
+	}, {
+		Type:               "StaleFalse",
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: stale,
+		Message:            "too long",
+	}, {
+		Type:               "FreshUnknown",
+		Status:             corev1.ConditionUnknown,
+		LastTransitionTime: fresh,
+	}}}
+
+	set.PruneConditions(status)
+
+	want := Conditions{{
+		Type:   "Foo",
+		Status: corev1.ConditionUnknown,
+	}, {
+		Type:   "FreshUnknown",
+		Status: corev1.ConditionUnknown,
+	}, {
+		Type:   ConditionReady,
+		Status: corev1.ConditionUnknown,
+	}, {
+		Type:    "StaleFalse",
+		Status:  corev1.ConditionFalse,
+		Message: "too",
+	}}
+	if diff := cmp.Diff(want, status.c, ignoreFields); diff != "" {
+		t.Errorf("PruneConditions (-want, +got) = %v", diff)
+	}
+}
@@ -69,24 +69,46 @@ func IsInUpdate(ctx context.Context) bool {
 	return ctx.Value(inUpdateKey{}) != nil
 }
 
-// IsInStatusUpdate checks whether the context is an Update.
+// IsInStatusUpdate checks whether the context is an Update of the
+// status subresource.
 func IsInStatusUpdate(ctx context.Context) bool {
+	return GetSubResource(ctx) == "status"
+}
+
+// GetSubResource returns the subresource being updated (e.g. "status"
+// or "scale"), or the empty string when we are not within an update
+// context or the update targets the main resource.
+func GetSubResource(ctx context.Context) string {
 	value := ctx.Value(inUpdateKey{})
 	if value == nil {
-		return false
+		return ""
 	}
-	up := value.(*updatePayload)
-	return up.subresource == "status"
+	return value.(*updatePayload).subresource
+}
+
+// This is attached to contexts passed to webhook interfaces when
+// the receiver being validated is being deleted.
+type inDeleteKey struct{}
+
+// WithinDelete is used to note that the webhook is calling within
+// the context of a Delete operation.  The base is the object being
+// deleted, which is retrievable via GetBaseline.
+func WithinDelete(ctx context.Context, base interface{}) context.Context {
+	return context.WithValue(ctx, inDeleteKey{}, base)
+}
+
+// IsInDelete checks whether the context is a Delete.
+func IsInDelete(ctx context.Context) bool {
+	return ctx.Value(inDeleteKey{}) != nil
 }
 
-// GetBaseline returns the baseline of the update, or nil when we
-// are not within an update context.
+// GetBaseline returns the baseline of the update, or the object being
+// deleted within a delete context, or nil when we are in neither.
 func GetBaseline(ctx context.Context) interface{} {
-	value := ctx.Value(inUpdateKey{})
-	if value == nil {
-		return nil
+	if value := ctx.Value(inUpdateKey{}); value != nil {
+		return value.(*updatePayload).base
 	}
-	return value.(*updatePayload).base
+	return ctx.Value(inDeleteKey{})
 }
 
 // This is attached to contexts passed to webhook interfaces when
@@ -88,6 +88,26 @@ func TestContexts(t *testing.T) {
 		ctx:   WithinCreate(ctx),
 		check: IsInUpdate,
 		want:  false,
+	}, {
+		name:  "is in delete",
+		ctx:   WithinDelete(ctx, struct{}{}),
+		check: IsInDelete,
+		want:  true,
+	}, {
+		name:  "not in delete (bare)",
+		ctx:   ctx,
+		check: IsInDelete,
+		want:  false,
+	}, {
+		name:  "not in delete (update)",
+		ctx:   WithinUpdate(ctx, struct{}{}),
+		check: IsInDelete,
+		want:  false,
+	}, {
+		name:  "not in update (delete)",
+		ctx:   WithinDelete(ctx, struct{}{}),
+		check: IsInUpdate,
+		want:  false,
 	}, {
 		name:  "in spec",
 		ctx:   WithinSpec(ctx),
@@ -143,6 +163,48 @@ func TestGetBaseline(t *testing.T) {
 	if want, got := foo, GetBaseline(ctx); got != want {
 		t.Errorf("GetBaseline() = %v, wanted %v", got, want)
 	}
+
+	var bar interface{} = "this is the deleted object"
+	ctx = WithinDelete(context.Background(), bar)
+
+	if want, got := bar, GetBaseline(ctx); got != want {
+		t.Errorf("GetBaseline() = %v, wanted %v", got, want)
+	}
+}
+
+func TestGetSubResource(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{{
+		name: "bare",
+		ctx:  ctx,
+	}, {
+		name: "create",
+		ctx:  WithinCreate(ctx),
+	}, {
+		name: "main resource update",
+		ctx:  WithinUpdate(ctx, struct{}{}),
+	}, {
+		name: "status update",
+		ctx:  WithinSubResourceUpdate(ctx, struct{}{}, "status"),
+		want: "status",
+	}, {
+		name: "scale update",
+		ctx:  WithinSubResourceUpdate(ctx, struct{}{}, "scale"),
+		want: "scale",
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := GetSubResource(tc.ctx); got != tc.want {
+				t.Errorf("GetSubResource() = %q, wanted %q", got, tc.want)
+			}
+		})
+	}
 }
 
 func TestGetUserInfo(t *testing.T) {
@@ -44,13 +44,13 @@ func CheckDeprecatedUpdate(ctx context.Context, obj interface{}, original interf
 	var errs *FieldError
 	objFields, objInlined := getPrefixedNamedFieldValues(deprecatedPrefix, obj)
 
-	if nonZero(reflect.ValueOf(original)) {
+	if NonZero(reflect.ValueOf(original)) {
 		originalFields, originalInlined := getPrefixedNamedFieldValues(deprecatedPrefix, original)
 
 		// We only have to walk obj Fields because the assumption is that obj
 		// and original are of the same type.
 		for name, value := range objFields {
-			if nonZero(value) {
+			if NonZero(value) {
 				if differ(originalFields[name], value) {
 					// Not allowed to update the value.
 					errs = errs.Also(ErrDisallowedUpdateDeprecatedFields(name))
@@ -65,7 +65,7 @@ func CheckDeprecatedUpdate(ctx context.Context, obj interface{}, original interf
 		}
 	} else {
 		for name, value := range objFields {
-			if nonZero(value) {
+			if NonZero(value) {
 				// Not allowed to set the value.
 				errs = errs.Also(ErrDisallowedFields(name))
 			}
@@ -131,21 +131,26 @@ func getInterface(a reflect.Value) interface{} {
 	}
 }
 
-// nonZero returns true if a is nil or reflect.Zero.
-func nonZero(a reflect.Value) bool {
+// NonZero returns false if a is nil or reflect.Zero, and is how
+// CheckDeprecated decides whether a field is set.
+func NonZero(a reflect.Value) bool {
 	switch a.Kind() {
 	case reflect.Ptr:
 		if a.IsNil() {
 			return false
 		}
-		return nonZero(a.Elem())
+		return NonZero(a.Elem())
 
-	case reflect.Map, reflect.Slice, reflect.Array:
+	case reflect.Map, reflect.Slice:
 		if a.IsNil() {
 			return false
 		}
 		return true
 
+	// Arrays cannot be nil, so they are set unless they are all zero.
+	case reflect.Array:
+		return !reflect.DeepEqual(a.Interface(), reflect.Zero(a.Type()).Interface())
+
 	// This is a nil interface{} type.
 	case reflect.Invalid:
 		return false
@@ -0,0 +1,74 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apis
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StashFields serializes the provided fields into the annotation named key on
+// the given object.  This is intended for use by Convertible implementations
+// when down-converting into a version that cannot represent some of the
+// fields of the source version, so that the data survives a round trip and
+// may be put back with RestoreFields when up-converting.
+//
+// When fields is nil or the zero value of its type the annotation is removed,
+// so that objects without anything to preserve are not littered with empty
+// annotations.
+func StashFields(obj metav1.Object, key string, fields interface{}) error {
+	annotations := obj.GetAnnotations()
+	if !NonZero(reflect.ValueOf(fields)) {
+		if _, ok := annotations[key]; ok {
+			delete(annotations, key)
+			obj.SetAnnotations(annotations)
+		}
+		return nil
+	}
+
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to serialize fields for annotation %q: %v", key, err)
+	}
+	if annotations == nil {
+		annotations = make(map[string]string, 1)
+	}
+	annotations[key] = string(b)
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+// RestoreFields deserializes the fields previously saved by StashFields from
+// the annotation named key on the given object into the value pointed to by
+// into, and then removes the annotation.  It returns false (and leaves into
+// untouched) when the object carries no such annotation.
+func RestoreFields(obj metav1.Object, key string, into interface{}) (bool, error) {
+	annotations := obj.GetAnnotations()
+	raw, ok := annotations[key]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal([]byte(raw), into); err != nil {
+		return false, fmt.Errorf("failed to restore fields from annotation %q: %v", key, err)
+	}
+	delete(annotations, key)
+	obj.SetAnnotations(annotations)
+	return true, nil
+}
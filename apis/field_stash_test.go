@@ -0,0 +1,147 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apis
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const stashKey = "conversion.knative.dev/v1beta1-lost-fields"
+
+type lostFields struct {
+	Foo string   `json:"foo,omitempty"`
+	Bar []string `json:"bar,omitempty"`
+}
+
+func TestStashRestoreFields(t *testing.T) {
+	tests := []struct {
+		name            string
+		fields          *lostFields
+		annotations     map[string]string
+		wantAnnotations map[string]string
+		wantRestored    bool
+	}{{
+		name:   "stash and restore",
+		fields: &lostFields{Foo: "foo", Bar: []string{"a", "b"}},
+		wantAnnotations: map[string]string{
+			stashKey: `{"foo":"foo","bar":["a","b"]}`,
+		},
+		wantRestored: true,
+	}, {
+		name:   "preserves other annotations",
+		fields: &lostFields{Foo: "foo"},
+		annotations: map[string]string{
+			"other": "value",
+		},
+		wantAnnotations: map[string]string{
+			"other":  "value",
+			stashKey: `{"foo":"foo"}`,
+		},
+		wantRestored: true,
+	}, {
+		name:   "nothing to stash",
+		fields: nil,
+	}, {
+		name:   "empty fields clear a stale annotation",
+		fields: &lostFields{},
+		annotations: map[string]string{
+			stashKey: `{"foo":"stale"}`,
+		},
+		wantAnnotations: map[string]string{},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			om := &metav1.ObjectMeta{Annotations: test.annotations}
+			if err := StashFields(om, stashKey, test.fields); err != nil {
+				t.Fatalf("StashFields() = %v", err)
+			}
+			if diff := cmp.Diff(test.wantAnnotations, om.Annotations); diff != "" {
+				t.Errorf("StashFields (-want, +got) = %v", diff)
+			}
+
+			got := &lostFields{}
+			restored, err := RestoreFields(om, stashKey, got)
+			if err != nil {
+				t.Fatalf("RestoreFields() = %v", err)
+			}
+			if restored != test.wantRestored {
+				t.Errorf("RestoreFields() = %v, wanted %v", restored, test.wantRestored)
+			}
+			if !restored {
+				return
+			}
+			if diff := cmp.Diff(test.fields, got); diff != "" {
+				t.Errorf("RestoreFields (-want, +got) = %v", diff)
+			}
+			if _, ok := om.Annotations[stashKey]; ok {
+				t.Errorf("RestoreFields() left annotation %q behind", stashKey)
+			}
+		})
+	}
+}
+
+func TestStashArrayFields(t *testing.T) {
+	tests := []struct {
+		name            string
+		fields          interface{}
+		wantAnnotations map[string]string
+	}{{
+		name:            "array",
+		fields:          [2]string{"a", "b"},
+		wantAnnotations: map[string]string{stashKey: `["a","b"]`},
+	}, {
+		name:            "pointer to array",
+		fields:          &[2]string{"a", ""},
+		wantAnnotations: map[string]string{stashKey: `["a",""]`},
+	}, {
+		name:   "zero array",
+		fields: [2]string{},
+	}, {
+		name:   "pointer to zero array",
+		fields: &[2]string{},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{}
+			if err := StashFields(obj, stashKey, test.fields); err != nil {
+				t.Fatalf("StashFields() = %v", err)
+			}
+			if diff := cmp.Diff(test.wantAnnotations, obj.GetAnnotations()); diff != "" {
+				t.Errorf("Annotations (-want, +got) = %s", diff)
+			}
+		})
+	}
+}
+
+func TestRestoreFieldsError(t *testing.T) {
+	om := &metav1.ObjectMeta{
+		Annotations: map[string]string{
+			stashKey: "not json",
+		},
+	}
+	if _, err := RestoreFields(om, stashKey, &lostFields{}); err == nil {
+		t.Error("RestoreFields() = nil, wanted error")
+	}
+	if _, ok := om.Annotations[stashKey]; !ok {
+		t.Errorf("RestoreFields() removed annotation %q on failure", stashKey)
+	}
+}
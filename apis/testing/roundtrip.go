@@ -0,0 +1,67 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	fuzz "github.com/google/gofuzz"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/apis"
+)
+
+// RoundTripIterations is the number of fuzzed objects checked by
+// CheckRoundTrip.
+const RoundTripIterations = 100
+
+// CheckRoundTrip fuzzes instances of the spoke's type and checks that
+// converting each up into the hub's type and back down yields the
+// original object.  Both spoke and hub must be pointers to structs; they
+// are only used for their types.  TypeMeta is ignored, since it is set
+// by the conversion webhook rather than the Convertible implementations.
+func CheckRoundTrip(ctx context.Context, t *testing.T, spoke, hub apis.Convertible, opts ...cmp.Option) {
+	t.Helper()
+	f := fuzz.New().NilChance(0.1).NumElements(0, 3).MaxDepth(10)
+	for i := 0; i < RoundTripIterations; i++ {
+		want := newLike(spoke)
+		f.Fuzz(want)
+
+		up := newLike(hub)
+		if err := want.ConvertUp(ctx, up); err != nil {
+			t.Fatalf("ConvertUp(%#v) = %v", want, err)
+		}
+		got := newLike(spoke)
+		if err := got.ConvertDown(ctx, up); err != nil {
+			t.Fatalf("ConvertDown(%#v) = %v", up, err)
+		}
+		if diff := cmp.Diff(want, got, append(opts, ignoreTypeMeta)...); diff != "" {
+			t.Fatalf("Round trip through %T (-want, +got) = %v", hub, diff)
+		}
+	}
+}
+
+var ignoreTypeMeta = cmpopts.IgnoreTypes(metav1.TypeMeta{})
+
+// newLike returns a new zero value of the type pointed to by c.
+func newLike(c apis.Convertible) apis.Convertible {
+	return reflect.New(reflect.TypeOf(c).Elem()).Interface().(apis.Convertible)
+}
@@ -20,6 +20,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"path"
+	"regexp"
+	"strings"
 )
 
 // URL is an alias of url.URL.
@@ -82,3 +85,79 @@ func (u *URL) URL() *url.URL {
 	url := url.URL(*u)
 	return &url
 }
+
+// JoinPath returns a copy of the URL with the provided path elements
+// appended to its path.  The elements are escaped as needed and the
+// result is cleaned, so that ".." elements cannot escape the existing
+// path prefix and duplicate slashes are collapsed.  A trailing slash on
+// the final element is preserved.
+func (u *URL) JoinPath(elem ...string) *URL {
+	if u == nil {
+		return nil
+	}
+	ret := u.DeepCopy()
+	if len(elem) == 0 {
+		return ret
+	}
+	// Clean the elements on their own first so that they may not walk
+	// above the path of the base URL.
+	joined := path.Join(append([]string{"/"}, elem...)...)
+	if strings.HasSuffix(elem[len(elem)-1], "/") && joined != "/" {
+		joined += "/"
+	}
+	ret.Path = strings.TrimSuffix(ret.Path, "/") + joined
+	ret.RawPath = ""
+	return ret
+}
+
+// WithQuery returns a copy of the URL with the query parameter key set to
+// the provided values, replacing any existing values for that key.  When
+// no values are provided the key is removed.  The receiver is not modified.
+func (u *URL) WithQuery(key string, values ...string) *URL {
+	if u == nil {
+		return nil
+	}
+	ret := u.DeepCopy()
+	q := ret.URL().Query()
+	if len(values) == 0 {
+		q.Del(key)
+	} else {
+		q[key] = append([]string(nil), values...)
+	}
+	ret.RawQuery = q.Encode()
+	return ret
+}
+
+// templateVar matches the simple "{name}" variables accepted by ExpandURL.
+var templateVar = regexp.MustCompile(`{([^{}]+)}`)
+
+// ExpandURL replaces the "{name}" variables in the given template with the
+// corresponding values from vars and parses the result as a URL.  Values
+// substituted before the query are path escaped, while values substituted
+// within the query are query escaped.  It is an error for the template to
+// reference a variable that is not present in vars.
+func ExpandURL(template string, vars map[string]string) (*URL, error) {
+	var missing []string
+	expand := func(s string, escape func(string) string) string {
+		return templateVar.ReplaceAllStringFunc(s, func(m string) string {
+			name := m[1 : len(m)-1]
+			v, ok := vars[name]
+			if !ok {
+				missing = append(missing, name)
+				return m
+			}
+			return escape(v)
+		})
+	}
+
+	var expanded string
+	if i := strings.Index(template, "?"); i >= 0 {
+		expanded = expand(template[:i], url.PathEscape) + "?" + expand(template[i+1:], url.QueryEscape)
+	} else {
+		expanded = expand(template, url.PathEscape)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing values for URL template variables: %s", strings.Join(missing, ", "))
+	}
+	return ParseURL(expanded)
+}
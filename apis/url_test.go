@@ -463,3 +463,169 @@ func TestURLString(t *testing.T) {
 		})
 	}
 }
+
+func TestJoinPath(t *testing.T) {
+	testCases := map[string]struct {
+		base string
+		elem []string
+		want string
+	}{
+		"no elements": {
+			base: "http://example.com/foo",
+			want: "http://example.com/foo",
+		},
+		"simple": {
+			base: "http://example.com/foo",
+			elem: []string{"bar", "baz"},
+			want: "http://example.com/foo/bar/baz",
+		},
+		"trailing slash on base": {
+			base: "http://example.com/foo/",
+			elem: []string{"bar"},
+			want: "http://example.com/foo/bar",
+		},
+		"trailing slash preserved": {
+			base: "http://example.com",
+			elem: []string{"bar/"},
+			want: "http://example.com/bar/",
+		},
+		"cannot escape base": {
+			base: "http://example.com/foo",
+			elem: []string{"../../bar"},
+			want: "http://example.com/foo/bar",
+		},
+		"escaped": {
+			base: "http://example.com/foo?a=b",
+			elem: []string{"a b"},
+			want: "http://example.com/foo/a%20b?a=b",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			base, err := ParseURL(tc.base)
+			if err != nil {
+				t.Fatalf("ParseURL() = %v", err)
+			}
+			before := base.String()
+			got := base.JoinPath(tc.elem...)
+			if diff := cmp.Diff(tc.want, got.String()); diff != "" {
+				t.Errorf("JoinPath (-want, +got) = %v", diff)
+			}
+			if base.String() != before {
+				t.Errorf("JoinPath modified the receiver: %s, want %s", base, before)
+			}
+		})
+	}
+
+	var nilURL *URL
+	if got := nilURL.JoinPath("foo"); got != nil {
+		t.Errorf("JoinPath() on nil = %v, wanted nil", got)
+	}
+}
+
+func TestWithQuery(t *testing.T) {
+	testCases := map[string]struct {
+		base   string
+		key    string
+		values []string
+		want   string
+	}{
+		"add": {
+			base:   "http://example.com/foo",
+			key:    "a",
+			values: []string{"b"},
+			want:   "http://example.com/foo?a=b",
+		},
+		"replace": {
+			base:   "http://example.com/foo?a=b&c=d",
+			key:    "a",
+			values: []string{"x", "y"},
+			want:   "http://example.com/foo?a=x&a=y&c=d",
+		},
+		"remove": {
+			base: "http://example.com/foo?a=b&c=d",
+			key:  "a",
+			want: "http://example.com/foo?c=d",
+		},
+		"escaped": {
+			base:   "http://example.com",
+			key:    "q",
+			values: []string{"a&b"},
+			want:   "http://example.com?q=a%26b",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			base, err := ParseURL(tc.base)
+			if err != nil {
+				t.Fatalf("ParseURL() = %v", err)
+			}
+			got := base.WithQuery(tc.key, tc.values...)
+			if diff := cmp.Diff(tc.want, got.String()); diff != "" {
+				t.Errorf("WithQuery (-want, +got) = %v", diff)
+			}
+			if base.String() != tc.base {
+				t.Errorf("WithQuery modified the receiver: %s, want %s", base, tc.base)
+			}
+		})
+	}
+}
+
+func TestExpandURL(t *testing.T) {
+	testCases := map[string]struct {
+		template string
+		vars     map[string]string
+		want     string
+		wantErr  string
+	}{
+		"no variables": {
+			template: "http://example.com/foo",
+			want:     "http://example.com/foo",
+		},
+		"path and host": {
+			template: "http://{name}.{namespace}.svc/{path}",
+			vars: map[string]string{
+				"name":      "foo",
+				"namespace": "bar",
+				"path":      "a b",
+			},
+			want: "http://foo.bar.svc/a%20b",
+		},
+		"path escaping": {
+			template: "http://example.com/{path}",
+			vars:     map[string]string{"path": "a/b"},
+			want:     "http://example.com/a%2Fb",
+		},
+		"query escaping": {
+			template: "http://example.com/{path}?q={q}",
+			vars: map[string]string{
+				"path": "foo",
+				"q":    "a&b=c",
+			},
+			want: "http://example.com/foo?q=a%26b%3Dc",
+		},
+		"missing variable": {
+			template: "http://{name}.{namespace}.svc",
+			vars:     map[string]string{"name": "foo"},
+			wantErr:  "missing values for URL template variables: namespace",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			got, err := ExpandURL(tc.template, tc.vars)
+			if tc.wantErr != "" || err != nil {
+				var gotErr string
+				if err != nil {
+					gotErr = err.Error()
+				}
+				if diff := cmp.Diff(tc.wantErr, gotErr); diff != "" {
+					t.Errorf("unexpected error (-want, +got) = %v", diff)
+				}
+				return
+			}
+			if diff := cmp.Diff(tc.want, got.String()); diff != "" {
+				t.Errorf("ExpandURL (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package apis
 
 import (
+	"time"
+
+	"github.com/google/go-cmp/cmp"
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -37,6 +40,53 @@ func (t *VolatileTime) UnmarshalJSON(b []byte) error {
 	return t.Inner.UnmarshalJSON(b)
 }
 
+// IsVolatile marks VolatileTime as a type whose values should never produce
+// differences.  This is what makes kmp.SafeDiff and friends ignore it.
+func (t VolatileTime) IsVolatile() bool {
+	return true
+}
+
+// IsZero returns whether the inner time is unset.
+func (t VolatileTime) IsZero() bool {
+	return t.Inner.IsZero()
+}
+
+// Before reports whether the time instant t is before u.
+func (t VolatileTime) Before(u VolatileTime) bool {
+	return t.Inner.Time.Before(u.Inner.Time)
+}
+
+// After reports whether the time instant t is after u.
+func (t VolatileTime) After(u VolatileTime) bool {
+	return t.Inner.Time.After(u.Inner.Time)
+}
+
+// Sub returns the duration t-u.
+func (t VolatileTime) Sub(u VolatileTime) time.Duration {
+	return t.Inner.Time.Sub(u.Inner.Time)
+}
+
+// SameInstant reports whether t and u represent the same time instant.  Unlike
+// semantic equality, which always treats VolatileTimes as equivalent, this
+// actually compares the times.
+func (t VolatileTime) SameInstant(u VolatileTime) bool {
+	return t.Inner.Time.Equal(u.Inner.Time)
+}
+
+// RoundTripEqual reports whether t and u are equal at the (second) precision
+// with which VolatileTime is serialized, which is what survives a round trip
+// through JSON.
+func (t VolatileTime) RoundTripEqual(u VolatileTime) bool {
+	return t.Inner.Rfc3339Copy().Time.Equal(u.Inner.Rfc3339Copy().Time)
+}
+
+// VolatileTimeRoundTripEquality is a cmp.Option comparing VolatileTimes with
+// RoundTripEqual, for use in fuzzed round-trip tests where sub-second
+// precision is lost through serialization.
+var VolatileTimeRoundTripEquality = cmp.Comparer(func(a, b VolatileTime) bool {
+	return a.RoundTripEqual(b)
+})
+
 func init() {
 	equality.Semantic.AddFunc(
 		// Always treat VolatileTime fields as equivalent.
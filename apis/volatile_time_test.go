@@ -21,8 +21,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/kmp"
 )
 
 type testType struct {
@@ -85,3 +87,74 @@ func TestVolatileTimeEquality(t *testing.T) {
 		t.Error("equality.Semantic.DeepEqual() = false, wanted true")
 	}
 }
+
+func TestVolatileTimeComparisons(t *testing.T) {
+	early := VolatileTime{metav1.NewTime(time.Unix(1024, 0))}
+	late := VolatileTime{metav1.NewTime(time.Unix(2048, 0))}
+
+	if !early.Before(late) {
+		t.Error("Before() = false, wanted true")
+	}
+	if early.After(late) {
+		t.Error("After() = true, wanted false")
+	}
+	if got, want := late.Sub(early), 1024*time.Second; got != want {
+		t.Errorf("Sub() = %v, wanted %v", got, want)
+	}
+	if early.SameInstant(late) {
+		t.Error("SameInstant() = true, wanted false")
+	}
+	if !early.SameInstant(VolatileTime{metav1.NewTime(time.Unix(1024, 0).In(time.FixedZone("x", 3600)))}) {
+		t.Error("SameInstant() = false, wanted true")
+	}
+	if early.IsZero() {
+		t.Error("IsZero() = true, wanted false")
+	}
+	if !(VolatileTime{}).IsZero() {
+		t.Error("IsZero() = false, wanted true")
+	}
+}
+
+func TestVolatileTimeRoundTripEquality(t *testing.T) {
+	tt := testType{
+		LastTransitionTime: VolatileTime{metav1.NewTime(time.Unix(1024, 36))},
+	}
+
+	b, err := json.Marshal(tt)
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	got := testType{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+
+	if got.LastTransitionTime.SameInstant(tt.LastTransitionTime) {
+		t.Error("SameInstant() = true, wanted sub-second precision to be lost")
+	}
+	if diff := cmp.Diff(tt, got, VolatileTimeRoundTripEquality); diff != "" {
+		t.Errorf("Round trip (-want, +got) = %v", diff)
+	}
+
+	later := testType{
+		LastTransitionTime: VolatileTime{metav1.NewTime(time.Unix(1025, 0))},
+	}
+	if cmp.Equal(tt, later, VolatileTimeRoundTripEquality) {
+		t.Error("cmp.Equal() = true, wanted false")
+	}
+}
+
+func TestVolatileTimeSafeDiff(t *testing.T) {
+	tt1 := testType{
+		LastTransitionTime: VolatileTime{metav1.NewTime(time.Unix(1024, 36))},
+	}
+	tt2 := testType{
+		LastTransitionTime: VolatileTime{metav1.NewTime(time.Unix(2048, 36))},
+	}
+
+	if diff, err := kmp.SafeDiff(tt1, tt2); err != nil {
+		t.Errorf("SafeDiff() = %v", err)
+	} else if diff != "" {
+		t.Errorf("SafeDiff() = %s, wanted no diff", diff)
+	}
+}
@@ -35,7 +35,8 @@ func init() {
 type Key struct{}
 
 func withClient(ctx context.Context, cfg *rest.Config) context.Context {
-	return context.WithValue(ctx, Key{}, clientset.NewForConfigOrDie(cfg))
+	return context.WithValue(ctx, Key{}, clientset.NewForConfigOrDie(
+		injection.ClientConfig(ctx, Key{}, cfg)))
 }
 
 // Get extracts the clientset.Interface client from the context.
@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1beta1.CustomResourceDefinitionInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/apiextensions-apiserver/pkg/client/informers/externalversions/apiextensions/v1beta1.CustomResourceDefinitionInformer from context.")
 	}
-	return untyped.(v1beta1.CustomResourceDefinitionInformer)
+	inf := untyped.(v1beta1.CustomResourceDefinitionInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1alpha1.PolicyInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch knative.dev/pkg/client/informers/externalversions/authentication/v1alpha1.PolicyInformer from context.")
 	}
-	return untyped.(v1alpha1.PolicyInformer)
+	inf := untyped.(v1alpha1.PolicyInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
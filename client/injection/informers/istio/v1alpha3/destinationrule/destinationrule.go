@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1alpha3.DestinationRuleInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch knative.dev/pkg/client/informers/externalversions/istio/v1alpha3.DestinationRuleInformer from context.")
 	}
-	return untyped.(v1alpha3.DestinationRuleInformer)
+	inf := untyped.(v1alpha3.DestinationRuleInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
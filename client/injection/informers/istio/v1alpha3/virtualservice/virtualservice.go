@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1alpha3.VirtualServiceInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch knative.dev/pkg/client/informers/externalversions/istio/v1alpha3.VirtualServiceInformer from context.")
 	}
-	return untyped.(v1alpha3.VirtualServiceInformer)
+	inf := untyped.(v1alpha3.VirtualServiceInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
@@ -35,7 +35,8 @@ func init() {
 type Key struct{}
 
 func withClient(ctx context.Context, cfg *rest.Config) context.Context {
-	return context.WithValue(ctx, Key{}, kubernetes.NewForConfigOrDie(cfg))
+	return context.WithValue(ctx, Key{}, kubernetes.NewForConfigOrDie(
+		injection.ClientConfig(ctx, Key{}, cfg)))
 }
 
 // Get extracts the kubernetes.Interface client from the context.
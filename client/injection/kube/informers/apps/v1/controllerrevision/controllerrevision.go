@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.ControllerRevisionInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/apps/v1.ControllerRevisionInformer from context.")
 	}
-	return untyped.(v1.ControllerRevisionInformer)
+	inf := untyped.(v1.ControllerRevisionInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.DaemonSetInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/apps/v1.DaemonSetInformer from context.")
 	}
-	return untyped.(v1.DaemonSetInformer)
+	inf := untyped.(v1.DaemonSetInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
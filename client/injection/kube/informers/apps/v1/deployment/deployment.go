@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.DeploymentInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/apps/v1.DeploymentInformer from context.")
 	}
-	return untyped.(v1.DeploymentInformer)
+	inf := untyped.(v1.DeploymentInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.ReplicaSetInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/apps/v1.ReplicaSetInformer from context.")
 	}
-	return untyped.(v1.ReplicaSetInformer)
+	inf := untyped.(v1.ReplicaSetInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
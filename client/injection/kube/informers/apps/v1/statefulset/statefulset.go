@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.StatefulSetInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/apps/v1.StatefulSetInformer from context.")
 	}
-	return untyped.(v1.StatefulSetInformer)
+	inf := untyped.(v1.StatefulSetInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
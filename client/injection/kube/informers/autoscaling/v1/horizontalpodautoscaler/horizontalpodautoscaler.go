@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.HorizontalPodAutoscalerInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/autoscaling/v1.HorizontalPodAutoscalerInformer from context.")
 	}
-	return untyped.(v1.HorizontalPodAutoscalerInformer)
+	inf := untyped.(v1.HorizontalPodAutoscalerInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
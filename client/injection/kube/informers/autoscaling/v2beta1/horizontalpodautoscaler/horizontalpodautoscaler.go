@@ -48,5 +48,7 @@ func Get(ctx context.Context) v2beta1.HorizontalPodAutoscalerInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/autoscaling/v2beta1.HorizontalPodAutoscalerInformer from context.")
 	}
-	return untyped.(v2beta1.HorizontalPodAutoscalerInformer)
+	inf := untyped.(v2beta1.HorizontalPodAutoscalerInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
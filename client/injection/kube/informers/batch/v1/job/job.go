@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.JobInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/batch/v1.JobInformer from context.")
 	}
-	return untyped.(v1.JobInformer)
+	inf := untyped.(v1.JobInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1beta1.CronJobInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/batch/v1beta1.CronJobInformer from context.")
 	}
-	return untyped.(v1beta1.CronJobInformer)
+	inf := untyped.(v1beta1.CronJobInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.ComponentStatusInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/core/v1.ComponentStatusInformer from context.")
 	}
-	return untyped.(v1.ComponentStatusInformer)
+	inf := untyped.(v1.ComponentStatusInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
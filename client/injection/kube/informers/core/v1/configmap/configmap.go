@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.ConfigMapInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/core/v1.ConfigMapInformer from context.")
 	}
-	return untyped.(v1.ConfigMapInformer)
+	inf := untyped.(v1.ConfigMapInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.EndpointsInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/core/v1.EndpointsInformer from context.")
 	}
-	return untyped.(v1.EndpointsInformer)
+	inf := untyped.(v1.EndpointsInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.EventInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/core/v1.EventInformer from context.")
 	}
-	return untyped.(v1.EventInformer)
+	inf := untyped.(v1.EventInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.LimitRangeInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/core/v1.LimitRangeInformer from context.")
 	}
-	return untyped.(v1.LimitRangeInformer)
+	inf := untyped.(v1.LimitRangeInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.NamespaceInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/core/v1.NamespaceInformer from context.")
 	}
-	return untyped.(v1.NamespaceInformer)
+	inf := untyped.(v1.NamespaceInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.NodeInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/core/v1.NodeInformer from context.")
 	}
-	return untyped.(v1.NodeInformer)
+	inf := untyped.(v1.NodeInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.PersistentVolumeInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/core/v1.PersistentVolumeInformer from context.")
 	}
-	return untyped.(v1.PersistentVolumeInformer)
+	inf := untyped.(v1.PersistentVolumeInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
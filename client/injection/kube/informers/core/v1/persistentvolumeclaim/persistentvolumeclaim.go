@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.PersistentVolumeClaimInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/core/v1.PersistentVolumeClaimInformer from context.")
 	}
-	return untyped.(v1.PersistentVolumeClaimInformer)
+	inf := untyped.(v1.PersistentVolumeClaimInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
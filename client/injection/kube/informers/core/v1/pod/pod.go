@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.PodInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/core/v1.PodInformer from context.")
 	}
-	return untyped.(v1.PodInformer)
+	inf := untyped.(v1.PodInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.PodTemplateInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/core/v1.PodTemplateInformer from context.")
 	}
-	return untyped.(v1.PodTemplateInformer)
+	inf := untyped.(v1.PodTemplateInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.ReplicationControllerInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/core/v1.ReplicationControllerInformer from context.")
 	}
-	return untyped.(v1.ReplicationControllerInformer)
+	inf := untyped.(v1.ReplicationControllerInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
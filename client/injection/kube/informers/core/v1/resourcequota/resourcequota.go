@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.ResourceQuotaInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/core/v1.ResourceQuotaInformer from context.")
 	}
-	return untyped.(v1.ResourceQuotaInformer)
+	inf := untyped.(v1.ResourceQuotaInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
@@ -0,0 +1,168 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package filtered injects a Secret informer limited to the secrets
+// matching a label selector, whose cache drops the data of the secrets
+// but for an allowlist of keys, so that controllers watching a few
+// secrets (e.g. certificates) don't hold every secret of the cluster in
+// memory.
+package filtered
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	v1 "k8s.io/client-go/informers/core/v1"
+	kubernetes "k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	client "knative.dev/pkg/client/injection/kube/client"
+	controller "knative.dev/pkg/controller"
+	injection "knative.dev/pkg/injection"
+	logging "knative.dev/pkg/logging"
+)
+
+func init() {
+	injection.Default.RegisterInformer(withInformer)
+}
+
+// Key is used for associating the Informer inside the context.Context.
+type Key struct{}
+
+// selectorKey and dataKeysKey are used as the keys for associating the
+// label selector and the allowlisted data keys of the informer with a
+// context.Context.
+type selectorKey struct{}
+type dataKeysKey struct{}
+
+// WithSelector limits the informer injected with the context to the
+// secrets matching the label selector.
+func WithSelector(ctx context.Context, selector string) context.Context {
+	return context.WithValue(ctx, selectorKey{}, selector)
+}
+
+// WithDataKeys keeps the data of the keys in the secrets cached by the
+// informer injected with the context.  By default their data is dropped.
+func WithDataKeys(ctx context.Context, keys ...string) context.Context {
+	return context.WithValue(ctx, dataKeysKey{}, keys)
+}
+
+func withInformer(ctx context.Context) (context.Context, controller.Informer) {
+	return WithInformer(ctx, client.Get(ctx))
+}
+
+// WithInformer attaches an informer of the secrets of the client to the
+// context, configured with WithSelector and WithDataKeys.  It is used by
+// the fake package, with the fake client.
+func WithInformer(ctx context.Context, c kubernetes.Interface) (context.Context, controller.Informer) {
+	namespace := metav1.NamespaceAll
+	if injection.HasNamespaceScope(ctx) {
+		namespace = injection.GetNamespaceScope(ctx)
+	}
+	selector, _ := ctx.Value(selectorKey{}).(string)
+	keys, _ := ctx.Value(dataKeysKey{}).([]string)
+
+	inf := NewInformer(c, namespace, controller.GetResyncPeriod(ctx), selector, keys...)
+	return context.WithValue(ctx, Key{}, inf), inf.Informer()
+}
+
+// Get extracts the typed informer from the context.
+func Get(ctx context.Context) v1.SecretInformer {
+	untyped := ctx.Value(Key{})
+	if untyped == nil {
+		logging.FromContext(ctx).Panic(
+			"Unable to fetch filtered k8s.io/client-go/informers/core/v1.SecretInformer from context.")
+	}
+	inf := untyped.(v1.SecretInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
+}
+
+// NewInformer returns an informer of the secrets of the namespace matching
+// the label selector, whose cache only holds the data of the keys.
+func NewInformer(c kubernetes.Interface, namespace string, resync time.Duration, selector string, keys ...string) v1.SecretInformer {
+	secrets := c.CoreV1().Secrets(namespace)
+	lw := &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			opts.LabelSelector = selector
+			l, err := secrets.List(opts)
+			if err != nil {
+				return nil, err
+			}
+			for i := range l.Items {
+				dropData(&l.Items[i], keys)
+			}
+			return l, nil
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			opts.LabelSelector = selector
+			w, err := secrets.Watch(opts)
+			if err != nil {
+				return nil, err
+			}
+			return watch.Filter(w, func(e watch.Event) (watch.Event, bool) {
+				if s, ok := e.Object.(*corev1.Secret); ok {
+					s = s.DeepCopy()
+					dropData(s, keys)
+					e.Object = s
+				}
+				return e, true
+			}), nil
+		},
+	}
+	return &informer{
+		inf: cache.NewSharedIndexInformer(lw, &corev1.Secret{}, resync, cache.Indexers{
+			cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+		}),
+	}
+}
+
+// dropData drops the data of the secret but for the keys.
+func dropData(s *corev1.Secret, keys []string) {
+	var data map[string][]byte
+	for _, k := range keys {
+		if v, ok := s.Data[k]; ok {
+			if data == nil {
+				data = make(map[string][]byte, len(keys))
+			}
+			data[k] = v
+		}
+	}
+	s.Data = data
+	s.StringData = nil
+}
+
+// informer implements v1.SecretInformer over a SharedIndexInformer.
+type informer struct {
+	inf cache.SharedIndexInformer
+}
+
+var _ v1.SecretInformer = (*informer)(nil)
+
+// Informer implements v1.SecretInformer
+func (i *informer) Informer() cache.SharedIndexInformer {
+	return i.inf
+}
+
+// Lister implements v1.SecretInformer
+func (i *informer) Lister() corev1listers.SecretLister {
+	return corev1listers.NewSecretLister(i.inf.GetIndexer())
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filtered
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func secret(name string, labels map[string]string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      name,
+			Labels:    labels,
+		},
+		Data: map[string][]byte{
+			"tls.crt": []byte("cert"),
+			"tls.key": []byte("key"),
+		},
+	}
+}
+
+func TestNewInformer(t *testing.T) {
+	certLabels := map[string]string{"cert": "true"}
+	c := fake.NewSimpleClientset(secret("listed", certLabels), secret("other", nil))
+	inf := NewInformer(c, metav1.NamespaceAll, time.Hour, "cert=true", "tls.crt")
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go inf.Informer().Run(stopCh)
+	if !cache.WaitForCacheSync(stopCh, inf.Informer().HasSynced) {
+		t.Fatal("Informer did not sync")
+	}
+
+	// Secrets added later go through the watch.
+	if _, err := c.CoreV1().Secrets("ns").Create(secret("watched", certLabels)); err != nil {
+		t.Fatalf("Create() = %v", err)
+	}
+	var secrets []*corev1.Secret
+	for i := 0; i < 100; i++ {
+		secrets, _ = inf.Lister().List(labels.Everything())
+		if len(secrets) == 2 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got, want := len(secrets), 2; got != want {
+		t.Fatalf("|List()| = %d, wanted %d", got, want)
+	}
+
+	want := map[string][]byte{"tls.crt": []byte("cert")}
+	for _, s := range secrets {
+		if diff := cmp.Diff(want, s.Data); diff != "" {
+			t.Errorf("Data of %s (-want, +got) = %s", s.Name, diff)
+		}
+	}
+}
+
+func TestDropData(t *testing.T) {
+	s := secret("foo", nil)
+	dropData(s, nil)
+	if s.Data != nil {
+		t.Errorf("Data = %v, wanted none", s.Data)
+	}
+}
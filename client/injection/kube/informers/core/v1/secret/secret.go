@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.SecretInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/core/v1.SecretInformer from context.")
 	}
-	return untyped.(v1.SecretInformer)
+	inf := untyped.(v1.SecretInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.ServiceInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/core/v1.ServiceInformer from context.")
 	}
-	return untyped.(v1.ServiceInformer)
+	inf := untyped.(v1.ServiceInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.ServiceAccountInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/core/v1.ServiceAccountInformer from context.")
 	}
-	return untyped.(v1.ServiceAccountInformer)
+	inf := untyped.(v1.ServiceAccountInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
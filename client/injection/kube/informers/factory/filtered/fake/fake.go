@@ -0,0 +1,35 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+
+	fake "knative.dev/pkg/client/injection/kube/client/fake"
+	filtered "knative.dev/pkg/client/injection/kube/informers/factory/filtered"
+	injection "knative.dev/pkg/injection"
+)
+
+var Get = filtered.Get
+
+func init() {
+	injection.Fake.RegisterInformerFactory(withInformerFactories)
+}
+
+func withInformerFactories(ctx context.Context) context.Context {
+	return filtered.WithInformerFactories(ctx, fake.Get(ctx))
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package filtered injects SharedInformerFactories whose informers only
+// cache the objects matching label and field selectors, e.g. the pods of
+// a node (spec.nodeName=X) or the pods still running
+// (status.phase!=Succeeded), so that controllers watching busy resources
+// can keep their caches small.
+package filtered
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	informers "k8s.io/client-go/informers"
+	kubernetes "k8s.io/client-go/kubernetes"
+	client "knative.dev/pkg/client/injection/kube/client"
+	controller "knative.dev/pkg/controller"
+	injection "knative.dev/pkg/injection"
+	logging "knative.dev/pkg/logging"
+)
+
+func init() {
+	injection.Default.RegisterInformerFactory(withInformerFactories)
+}
+
+// Selector selects the objects cached by the informers of a factory.
+// Either selector may be empty, and both are ANDed when set.
+type Selector struct {
+	// Label is a label selector, e.g. "app=foo".
+	Label string
+
+	// Field is a field selector, e.g. "spec.nodeName=X".
+	Field string
+}
+
+// Key is used as the key for associating the factory of a Selector with a
+// context.Context.
+type Key struct {
+	Selector Selector
+}
+
+// selectorsKey is used as the key for associating the selectors to create
+// factories for with a context.Context.
+type selectorsKey struct{}
+
+// WithSelectors asks for a factory to be injected for each of the
+// selectors.  It must be called on the context passed to
+// injection.Default.SetupInformers.  The informers fetched from the
+// factories before injection.StartInformers are started by it.
+func WithSelectors(ctx context.Context, selectors ...Selector) context.Context {
+	return context.WithValue(ctx, selectorsKey{}, selectors)
+}
+
+func withInformerFactories(ctx context.Context) context.Context {
+	return WithInformerFactories(ctx, client.Get(ctx))
+}
+
+// WithInformerFactories attaches a factory of informers of the client to
+// the context for each of the selectors of WithSelectors.  It is used by
+// the fake package, with the fake client.
+func WithInformerFactories(ctx context.Context, c kubernetes.Interface) context.Context {
+	selectors, _ := ctx.Value(selectorsKey{}).([]Selector)
+	for _, selector := range selectors {
+		selector := selector
+		opts := make([]informers.SharedInformerOption, 0, 2)
+		if injection.HasNamespaceScope(ctx) {
+			opts = append(opts, informers.WithNamespace(injection.GetNamespaceScope(ctx)))
+		}
+		opts = append(opts, informers.WithTweakListOptions(func(l *metav1.ListOptions) {
+			l.LabelSelector = selector.Label
+			l.FieldSelector = selector.Field
+		}))
+		f := informers.NewSharedInformerFactoryWithOptions(c, controller.GetResyncPeriod(ctx), opts...)
+		ctx = injection.WithStartedFactory(ctx, f)
+		ctx = context.WithValue(ctx, Key{Selector: selector}, f)
+	}
+	return ctx
+}
+
+// Get extracts the factory of the selector from the context.
+func Get(ctx context.Context, selector Selector) informers.SharedInformerFactory {
+	untyped := ctx.Value(Key{Selector: selector})
+	if untyped == nil {
+		logging.FromContext(ctx).Panicf(
+			"Unable to fetch k8s.io/client-go/informers.SharedInformerFactory with selector %+v from context.", selector)
+	}
+	return untyped.(informers.SharedInformerFactory)
+}
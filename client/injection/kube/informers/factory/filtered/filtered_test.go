@@ -0,0 +1,84 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filtered
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"knative.dev/pkg/injection"
+)
+
+func TestWithInformerFactories(t *testing.T) {
+	byNode := Selector{Field: "spec.nodeName=foo"}
+	byLabel := Selector{Label: "app=bar"}
+	ctx := WithSelectors(context.Background(), byNode, byLabel)
+	ctx = WithInformerFactories(ctx, fake.NewSimpleClientset())
+
+	if Get(ctx, byNode) == Get(ctx, byLabel) {
+		t.Error("Get() returned the same factory for different selectors")
+	}
+}
+
+func TestGetMissingSelector(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Get() did not panic for a selector without factory")
+		}
+	}()
+	ctx := WithInformerFactories(context.Background(), fake.NewSimpleClientset())
+	Get(ctx, Selector{Label: "app=bar"})
+}
+
+func TestGetInformerSyncs(t *testing.T) {
+	byLabel := Selector{Label: "app=bar"}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "bar",
+			Labels:    map[string]string{"app": "bar"},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(WithSelectors(context.Background(), byLabel))
+	defer cancel()
+	ctx = WithInformerFactories(ctx, fake.NewSimpleClientset(pod))
+
+	// Like the constructors of controllers, fetch the informer before
+	// starting the informers.
+	pods := Get(ctx, byLabel).Core().V1().Pods()
+	informer := pods.Informer()
+	if err := injection.StartInformers(ctx); err != nil {
+		t.Fatalf("StartInformers() = %v", err)
+	}
+
+	if !informer.HasSynced() {
+		t.Error("HasSynced() = false, wanted true")
+	}
+	got, err := pods.Lister().List(labels.Everything())
+	if err != nil {
+		t.Fatalf("List() = %v", err)
+	}
+	if len(got) != 1 || got[0].Name != pod.Name {
+		t.Errorf("List() = %v, wanted [%s]", got, pod.Name)
+	}
+}
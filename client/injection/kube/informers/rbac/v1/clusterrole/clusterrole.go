@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.ClusterRoleInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/rbac/v1.ClusterRoleInformer from context.")
 	}
-	return untyped.(v1.ClusterRoleInformer)
+	inf := untyped.(v1.ClusterRoleInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.ClusterRoleBindingInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/rbac/v1.ClusterRoleBindingInformer from context.")
 	}
-	return untyped.(v1.ClusterRoleBindingInformer)
+	inf := untyped.(v1.ClusterRoleBindingInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
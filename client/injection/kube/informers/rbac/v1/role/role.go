@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.RoleInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/rbac/v1.RoleInformer from context.")
 	}
-	return untyped.(v1.RoleInformer)
+	inf := untyped.(v1.RoleInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
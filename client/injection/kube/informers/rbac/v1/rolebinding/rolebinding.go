@@ -48,5 +48,7 @@ func Get(ctx context.Context) v1.RoleBindingInformer {
 		logging.FromContext(ctx).Panic(
 			"Unable to fetch k8s.io/client-go/informers/rbac/v1.RoleBindingInformer from context.")
 	}
-	return untyped.(v1.RoleBindingInformer)
+	inf := untyped.(v1.RoleBindingInformer)
+	injection.Demand(ctx, inf.Informer())
+	return inf
 }
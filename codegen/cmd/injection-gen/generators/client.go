@@ -66,6 +66,7 @@ func (g *clientGenerator) GenerateType(c *generator.Context, t *types.Type, w io
 		"clientSetNewForConfigOrDie": c.Universe.Function(types.Name{Package: g.clientSetPackage, Name: "NewForConfigOrDie"}),
 		"clientSetInterface":         c.Universe.Type(types.Name{Package: g.clientSetPackage, Name: "Interface"}),
 		"injectionRegisterClient":    c.Universe.Function(types.Name{Package: "knative.dev/pkg/injection", Name: "Default.RegisterClient"}),
+		"injectionClientConfig":      c.Universe.Function(types.Name{Package: "knative.dev/pkg/injection", Name: "ClientConfig"}),
 		"restConfig":                 c.Universe.Type(types.Name{Package: "k8s.io/client-go/rest", Name: "Config"}),
 		"loggingFromContext": c.Universe.Function(types.Name{
 			Package: "knative.dev/pkg/logging",
@@ -87,7 +88,8 @@ func init() {
 type Key struct{}
 
 func withClient(ctx context.Context, cfg *{{.restConfig|raw}}) context.Context {
-	return context.WithValue(ctx, Key{}, {{.clientSetNewForConfigOrDie|raw}}(cfg))
+	return context.WithValue(ctx, Key{}, {{.clientSetNewForConfigOrDie|raw}}(
+		{{.injectionClientConfig|raw}}(ctx, Key{}, cfg)))
 }
 
 // Get extracts the {{.clientSetInterface|raw}} client from the context.
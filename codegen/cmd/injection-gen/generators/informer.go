@@ -83,6 +83,7 @@ func (g *injectionGenerator) GenerateType(c *generator.Context, t *types.Type, w
 		"type":                      t,
 		"version":                   namer.IC(g.groupVersion.Version.String()),
 		"injectionRegisterInformer": c.Universe.Type(types.Name{Package: "knative.dev/pkg/injection", Name: "Default.RegisterInformer"}),
+		"injectionDemand":           c.Universe.Function(types.Name{Package: "knative.dev/pkg/injection", Name: "Demand"}),
 		"controllerInformer":        c.Universe.Type(types.Name{Package: "knative.dev/pkg/controller", Name: "Informer"}),
 		"informersTypedInformer":    c.Universe.Type(types.Name{Package: g.typedInformerPackage, Name: t.Name.Name + "Informer"}),
 		"factoryGet":                c.Universe.Type(types.Name{Package: g.groupInformerFactoryPackage, Name: "Get"}),
@@ -118,6 +119,8 @@ func Get(ctx context.Context) {{.informersTypedInformer|raw}} {
 		{{.loggingFromContext|raw}}(ctx).Panic(
 			"Unable to fetch {{.informersTypedInformer}} from context.")
 	}
-	return untyped.({{.informersTypedInformer|raw}})
+	inf := untyped.({{.informersTypedInformer|raw}})
+	{{.injectionDemand|raw}}(ctx, inf.Informer())
+	return inf
 }
 `
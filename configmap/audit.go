@@ -0,0 +1,167 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// ReasonConfigChanged is the reason of the events emitted when an
+	// audited ConfigMap is updated.
+	ReasonConfigChanged = "ConfigChanged"
+
+	// ReasonConfigDeleted is the reason of the events emitted when an
+	// audited ConfigMap is deleted.
+	ReasonConfigDeleted = "ConfigDeleted"
+
+	// redacted replaces the values of the sensitive keys in the audit trail.
+	redacted = "<redacted>"
+)
+
+// auditor logs and reports the changes of the observed ConfigMaps.
+type auditor struct {
+	logger    *zap.SugaredLogger
+	recorder  record.EventRecorder
+	sensitive sets.String
+}
+
+// Audit makes the watcher keep an audit trail of the changes of the
+// observed ConfigMaps once it has started: each update or deletion is
+// logged with the keys that were added, removed or changed, and, when
+// the recorder is not nil, reported as an event on the ConfigMap.  The
+// values of the sensitive keys are redacted from the logs, and events
+// only ever carry key names.  The observed ConfigMaps that drift from
+// their documented example (see ExampleDrift) are also logged whenever
+// they are added or updated.  Audit must be called before Start.
+func (i *InformedWatcher) Audit(logger *zap.SugaredLogger, recorder record.EventRecorder, sensitiveKeys ...string) {
+	i.auditor = &auditor{
+		logger:    logger,
+		recorder:  recorder,
+		sensitive: sets.NewString(sensitiveKeys...),
+	}
+}
+
+// observed returns whether the ConfigMap has observers.
+func (i *InformedWatcher) observed(cm *corev1.ConfigMap) bool {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	_, ok := i.observers[cm.Name]
+	return ok
+}
+
+// configDiff is the difference between two versions of a ConfigMap.
+type configDiff struct {
+	added   map[string]string
+	removed map[string]string
+	changed map[string][2]string
+}
+
+func (d configDiff) empty() bool {
+	return len(d.added) == 0 && len(d.removed) == 0 && len(d.changed) == 0
+}
+
+// keys returns the sorted names of the keys that differ.
+func (d configDiff) keys() []string {
+	keys := make([]string, 0, len(d.added)+len(d.removed)+len(d.changed))
+	for k := range d.added {
+		keys = append(keys, k)
+	}
+	for k := range d.removed {
+		keys = append(keys, k)
+	}
+	for k := range d.changed {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// diff returns the difference between the data of the ConfigMaps,
+// redacting the values of the sensitive keys.
+func (a *auditor) diff(o, n *corev1.ConfigMap) configDiff {
+	d := configDiff{
+		added:   map[string]string{},
+		removed: map[string]string{},
+		changed: map[string][2]string{},
+	}
+	for k, nv := range n.Data {
+		ov, ok := o.Data[k]
+		switch {
+		case !ok:
+			d.added[k] = a.redact(k, nv)
+		case ov != nv:
+			d.changed[k] = [2]string{a.redact(k, ov), a.redact(k, nv)}
+		}
+	}
+	for k, ov := range o.Data {
+		if _, ok := n.Data[k]; !ok {
+			d.removed[k] = a.redact(k, ov)
+		}
+	}
+	return d
+}
+
+func (a *auditor) redact(key, value string) string {
+	if a.sensitive.Has(key) {
+		return redacted
+	}
+	return value
+}
+
+func (a *auditor) updated(o, n *corev1.ConfigMap) {
+	d := a.diff(o, n)
+	if d.empty() {
+		return
+	}
+	a.logger.Infow("ConfigMap changed",
+		zap.String("configmap", n.Namespace+"/"+n.Name),
+		zap.String("oldResourceVersion", o.ResourceVersion),
+		zap.String("newResourceVersion", n.ResourceVersion),
+		zap.Any("added", d.added),
+		zap.Any("removed", d.removed),
+		zap.Any("changed", d.changed))
+	if a.recorder != nil {
+		a.recorder.Eventf(n, corev1.EventTypeNormal, ReasonConfigChanged,
+			"Keys changed: %s", strings.Join(d.keys(), ", "))
+	}
+}
+
+// drifted logs the ways in which the ConfigMap diverges from its
+// documented example.
+func (a *auditor) drifted(cm *corev1.ConfigMap) {
+	for _, d := range ExampleDrift(cm) {
+		a.logger.Warnw("ConfigMap drifted from its example",
+			zap.String("configmap", cm.Namespace+"/"+cm.Name),
+			zap.String("drift", d))
+	}
+}
+
+func (a *auditor) deleted(o *corev1.ConfigMap) {
+	a.logger.Infow("ConfigMap deleted",
+		zap.String("configmap", o.Namespace+"/"+o.Name),
+		zap.String("oldResourceVersion", o.ResourceVersion))
+	if a.recorder != nil {
+		a.recorder.Event(o, corev1.EventTypeWarning, ReasonConfigDeleted, "ConfigMap was deleted")
+	}
+}
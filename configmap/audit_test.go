@@ -0,0 +1,181 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestAuditDiff(t *testing.T) {
+	a := &auditor{sensitive: sets.NewString("password")}
+
+	o := &corev1.ConfigMap{Data: map[string]string{
+		"same":     "1",
+		"changed":  "1",
+		"removed":  "1",
+		"password": "hunter2",
+	}}
+	n := &corev1.ConfigMap{Data: map[string]string{
+		"same":     "1",
+		"changed":  "2",
+		"added":    "1",
+		"password": "hunter3",
+	}}
+
+	d := a.diff(o, n)
+	if diff := cmp.Diff(map[string]string{"added": "1"}, d.added); diff != "" {
+		t.Errorf("added (-want, +got) = %s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{"removed": "1"}, d.removed); diff != "" {
+		t.Errorf("removed (-want, +got) = %s", diff)
+	}
+	wantChanged := map[string][2]string{
+		"changed":  {"1", "2"},
+		"password": {redacted, redacted},
+	}
+	if diff := cmp.Diff(wantChanged, d.changed); diff != "" {
+		t.Errorf("changed (-want, +got) = %s", diff)
+	}
+	if diff := cmp.Diff([]string{"added", "changed", "password", "removed"}, d.keys()); diff != "" {
+		t.Errorf("keys (-want, +got) = %s", diff)
+	}
+	if !a.diff(o, o).empty() {
+		t.Error("diff(o, o) is not empty")
+	}
+}
+
+func TestInformedWatcherAudit(t *testing.T) {
+	fooCM := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "foo",
+		},
+		Data: map[string]string{
+			"level":  "info",
+			"secret": "s3cr3t",
+		},
+	}
+	barCM := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "bar",
+		},
+	}
+
+	kc := fakekubeclientset.NewSimpleClientset(fooCM, barCM)
+	cm := NewInformedWatcher(kc, "default")
+
+	var logs syncBuffer
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), &logs, zap.InfoLevel)
+	recorder := record.NewFakeRecorder(10)
+	cm.Audit(zap.New(core).Sugar(), recorder, "secret")
+
+	foo1 := &counter{name: "foo1", wg: &sync.WaitGroup{}}
+	foo1.wg.Add(1)
+	cm.Watch("foo", foo1.callback)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	if err := cm.Start(stopCh); err != nil {
+		t.Fatalf("cm.Start() = %v", err)
+	}
+	foo1.wg.Wait()
+
+	// Changes of unobserved ConfigMaps are not audited.
+	barCM = barCM.DeepCopy()
+	barCM.Data = map[string]string{"foo": "bar"}
+	if _, err := kc.CoreV1().ConfigMaps("default").Update(barCM); err != nil {
+		t.Fatalf("Update() = %v", err)
+	}
+
+	fooCM = fooCM.DeepCopy()
+	fooCM.Data = map[string]string{
+		"level":  "debug",
+		"secret": "t0p-s3cr3t",
+	}
+	foo1.wg.Add(1)
+	if _, err := kc.CoreV1().ConfigMaps("default").Update(fooCM); err != nil {
+		t.Fatalf("Update() = %v", err)
+	}
+	foo1.wg.Wait()
+
+	if got, want := <-recorder.Events, "Normal ConfigChanged Keys changed: level, secret"; got != want {
+		t.Errorf("Event = %q, wanted %q", got, want)
+	}
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Got %d audit log lines, wanted 1: %v", len(lines), lines)
+	}
+	var entry struct {
+		Msg       string
+		ConfigMap string
+		Changed   map[string][2]string
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("json.Unmarshal() = %v", err)
+	}
+	want := struct {
+		Msg       string
+		ConfigMap string
+		Changed   map[string][2]string
+	}{
+		Msg:       "ConfigMap changed",
+		ConfigMap: "default/foo",
+		Changed: map[string][2]string{
+			"level":  {"info", "debug"},
+			"secret": {redacted, redacted},
+		},
+	}
+	if diff := cmp.Diff(want, entry); diff != "" {
+		t.Errorf("Audit log (-want, +got) = %s", diff)
+	}
+}
+
+// syncBuffer is a bytes.Buffer that is safe for concurrent use.
+type syncBuffer struct {
+	m sync.Mutex
+	b bytes.Buffer
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.b.Write(p)
+}
+
+func (s *syncBuffer) Sync() error {
+	return nil
+}
+
+func (s *syncBuffer) String() string {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.b.String()
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// BatchObserver is the signature of the callbacks that notify an observer
+// of the latest state of a set of ConfigMaps, keyed by name.  Like an
+// Observer, it should not modify the provided ConfigMaps.
+type BatchObserver func(map[string]*corev1.ConfigMap)
+
+// WatchBatch registers the observer with the watcher for the named
+// ConfigMaps.  The observer is first invoked once every one of them has
+// been observed, which with an InformedWatcher happens when Start
+// returns.  It is then invoked with all of them whenever any changes,
+// once no change has been observed for the window, so that ConfigMaps
+// updated together are never observed in a torn intermediate state.
+func WatchBatch(w Watcher, window time.Duration, o BatchObserver, names ...string) {
+	b := &batch{
+		window:   window,
+		observer: o,
+		names:    names,
+		latest:   make(map[string]*corev1.ConfigMap, len(names)),
+	}
+	for _, name := range names {
+		w.Watch(name, b.onChange)
+	}
+}
+
+type batch struct {
+	window   time.Duration
+	observer BatchObserver
+	names    []string
+
+	// m guards latest, notified and timer.
+	m        sync.Mutex
+	latest   map[string]*corev1.ConfigMap
+	notified bool
+	timer    *time.Timer
+
+	// notifyMu serializes the notifications of the observer.
+	notifyMu sync.Mutex
+}
+
+func (b *batch) onChange(cm *corev1.ConfigMap) {
+	b.m.Lock()
+	b.latest[cm.Name] = cm
+	if len(b.latest) < len(b.names) {
+		b.m.Unlock()
+		return
+	}
+	if !b.notified || b.window <= 0 {
+		b.notified = true
+		b.m.Unlock()
+		b.notify()
+		return
+	}
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	b.timer = time.AfterFunc(b.window, b.notify)
+	b.m.Unlock()
+}
+
+// notify invokes the observer with a snapshot of the latest ConfigMaps.
+func (b *batch) notify() {
+	b.notifyMu.Lock()
+	defer b.notifyMu.Unlock()
+
+	b.m.Lock()
+	snapshot := make(map[string]*corev1.ConfigMap, len(b.latest))
+	for name, cm := range b.latest {
+		snapshot[name] = cm
+	}
+	b.m.Unlock()
+
+	b.observer(snapshot)
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type batchCounter struct {
+	m         sync.Mutex
+	snapshots []map[string]*corev1.ConfigMap
+}
+
+func (bc *batchCounter) observe(cms map[string]*corev1.ConfigMap) {
+	bc.m.Lock()
+	defer bc.m.Unlock()
+	bc.snapshots = append(bc.snapshots, cms)
+}
+
+func (bc *batchCounter) values() []string {
+	bc.m.Lock()
+	defer bc.m.Unlock()
+	values := make([]string, 0, len(bc.snapshots))
+	for _, s := range bc.snapshots {
+		values = append(values, s["foo"].Data["v"]+s["bar"].Data["v"])
+	}
+	return values
+}
+
+func newConfigMap(name, v string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Data:       map[string]string{"v": v},
+	}
+}
+
+func TestWatchBatch(t *testing.T) {
+	w := &ManualWatcher{Namespace: "default"}
+	bc := &batchCounter{}
+	WatchBatch(w, 50*time.Millisecond, bc.observe, "foo", "bar")
+
+	// Nothing is observed until every ConfigMap was.
+	w.OnChange(newConfigMap("foo", "1"))
+	if got := bc.values(); len(got) != 0 {
+		t.Errorf("Observed %v before every ConfigMap was", got)
+	}
+
+	// The first complete snapshot is observed right away.
+	w.OnChange(newConfigMap("bar", "1"))
+	if got, want := bc.values(), []string{"11"}; !equal(got, want) {
+		t.Errorf("Observed %v, wanted %v", got, want)
+	}
+
+	// Changes in quick succession are observed together.
+	w.OnChange(newConfigMap("foo", "2"))
+	w.OnChange(newConfigMap("bar", "2"))
+	time.Sleep(20 * time.Millisecond)
+	if got, want := bc.values(), []string{"11"}; !equal(got, want) {
+		t.Errorf("Observed %v within the window, wanted %v", got, want)
+	}
+	for i := 0; i < 100 && len(bc.values()) < 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got, want := bc.values(), []string{"11", "22"}; !equal(got, want) {
+		t.Errorf("Observed %v, wanted %v", got, want)
+	}
+}
+
+func TestWatchBatchWithoutWindow(t *testing.T) {
+	w := &ManualWatcher{Namespace: "default"}
+	bc := &batchCounter{}
+	WatchBatch(w, 0, bc.observe, "foo", "bar")
+
+	w.OnChange(newConfigMap("foo", "1"))
+	w.OnChange(newConfigMap("bar", "1"))
+	w.OnChange(newConfigMap("foo", "2"))
+	if got, want := bc.values(), []string{"11", "21"}; !equal(got, want) {
+		t.Errorf("Observed %v, wanted %v", got, want)
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"fmt"
+	"hash/crc32"
+	"regexp"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ExampleChecksumAnnotation is the annotation holding the Checksum
+	// of the ExampleKey of a ConfigMap, as shipped.
+	ExampleChecksumAnnotation = "knative.dev/example-checksum"
+
+	// ExampleKeysAnnotation is the annotation holding the comma-separated
+	// list of the keys of a ConfigMap that its ExampleKey documents.
+	ExampleKeysAnnotation = "knative.dev/example-keys"
+)
+
+// Checksum returns the checksum of the given value, as stored in the
+// ExampleChecksumAnnotation.
+func Checksum(value string) string {
+	return fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(value)))
+}
+
+// ExampleDrift returns the ways in which the ConfigMap diverges from
+// its documented example, according to its annotations:
+//
+//   - its ExampleKey does not match the ExampleChecksumAnnotation, which
+//     usually means that the example was edited instead of the keys;
+//   - it sets keys that are not among the ExampleKeysAnnotation;
+//   - keys among the ExampleKeysAnnotation are missing from its ExampleKey.
+//
+// ConfigMaps without the annotations never drift.
+func ExampleDrift(cm *corev1.ConfigMap) []string {
+	var drift []string
+	example, hasExample := cm.Data[ExampleKey]
+
+	if want, ok := cm.Annotations[ExampleChecksumAnnotation]; ok && hasExample {
+		if got := Checksum(example); got != want {
+			drift = append(drift, fmt.Sprintf("the %s checksum is %s, want %s", ExampleKey, got, want))
+		}
+	}
+
+	raw, ok := cm.Annotations[ExampleKeysAnnotation]
+	if !ok {
+		return drift
+	}
+	documented := map[string]bool{}
+	for _, k := range splitList(raw) {
+		documented[k] = true
+	}
+
+	var undocumented []string
+	for k := range cm.Data {
+		if k != ExampleKey && !documented[k] {
+			undocumented = append(undocumented, k)
+		}
+	}
+	sort.Strings(undocumented)
+	for _, k := range undocumented {
+		drift = append(drift, fmt.Sprintf("key %q is not documented by the %s", k, ExampleKey))
+	}
+
+	var missing []string
+	for k := range documented {
+		if !exampleHasKey(example, k) {
+			missing = append(missing, k)
+		}
+	}
+	sort.Strings(missing)
+	for _, k := range missing {
+		drift = append(drift, fmt.Sprintf("documented key %q is missing from the %s", k, ExampleKey))
+	}
+	return drift
+}
+
+// exampleHasKey returns whether the example sets the key, commented out
+// or not, at the top level.
+func exampleHasKey(example, key string) bool {
+	re := regexp.MustCompile(`(?m)^#?\s?` + regexp.QuoteMeta(key) + `:`)
+	return re.MatchString(example)
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestExampleDrift(t *testing.T) {
+	const example = `
+# Some documentation.
+# level: "info"
+
+# More documentation.
+timeout: "30s"
+#   nested: "ignored"
+`
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		data        map[string]string
+		want        []string
+	}{{
+		name: "no annotations",
+		data: map[string]string{
+			ExampleKey: "whatever",
+			"other":    "value",
+		},
+	}, {
+		name: "all good",
+		annotations: map[string]string{
+			ExampleChecksumAnnotation: Checksum(example),
+			ExampleKeysAnnotation:     "level, timeout",
+		},
+		data: map[string]string{
+			ExampleKey: example,
+			"level":    "debug",
+		},
+	}, {
+		name: "edited example",
+		annotations: map[string]string{
+			ExampleChecksumAnnotation: Checksum(example),
+		},
+		data: map[string]string{
+			ExampleKey: example + "# level: debug\n",
+		},
+		want: []string{
+			"the _example checksum is " + Checksum(example+"# level: debug\n") + ", want " + Checksum(example),
+		},
+	}, {
+		name: "structural drift",
+		annotations: map[string]string{
+			ExampleKeysAnnotation: "level,nested,retries",
+		},
+		data: map[string]string{
+			ExampleKey: example,
+			"level":    "debug",
+			"timout":   "1m",
+			"region":   "us",
+		},
+		want: []string{
+			`key "region" is not documented by the _example`,
+			`key "timout" is not documented by the _example`,
+			`documented key "nested" is missing from the _example`,
+			`documented key "retries" is missing from the _example`,
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "config-test",
+					Annotations: test.annotations,
+				},
+				Data: test.data,
+			}
+			if diff := cmp.Diff(test.want, ExampleDrift(cm)); diff != "" {
+				t.Errorf("ExampleDrift (-want, +got) = %s", diff)
+			}
+		})
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	if got, want := Checksum(""), "00000000"; got != want {
+		t.Errorf("Checksum(\"\") = %q, want %q", got, want)
+	}
+	if Checksum("a") == Checksum("b") {
+		t.Error("Checksum(a) == Checksum(b)")
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultFileWatcherInterval is how often a FileWatcher checks its
+// ConfigMaps for changes by default.
+const DefaultFileWatcherInterval = 10 * time.Second
+
+// FileWatcher provides an implementation of Watcher over ConfigMaps
+// mounted as volumes, each in the sub-directory of Dir named after it, so
+// that components can be configured without the RBAC to watch ConfigMaps.
+// Secret volumes may be watched too, their data being observed as that
+// of a ConfigMap.
+//
+// The volumes are read with Load every Interval, which copes with the
+// atomic swap of the symlink through which the kubelet updates them, and
+// the observers are notified of the ConfigMaps whose data changed.
+type FileWatcher struct {
+	// Dir is the directory under which the ConfigMaps are mounted.
+	Dir string
+
+	// Interval is how often the ConfigMaps are checked for changes.
+	Interval time.Duration
+
+	// data is the last data read for each ConfigMap.
+	data map[string]map[string]string
+
+	// Embedding this struct allows us to reuse the logic
+	// of registering and notifying observers.
+	ManualWatcher
+}
+
+// Asserts that FileWatcher implements Watcher.
+var _ Watcher = (*FileWatcher)(nil)
+
+// NewFileWatcher watches the ConfigMaps mounted under dir, which are
+// observed as being in the given namespace.
+func NewFileWatcher(dir, namespace string) *FileWatcher {
+	return &FileWatcher{
+		Dir:      dir,
+		Interval: DefaultFileWatcherInterval,
+		data:     make(map[string]map[string]string),
+		ManualWatcher: ManualWatcher{
+			Namespace: namespace,
+		},
+	}
+}
+
+// Start implements Watcher.  It fails when an observed ConfigMap is not
+// mounted.
+func (w *FileWatcher) Start(stopCh <-chan struct{}) error {
+	if w.Interval <= 0 {
+		return errors.New("the interval of the file watcher must be positive")
+	}
+	if err := w.check(); err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(w.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C:
+				// Volumes being swapped may fail to read, and will be
+				// read again on the next tick.
+				w.check()
+			}
+		}
+	}()
+	return nil
+}
+
+// check reads the observed ConfigMaps and notifies the observers of those
+// which changed since last read.
+func (w *FileWatcher) check() error {
+	w.m.RLock()
+	names := make([]string, 0, len(w.observers))
+	for name := range w.observers {
+		names = append(names, name)
+	}
+	w.m.RUnlock()
+
+	for _, name := range names {
+		data, err := Load(filepath.Join(w.Dir, name))
+		if err != nil {
+			return err
+		}
+		if last, ok := w.data[name]; ok && reflect.DeepEqual(last, data) {
+			continue
+		}
+		w.data[name] = data
+		w.OnChange(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: w.Namespace,
+				Name:      name,
+			},
+			Data: data,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// mount writes the data as the kubelet mounts a ConfigMap volume: in a
+// timestamped directory, atomically swapped in through the ..data
+// symlink, which the keys link through.
+func mount(t *testing.T, dir, version string, data map[string]string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, version), 0755); err != nil {
+		t.Fatalf("MkdirAll() = %v", err)
+	}
+	for k, v := range data {
+		if err := ioutil.WriteFile(filepath.Join(dir, version, k), []byte(v), 0644); err != nil {
+			t.Fatalf("WriteFile() = %v", err)
+		}
+	}
+	tmp := filepath.Join(dir, "..data_tmp")
+	if err := os.Symlink(version, tmp); err != nil {
+		t.Fatalf("Symlink() = %v", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, "..data")); err != nil {
+		t.Fatalf("Rename() = %v", err)
+	}
+	for k := range data {
+		os.Symlink(filepath.Join("..data", k), filepath.Join(dir, k))
+	}
+}
+
+func TestFileWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file-watcher")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	mount(t, filepath.Join(dir, "foo"), "..v1", map[string]string{"key": "val"})
+
+	w := NewFileWatcher(dir, "default")
+	w.Interval = 10 * time.Millisecond
+	foo := &counter{name: "foo"}
+	w.Watch("foo", foo.callback)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	if err := w.Start(stopCh); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	if got, want := foo.count(), 1; got != want {
+		t.Fatalf("count = %d, wanted %d", got, want)
+	}
+	if diff := cmp.Diff(map[string]string{"key": "val"}, foo.cfg[0].Data); diff != "" {
+		t.Errorf("Data (-want, +got) = %s", diff)
+	}
+
+	// Unchanged volumes aren't observed again.
+	time.Sleep(50 * time.Millisecond)
+	if got, want := foo.count(), 1; got != want {
+		t.Errorf("count = %d, wanted %d", got, want)
+	}
+
+	// Swapping in new data is observed.
+	mount(t, filepath.Join(dir, "foo"), "..v2", map[string]string{"key": "new"})
+	for i := 0; i < 100 && foo.count() < 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got, want := foo.count(), 2; got != want {
+		t.Fatalf("count = %d, wanted %d", got, want)
+	}
+	foo.mu.RLock()
+	defer foo.mu.RUnlock()
+	if got := foo.cfg[1]; got.Namespace != "default" || got.Name != "foo" || got.Data["key"] != "new" {
+		t.Errorf("Observed %v, wanted default/foo with the new data", got)
+	}
+}
+
+func TestFileWatcherMissing(t *testing.T) {
+	w := NewFileWatcher("/does/not/exist", "default")
+	w.Watch("foo", (&counter{}).callback)
+	if err := w.Start(nil); err == nil {
+		t.Error("Start() = nil, wanted an error for the missing volume")
+	}
+}
@@ -18,6 +18,9 @@ package configmap
 
 import (
 	"errors"
+	"fmt"
+
+	"github.com/ghodss/yaml"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
@@ -67,6 +70,9 @@ type InformedWatcher struct {
 	// defaults are the default ConfigMaps to use if the real ones do not exist or are deleted.
 	defaults map[string]*corev1.ConfigMap
 
+	// auditor keeps the audit trail of the changes, if set by Audit.
+	auditor *auditor
+
 	// Embedding this struct allows us to reuse the logic
 	// of registering and notifying observers. This simplifies the
 	// InformedWatcher to just setting up the Kubernetes informer.
@@ -98,6 +104,63 @@ func (i *InformedWatcher) WatchWithDefault(cm corev1.ConfigMap, o ...Observer) {
 	i.Watch(cm.Name, o...)
 }
 
+// WatchWithDefaultYAML is like WatchWithDefault, with the default
+// ConfigMap read from its YAML manifest, such as the one shipped with
+// the component.  Additionally, the keys missing from the real ConfigMap
+// take the default value of the manifest, so that the component behaves
+// the same whether its ConfigMap is installed, partially filled in or
+// absent.  The ExampleKey of the manifest is never used as a default.
+func (i *InformedWatcher) WatchWithDefaultYAML(manifest []byte, o ...Observer) error {
+	def, err := configMapFromYAML(manifest)
+	if err != nil {
+		return err
+	}
+	i.WatchWithDefault(*def, func(cm *corev1.ConfigMap) {
+		cm = withDefaultKeys(cm, def)
+		for _, f := range o {
+			f(cm)
+		}
+	})
+	return nil
+}
+
+// configMapFromYAML reads a ConfigMap from its YAML manifest, dropping
+// its ExampleKey.
+func configMapFromYAML(manifest []byte) (*corev1.ConfigMap, error) {
+	cm := &corev1.ConfigMap{}
+	if err := yaml.Unmarshal(manifest, cm); err != nil {
+		return nil, fmt.Errorf("failed to parse the default ConfigMap: %v", err)
+	}
+	if cm.Name == "" {
+		return nil, errors.New("the default ConfigMap has no name")
+	}
+	delete(cm.Data, ExampleKey)
+	return cm, nil
+}
+
+// withDefaultKeys returns the ConfigMap with the keys missing from it
+// taken from the default ConfigMap, copying it rather than modifying the
+// informer's cache if any is missing.
+func withDefaultKeys(cm, def *corev1.ConfigMap) *corev1.ConfigMap {
+	var merged *corev1.ConfigMap
+	for k, v := range def.Data {
+		if _, ok := cm.Data[k]; ok {
+			continue
+		}
+		if merged == nil {
+			merged = cm.DeepCopy()
+			if merged.Data == nil {
+				merged.Data = make(map[string]string, len(def.Data))
+			}
+		}
+		merged.Data[k] = v
+	}
+	if merged == nil {
+		return cm
+	}
+	return merged
+}
+
 // Start implements Watcher.
 func (i *InformedWatcher) Start(stopCh <-chan struct{}) error {
 	// Pretend that all the defaulted ConfigMaps were just created. This is done before we start
@@ -158,6 +221,9 @@ func (i *InformedWatcher) checkObservedResourcesExist() error {
 
 func (i *InformedWatcher) addConfigMapEvent(obj interface{}) {
 	configMap := obj.(*corev1.ConfigMap)
+	if i.auditor != nil && i.observed(configMap) {
+		i.auditor.drifted(configMap)
+	}
 	i.OnChange(configMap)
 }
 
@@ -168,11 +234,18 @@ func (i *InformedWatcher) updateConfigMapEvent(o, n interface{}) {
 		return
 	}
 	configMap := n.(*corev1.ConfigMap)
+	if i.auditor != nil && i.observed(configMap) {
+		i.auditor.updated(o.(*corev1.ConfigMap), configMap)
+		i.auditor.drifted(configMap)
+	}
 	i.OnChange(configMap)
 }
 
 func (i *InformedWatcher) deleteConfigMapEvent(obj interface{}) {
 	configMap := obj.(*corev1.ConfigMap)
+	if i.auditor != nil && i.observed(configMap) {
+		i.auditor.deleted(configMap)
+	}
 	if def, ok := i.defaults[configMap.Name]; ok {
 		i.OnChange(def)
 	}
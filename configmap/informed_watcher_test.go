@@ -23,6 +23,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
 )
 
@@ -392,3 +393,100 @@ func TestWatchWithDefaultAfterStart(t *testing.T) {
 		t.Fatalf("foo1.count = %v, want %d", len(foo1.cfg), len(expected))
 	}
 }
+
+func TestWatchWithDefaultYAML(t *testing.T) {
+	const manifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: foo
+  namespace: default
+data:
+  _example: |
+    # Some documentation.
+  timeout: "30s"
+  retries: "3"
+`
+	tests := []struct {
+		name string
+		objs []runtime.Object
+		want []map[string]string
+	}{{
+		name: "absent",
+		want: []map[string]string{{
+			"timeout": "30s",
+			"retries": "3",
+		}},
+	}, {
+		name: "partially filled in",
+		objs: []runtime.Object{&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "foo",
+			},
+			Data: map[string]string{
+				"timeout": "1m",
+			},
+		}},
+		want: []map[string]string{{
+			"timeout": "30s",
+			"retries": "3",
+		}, {
+			"timeout": "1m",
+			"retries": "3",
+		}},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kc := fakekubeclientset.NewSimpleClientset(test.objs...)
+			cm := NewInformedWatcher(kc, "default")
+
+			foo1 := &counter{name: "foo1"}
+			if err := cm.WatchWithDefaultYAML([]byte(manifest), foo1.callback); err != nil {
+				t.Fatalf("WatchWithDefaultYAML() = %v", err)
+			}
+
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+			if err := cm.Start(stopCh); err != nil {
+				t.Fatalf("cm.Start() = %v", err)
+			}
+
+			if foo1.count() != len(test.want) {
+				t.Fatalf("foo1.count = %v, want %d", foo1.count(), len(test.want))
+			}
+			for i, want := range test.want {
+				if got := foo1.cfg[i].Data; !equality.Semantic.DeepEqual(want, got) {
+					t.Errorf("%d config seen should have been '%v', actually '%v'", i, want, got)
+				}
+			}
+			for _, obj := range test.objs {
+				if got := obj.(*corev1.ConfigMap).Data; len(got) != 1 {
+					t.Errorf("The informed ConfigMap was modified: %v", got)
+				}
+			}
+		})
+	}
+}
+
+func TestWatchWithDefaultYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+	}{{
+		name:     "not yaml",
+		manifest: "{",
+	}, {
+		name:     "no name",
+		manifest: "data:\n  foo: bar\n",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cm := NewInformedWatcher(fakekubeclientset.NewSimpleClientset(), "default")
+			if err := cm.WatchWithDefaultYAML([]byte(test.manifest)); err == nil {
+				t.Error("WatchWithDefaultYAML() = nil, wanted an error")
+			}
+		})
+	}
+}
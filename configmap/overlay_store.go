@@ -0,0 +1,222 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+)
+
+// An OverlayStore is responsible for storing and constructing a config
+// from a global Kubernetes ConfigMap, overlaid for some namespaces by a
+// namespace-local ConfigMap of the same name.
+//
+// The config of a namespace is constructed from the data of the global
+// ConfigMap, where each overridable key set by the namespace's overlay
+// takes the overlay's value.  The other keys of the overlay are ignored.
+// Namespaces without an overlay, or whose overlay fails construction
+// before ever succeeding, get the global config.
+//
+// The global ConfigMap should be fed to OnConfigChanged, e.g. through
+// WatchConfigs, and the overlays to OnOverlayChanged and
+// OnOverlayDeleted, e.g. through OverlayHandler.
+type OverlayStore struct {
+	name        string
+	configName  string
+	logger      Logger
+	constructor reflect.Value
+	overridable sets.String
+
+	// m guards the fields below.
+	m        sync.RWMutex
+	global   *corev1.ConfigMap
+	value    interface{}
+	overlays map[string]*corev1.ConfigMap
+	values   map[string]interface{}
+}
+
+// NewOverlayStore creates an OverlayStore with the given name and Logger
+// for the named ConfigMap, whose constructor must have the definition
+//
+// func(*k8s.io/api/core/v1.ConfigMap) (... , error)
+//
+// If the function definition differs then NewOverlayStore will panic.
+// overridableKeys are the keys that overlays may set.
+func NewOverlayStore(name string, logger Logger, configName string, constructor interface{}, overridableKeys ...string) *OverlayStore {
+	if err := ValidateConstructor(constructor); err != nil {
+		panic(err)
+	}
+	return &OverlayStore{
+		name:        name,
+		configName:  configName,
+		logger:      logger,
+		constructor: reflect.ValueOf(constructor),
+		overridable: sets.NewString(overridableKeys...),
+		overlays:    make(map[string]*corev1.ConfigMap),
+		values:      make(map[string]interface{}),
+	}
+}
+
+// WatchConfigs uses the provided configmap.Watcher to watch the global
+// ConfigMap.
+func (s *OverlayStore) WatchConfigs(w Watcher) {
+	w.Watch(s.configName, s.OnConfigChanged)
+}
+
+// OverlayHandler returns the event handler feeding the store with the
+// overlays from an informer of ConfigMaps across namespaces.  The global
+// ConfigMap, in globalNamespace, is not an overlay and is skipped.
+func (s *OverlayStore) OverlayHandler(globalNamespace string) cache.ResourceEventHandler {
+	return cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			cm, ok := obj.(*corev1.ConfigMap)
+			return ok && cm.Name == s.configName && cm.Namespace != globalNamespace
+		},
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				s.OnOverlayChanged(obj.(*corev1.ConfigMap))
+			},
+			UpdateFunc: func(_, obj interface{}) {
+				s.OnOverlayChanged(obj.(*corev1.ConfigMap))
+			},
+			DeleteFunc: func(obj interface{}) {
+				if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+					obj = tombstone.Obj
+				}
+				s.OnOverlayDeleted(obj.(*corev1.ConfigMap).Namespace)
+			},
+		},
+	}
+}
+
+// Load returns the config constructed for the given namespace.
+func (s *OverlayStore) Load(namespace string) interface{} {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	if v, ok := s.values[namespace]; ok {
+		return v
+	}
+	return s.value
+}
+
+// OnConfigChanged constructs the config from the global ConfigMap and
+// reconstructs those of the namespaces with overlays.  If construction
+// fails during the first appearance the store will log a fatal error.
+// If construction fails while updating the store will log an error
+// message and keep the last values.
+func (s *OverlayStore) OnConfigChanged(c *corev1.ConfigMap) {
+	value, err := s.construct(c)
+	if err != nil {
+		s.m.RLock()
+		initialized := s.global != nil
+		s.m.RUnlock()
+		if initialized {
+			s.logger.Errorf("Error updating %s config %q: %q", s.name, s.configName, err)
+		} else {
+			s.logger.Fatalf("Error initializing %s config %q: %q", s.name, s.configName, err)
+		}
+		return
+	}
+	s.logger.Infof("%s config %q config was added or updated: %#v", s.name, s.configName, value)
+
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.global = c
+	s.value = value
+
+	// Reconstruct the overlays in a deterministic order, for the logs.
+	namespaces := make([]string, 0, len(s.overlays))
+	for ns := range s.overlays {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	for _, ns := range namespaces {
+		s.overlayLocked(s.overlays[ns])
+	}
+}
+
+// OnOverlayChanged constructs the config of the overlay's namespace.
+// If construction fails the store will log an error message and keep
+// the last value of the namespace.
+func (s *OverlayStore) OnOverlayChanged(c *corev1.ConfigMap) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.overlays[c.Namespace] = c
+	if s.global != nil {
+		s.overlayLocked(c)
+	}
+}
+
+// OnOverlayDeleted reverts the namespace to the global config.
+func (s *OverlayStore) OnOverlayDeleted(namespace string) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	delete(s.overlays, namespace)
+	delete(s.values, namespace)
+	s.logger.Infof("%s config %q overlay was deleted from namespace %q", s.name, s.configName, namespace)
+}
+
+// overlayLocked constructs the config of the overlay's namespace from
+// the global ConfigMap.  s.m must be held.
+func (s *OverlayStore) overlayLocked(overlay *corev1.ConfigMap) {
+	merged := s.global.DeepCopy()
+	merged.Namespace = overlay.Namespace
+	if merged.Data == nil {
+		merged.Data = make(map[string]string, len(overlay.Data))
+	}
+	var ignored []string
+	for k, v := range overlay.Data {
+		if !s.overridable.Has(k) {
+			if k != ExampleKey {
+				ignored = append(ignored, k)
+			}
+			continue
+		}
+		merged.Data[k] = v
+	}
+	if len(ignored) > 0 {
+		sort.Strings(ignored)
+		s.logger.Errorf("Ignoring keys %v of %s config %q overlay in namespace %q, which are not overridable",
+			ignored, s.name, s.configName, overlay.Namespace)
+	}
+
+	value, err := s.construct(merged)
+	if err != nil {
+		s.logger.Errorf("Error updating %s config %q overlay in namespace %q: %q",
+			s.name, s.configName, overlay.Namespace, err)
+		return
+	}
+	s.logger.Infof("%s config %q overlay in namespace %q was added or updated: %#v",
+		s.name, s.configName, overlay.Namespace, value)
+	s.values[overlay.Namespace] = value
+}
+
+func (s *OverlayStore) construct(c *corev1.ConfigMap) (interface{}, error) {
+	outputs := s.constructor.Call([]reflect.Value{reflect.ValueOf(c)})
+	if errVal := outputs[1]; !errVal.IsNil() {
+		return nil, errVal.Interface().(error)
+	}
+	return outputs[0].Interface(), nil
+}
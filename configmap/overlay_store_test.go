@@ -0,0 +1,145 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+func overlayConfigMap(namespace string, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      "config-test",
+		},
+		Data: data,
+	}
+}
+
+func newTestOverlayStore(t *testing.T) *OverlayStore {
+	constructor := func(c *corev1.ConfigMap) (map[string]string, error) {
+		if c.Data["bad"] != "" {
+			return nil, errors.New("bad config")
+		}
+		return c.Data, nil
+	}
+	return NewOverlayStore("test", TestLogger(t), "config-test", constructor, "level", "bad")
+}
+
+func TestOverlayStore(t *testing.T) {
+	store := newTestOverlayStore(t)
+
+	// Overlays seen before the global ConfigMap are applied once it is.
+	store.OnOverlayChanged(overlayConfigMap("ns1", map[string]string{
+		"level":  "debug",
+		"region": "ignored",
+	}))
+	store.OnConfigChanged(overlayConfigMap("system", map[string]string{
+		"level":  "info",
+		"region": "us",
+	}))
+	store.OnOverlayChanged(overlayConfigMap("ns2", map[string]string{
+		ExampleKey: "ignored",
+	}))
+
+	tests := []struct {
+		namespace string
+		want      map[string]string
+	}{{
+		namespace: "ns1",
+		want:      map[string]string{"level": "debug", "region": "us"},
+	}, {
+		namespace: "ns2",
+		want:      map[string]string{"level": "info", "region": "us"},
+	}, {
+		namespace: "ns3",
+		want:      map[string]string{"level": "info", "region": "us"},
+	}}
+	for _, test := range tests {
+		if diff := cmp.Diff(test.want, store.Load(test.namespace)); diff != "" {
+			t.Errorf("Load(%q) (-want, +got) = %s", test.namespace, diff)
+		}
+	}
+
+	// Global updates are reflected in the overlays.
+	store.OnConfigChanged(overlayConfigMap("system", map[string]string{
+		"level":  "info",
+		"region": "eu",
+	}))
+	if diff := cmp.Diff(map[string]string{"level": "debug", "region": "eu"}, store.Load("ns1")); diff != "" {
+		t.Errorf("Load(ns1) (-want, +got) = %s", diff)
+	}
+
+	// Bad overlays keep the last good value.
+	store.OnOverlayChanged(overlayConfigMap("ns1", map[string]string{
+		"bad": "true",
+	}))
+	if diff := cmp.Diff(map[string]string{"level": "debug", "region": "eu"}, store.Load("ns1")); diff != "" {
+		t.Errorf("Load(ns1) (-want, +got) = %s", diff)
+	}
+
+	// Bad global updates keep the last good values.
+	store.OnConfigChanged(overlayConfigMap("system", map[string]string{
+		"bad": "true",
+	}))
+	if diff := cmp.Diff(map[string]string{"level": "info", "region": "eu"}, store.Load("ns3")); diff != "" {
+		t.Errorf("Load(ns3) (-want, +got) = %s", diff)
+	}
+
+	// Deleted overlays revert to the global value.
+	store.OnOverlayDeleted("ns1")
+	if diff := cmp.Diff(map[string]string{"level": "info", "region": "eu"}, store.Load("ns1")); diff != "" {
+		t.Errorf("Load(ns1) (-want, +got) = %s", diff)
+	}
+}
+
+func TestOverlayHandler(t *testing.T) {
+	store := newTestOverlayStore(t)
+	store.OnConfigChanged(overlayConfigMap("system", map[string]string{"level": "info"}))
+	handler := store.OverlayHandler("system")
+
+	overlay := overlayConfigMap("ns1", map[string]string{"level": "debug"})
+	handler.OnAdd(overlay)
+	other := overlayConfigMap("ns2", map[string]string{"level": "debug"})
+	other.Name = "config-other"
+	handler.OnAdd(other)
+	handler.OnAdd(overlayConfigMap("system", map[string]string{"level": "error"}))
+
+	want := map[string]map[string]string{
+		"ns1":    {"level": "debug"},
+		"ns2":    {"level": "info"},
+		"system": {"level": "info"},
+	}
+	for ns, w := range want {
+		if diff := cmp.Diff(w, store.Load(ns)); diff != "" {
+			t.Errorf("Load(%q) (-want, +got) = %s", ns, diff)
+		}
+	}
+
+	handler.OnDelete(cache.DeletedFinalStateUnknown{Key: "ns1/config-test", Obj: overlay})
+	if diff := cmp.Diff(map[string]string{"level": "info"}, store.Load("ns1")); diff != "" {
+		t.Errorf("Load(ns1) (-want, +got) = %s", diff)
+	}
+}
@@ -0,0 +1,260 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"knative.dev/pkg/apis"
+)
+
+// ParseFunc is a function taking ConfigMap data and applying a parse operation to it.
+type ParseFunc func(map[string]string) error
+
+// Parse parses the given map using the parser functions passed in.
+// Keys missing from the map leave their targets untouched, so targets
+// may be initialized with their defaults beforehand.
+func Parse(data map[string]string, parsers ...ParseFunc) error {
+	for _, parse := range parsers {
+		if err := parse(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AsString passes the value at key through into the target, if it exists.
+func AsString(key string, target *string) ParseFunc {
+	return func(data map[string]string) error {
+		if raw, ok := data[key]; ok {
+			*target = raw
+		}
+		return nil
+	}
+}
+
+// AsBool parses the value at key as a boolean into the target, if it exists.
+func AsBool(key string, target *bool) ParseFunc {
+	return func(data map[string]string) error {
+		if raw, ok := data[key]; ok {
+			val, err := strconv.ParseBool(raw)
+			if err != nil {
+				return fmt.Errorf("failed to parse %q: %v", key, err)
+			}
+			*target = val
+		}
+		return nil
+	}
+}
+
+// AsInt32 parses the value at key as an int32 into the target, if it exists.
+func AsInt32(key string, target *int32) ParseFunc {
+	return func(data map[string]string) error {
+		if raw, ok := data[key]; ok {
+			val, err := strconv.ParseInt(raw, 10, 32)
+			if err != nil {
+				return fmt.Errorf("failed to parse %q: %v", key, err)
+			}
+			*target = int32(val)
+		}
+		return nil
+	}
+}
+
+// AsInt64 parses the value at key as an int64 into the target, if it exists.
+func AsInt64(key string, target *int64) ParseFunc {
+	return func(data map[string]string) error {
+		if raw, ok := data[key]; ok {
+			val, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return fmt.Errorf("failed to parse %q: %v", key, err)
+			}
+			*target = val
+		}
+		return nil
+	}
+}
+
+// AsInt parses the value at key as an int into the target, if it exists.
+func AsInt(key string, target *int) ParseFunc {
+	return func(data map[string]string) error {
+		if raw, ok := data[key]; ok {
+			val, err := strconv.Atoi(raw)
+			if err != nil {
+				return fmt.Errorf("failed to parse %q: %v", key, err)
+			}
+			*target = val
+		}
+		return nil
+	}
+}
+
+// AsFloat64 parses the value at key as a float64 into the target, if it exists.
+func AsFloat64(key string, target *float64) ParseFunc {
+	return func(data map[string]string) error {
+		if raw, ok := data[key]; ok {
+			val, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return fmt.Errorf("failed to parse %q: %v", key, err)
+			}
+			*target = val
+		}
+		return nil
+	}
+}
+
+// AsDuration parses the value at key as a time.Duration into the target, if it exists.
+func AsDuration(key string, target *time.Duration) ParseFunc {
+	return func(data map[string]string) error {
+		if raw, ok := data[key]; ok {
+			val, err := time.ParseDuration(raw)
+			if err != nil {
+				return fmt.Errorf("failed to parse %q: %v", key, err)
+			}
+			*target = val
+		}
+		return nil
+	}
+}
+
+// AsDurations parses the value at key as a comma-separated list of
+// time.Durations into the target, if it exists.
+func AsDurations(key string, target *[]time.Duration) ParseFunc {
+	return func(data map[string]string) error {
+		if raw, ok := data[key]; ok {
+			vals := []time.Duration{}
+			for _, s := range splitList(raw) {
+				val, err := time.ParseDuration(s)
+				if err != nil {
+					return fmt.Errorf("failed to parse %q: %v", key, err)
+				}
+				vals = append(vals, val)
+			}
+			*target = vals
+		}
+		return nil
+	}
+}
+
+// AsStringSet parses the value at key as a sets.String (split by ',') into the target, if it exists.
+func AsStringSet(key string, target *sets.String) ParseFunc {
+	return func(data map[string]string) error {
+		if raw, ok := data[key]; ok {
+			*target = sets.NewString(splitList(raw)...)
+		}
+		return nil
+	}
+}
+
+// AsQuantity parses the value at key as a *resource.Quantity into the target, if it exists.
+func AsQuantity(key string, target **resource.Quantity) ParseFunc {
+	return func(data map[string]string) error {
+		if raw, ok := data[key]; ok {
+			val, err := resource.ParseQuantity(raw)
+			if err != nil {
+				return fmt.Errorf("failed to parse %q: %v", key, err)
+			}
+			*target = &val
+		}
+		return nil
+	}
+}
+
+// AsURLs parses the value at key as a comma-separated list of
+// apis.URLs into the target, if it exists.
+func AsURLs(key string, target *[]apis.URL) ParseFunc {
+	return func(data map[string]string) error {
+		if raw, ok := data[key]; ok {
+			vals := []apis.URL{}
+			for _, s := range splitList(raw) {
+				val, err := apis.ParseURL(s)
+				if err != nil {
+					return fmt.Errorf("failed to parse %q: %v", key, err)
+				}
+				vals = append(vals, *val)
+			}
+			*target = vals
+		}
+		return nil
+	}
+}
+
+// AsYAMLMap parses the value at key as an inline YAML (or JSON) mapping
+// of strings into the target, if it exists.
+func AsYAMLMap(key string, target *map[string]string) ParseFunc {
+	return func(data map[string]string) error {
+		if raw, ok := data[key]; ok {
+			val := map[string]string{}
+			if err := yaml.Unmarshal([]byte(raw), &val); err != nil {
+				return fmt.Errorf("failed to parse %q: %v", key, err)
+			}
+			*target = val
+		}
+		return nil
+	}
+}
+
+// AsOneOf passes the value at key through into the target, if it exists
+// and is one of the allowed values.
+func AsOneOf(key string, target *string, allowed ...string) ParseFunc {
+	return func(data map[string]string) error {
+		if raw, ok := data[key]; ok {
+			for _, a := range allowed {
+				if raw == a {
+					*target = raw
+					return nil
+				}
+			}
+			return fmt.Errorf("failed to parse %q: %q is not one of %v", key, raw, allowed)
+		}
+		return nil
+	}
+}
+
+// AsStringMatching passes the value at key through into the target, if it
+// exists and matches the regular expression.
+func AsStringMatching(key string, target *string, re *regexp.Regexp) ParseFunc {
+	return func(data map[string]string) error {
+		if raw, ok := data[key]; ok {
+			if !re.MatchString(raw) {
+				return fmt.Errorf("failed to parse %q: %q does not match %q", key, raw, re)
+			}
+			*target = raw
+		}
+		return nil
+	}
+}
+
+// splitList splits a comma-separated list, trimming its elements and
+// dropping the empty ones.
+func splitList(raw string) []string {
+	var vals []string
+	for _, s := range strings.Split(raw, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			vals = append(vals, s)
+		}
+	}
+	return vals
+}
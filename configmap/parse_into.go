@@ -0,0 +1,215 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"knative.dev/pkg/apis"
+)
+
+// The struct tags understood by ParseInto and Example.
+//
+//	type Config struct {
+//	  Timeout time.Duration `configmap:"timeout" default:"30" unit:"s" doc:"How long to wait."`
+//	  Target  string        `configmap:"target,required" doc:"Where to send things."`
+//	}
+//
+// The configmap tag holds the key of the field, optionally followed by
+// ",required".  Untagged fields are ignored.  The unit tag is only
+// allowed on time.Duration, []time.Duration and *resource.Quantity
+// fields, and is appended to the values that are bare numbers.
+const (
+	keyTag     = "configmap"
+	defaultTag = "default"
+	unitTag    = "unit"
+	docTag     = "doc"
+)
+
+// field is a tagged field of a config struct.
+type field struct {
+	key      string
+	def      string
+	required bool
+	unit     string
+	doc      string
+	target   interface{}
+}
+
+// ParseInto parses the given map into the struct pointed to by cfg,
+// according to the tags of its fields.  Keys missing from the map take
+// the default of their field, if any, and otherwise leave it untouched.
+// A missing required key is an error.
+func ParseInto(data map[string]string, cfg interface{}) error {
+	fields, err := fieldsOf(cfg)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		raw, ok := data[f.key]
+		if !ok {
+			if f.required {
+				return fmt.Errorf("missing required key %q", f.key)
+			}
+			if f.def == "" {
+				continue
+			}
+			raw = f.def
+		}
+		if f.unit != "" {
+			raw = withUnit(raw, f.unit)
+		}
+		parse, err := collector(f.key, f.target)
+		if err != nil {
+			return err
+		}
+		if err := parse(map[string]string{f.key: raw}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Example returns the content of the ExampleKey of the ConfigMap parsed
+// into the struct pointed to by cfg: every key with its default and
+// documentation, commented out.
+func Example(cfg interface{}) (string, error) {
+	fields, err := fieldsOf(cfg)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		for _, line := range strings.Split(f.doc, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				fmt.Fprintf(&b, "# %s\n", line)
+			}
+		}
+		if f.unit != "" {
+			fmt.Fprintf(&b, "# Bare numbers are in units of %q.\n", f.unit)
+		}
+		if f.required {
+			b.WriteString("# This key is required.\n")
+		}
+		fmt.Fprintf(&b, "# %s: %s\n", f.key, strconv.Quote(f.def))
+	}
+	return b.String(), nil
+}
+
+// fieldsOf returns the tagged fields of the struct pointed to by cfg.
+func fieldsOf(cfg interface{}) ([]field, error) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a pointer to a struct, got %T", cfg)
+	}
+	v = v.Elem()
+
+	var fields []field
+	for i := 0; i < v.NumField(); i++ {
+		sf := v.Type().Field(i)
+		tag, ok := sf.Tag.Lookup(keyTag)
+		if !ok {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		f := field{
+			key:    parts[0],
+			def:    sf.Tag.Get(defaultTag),
+			unit:   sf.Tag.Get(unitTag),
+			doc:    sf.Tag.Get(docTag),
+			target: v.Field(i).Addr().Interface(),
+		}
+		for _, opt := range parts[1:] {
+			if opt != "required" {
+				return nil, fmt.Errorf("unknown option %q for field %s", opt, sf.Name)
+			}
+			f.required = true
+		}
+		if f.key == "" {
+			return nil, fmt.Errorf("missing key for field %s", sf.Name)
+		}
+		if f.required && f.def != "" {
+			return nil, fmt.Errorf("required field %s must not have a default", sf.Name)
+		}
+		if _, err := collector(f.key, f.target); err != nil {
+			return nil, err
+		}
+		if f.unit != "" {
+			switch f.target.(type) {
+			case *time.Duration, *[]time.Duration, **resource.Quantity:
+			default:
+				return nil, fmt.Errorf("unit is not supported for field %s", sf.Name)
+			}
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// collector returns the ParseFunc of the given key for the type of target.
+func collector(key string, target interface{}) (ParseFunc, error) {
+	switch t := target.(type) {
+	case *string:
+		return AsString(key, t), nil
+	case *bool:
+		return AsBool(key, t), nil
+	case *int32:
+		return AsInt32(key, t), nil
+	case *int64:
+		return AsInt64(key, t), nil
+	case *int:
+		return AsInt(key, t), nil
+	case *float64:
+		return AsFloat64(key, t), nil
+	case *time.Duration:
+		return AsDuration(key, t), nil
+	case *[]time.Duration:
+		return AsDurations(key, t), nil
+	case *sets.String:
+		return AsStringSet(key, t), nil
+	case **resource.Quantity:
+		return AsQuantity(key, t), nil
+	case *[]apis.URL:
+		return AsURLs(key, t), nil
+	case *map[string]string:
+		return AsYAMLMap(key, t), nil
+	default:
+		return nil, fmt.Errorf("unsupported type %T for key %q", target, key)
+	}
+}
+
+// withUnit appends the unit to the elements of the comma-separated
+// list that are bare numbers.
+func withUnit(raw, unit string) string {
+	vals := splitList(raw)
+	for i, v := range vals {
+		if _, err := strconv.ParseFloat(v, 64); err == nil {
+			vals[i] = v + unit
+		}
+	}
+	return strings.Join(vals, ",")
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+type taggedConfig struct {
+	Target   string             `configmap:"target,required" doc:"Where to send things."`
+	Timeout  time.Duration      `configmap:"timeout" default:"30" unit:"s" doc:"How long to wait."`
+	Backoffs []time.Duration    `configmap:"backoffs" default:"1,500ms" unit:"s"`
+	Memory   *resource.Quantity `configmap:"memory" default:"64" unit:"Mi"`
+	Enabled  bool               `configmap:"enabled" default:"true"`
+	Tags     sets.String        `configmap:"tags"`
+
+	Ignored string
+}
+
+func TestParseInto(t *testing.T) {
+	sixtyFourMi := resource.MustParse("64Mi")
+	oneGi := resource.MustParse("1Gi")
+
+	tests := []struct {
+		name    string
+		data    map[string]string
+		want    taggedConfig
+		wantErr bool
+	}{{
+		name: "defaults",
+		data: map[string]string{
+			"target": "foo",
+		},
+		want: taggedConfig{
+			Target:   "foo",
+			Timeout:  30 * time.Second,
+			Backoffs: []time.Duration{time.Second, 500 * time.Millisecond},
+			Memory:   &sixtyFourMi,
+			Enabled:  true,
+		},
+	}, {
+		name: "overrides",
+		data: map[string]string{
+			"target":   "foo",
+			"timeout":  "1m",
+			"backoffs": "2",
+			"memory":   "1Gi",
+			"enabled":  "false",
+			"tags":     "a,b",
+			"Ignored":  "bar",
+		},
+		want: taggedConfig{
+			Target:   "foo",
+			Timeout:  time.Minute,
+			Backoffs: []time.Duration{2 * time.Second},
+			Memory:   &oneGi,
+			Tags:     sets.NewString("a", "b"),
+		},
+	}, {
+		name:    "missing required",
+		data:    map[string]string{},
+		wantErr: true,
+	}, {
+		name: "bad value",
+		data: map[string]string{
+			"target":  "foo",
+			"timeout": "soon",
+		},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got taggedConfig
+			err := ParseInto(test.data, &got)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ParseInto() = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("ParseInto (-want, +got) = %s", diff)
+			}
+		})
+	}
+}
+
+func TestParseIntoBadStructs(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  interface{}
+	}{{
+		name: "not a pointer",
+		cfg:  taggedConfig{},
+	}, {
+		name: "not a struct",
+		cfg:  new(string),
+	}, {
+		name: "unsupported type",
+		cfg: &struct {
+			C chan int `configmap:"c"`
+		}{},
+	}, {
+		name: "missing key",
+		cfg: &struct {
+			S string `configmap:",required"`
+		}{},
+	}, {
+		name: "unknown option",
+		cfg: &struct {
+			S string `configmap:"s,optional"`
+		}{},
+	}, {
+		name: "required with default",
+		cfg: &struct {
+			S string `configmap:"s,required" default:"foo"`
+		}{},
+	}, {
+		name: "unit on unsupported type",
+		cfg: &struct {
+			I int `configmap:"i" unit:"s"`
+		}{},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := ParseInto(map[string]string{}, test.cfg); err == nil {
+				t.Error("ParseInto() = nil, wanted an error")
+			}
+			if _, err := Example(test.cfg); err == nil {
+				t.Error("Example() = nil, wanted an error")
+			}
+		})
+	}
+}
+
+func TestExample(t *testing.T) {
+	got, err := Example(&taggedConfig{})
+	if err != nil {
+		t.Fatalf("Example() = %v", err)
+	}
+
+	want := `# Where to send things.
+# This key is required.
+# target: ""
+
+# How long to wait.
+# Bare numbers are in units of "s".
+# timeout: "30"
+
+# Bare numbers are in units of "s".
+# backoffs: "1,500ms"
+
+# Bare numbers are in units of "Mi".
+# memory: "64"
+
+# enabled: "true"
+
+# tags: ""
+`
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Example (-want, +got) = %s", diff)
+	}
+}
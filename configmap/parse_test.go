@@ -0,0 +1,216 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"knative.dev/pkg/apis"
+)
+
+type testConfig struct {
+	str    string
+	boo    bool
+	i32    int32
+	i64    int64
+	i      int
+	f64    float64
+	dur    time.Duration
+	durs   []time.Duration
+	set    sets.String
+	qua    *resource.Quantity
+	urls   []apis.URL
+	labels map[string]string
+	mode   string
+	name   string
+}
+
+func TestParse(t *testing.T) {
+	fiveHundredM := resource.MustParse("500m")
+	nameRE := regexp.MustCompile(`^[a-z]+$`)
+
+	tests := []struct {
+		name    string
+		conf    testConfig
+		data    map[string]string
+		want    testConfig
+		wantErr bool
+	}{{
+		name: "all good",
+		data: map[string]string{
+			"test-string":    "foo.bar",
+			"test-bool":      "true",
+			"test-int32":     "1",
+			"test-int64":     "2",
+			"test-int":       "3",
+			"test-float64":   "1.0",
+			"test-duration":  "1m",
+			"test-durations": "1s, 1m,1h",
+			"test-set":       "a,b,c, d",
+			"test-quantity":  "500m",
+			"test-urls":      "http://a.com, https://b.com/c",
+			"test-labels":    "foo: bar\nbaz: qux",
+			"test-mode":      "fast",
+			"test-name":      "abc",
+		},
+		want: testConfig{
+			str:  "foo.bar",
+			boo:  true,
+			i32:  1,
+			i64:  2,
+			i:    3,
+			f64:  1.0,
+			dur:  time.Minute,
+			durs: []time.Duration{time.Second, time.Minute, time.Hour},
+			set:  sets.NewString("a", "b", "c", "d"),
+			qua:  &fiveHundredM,
+			urls: []apis.URL{{
+				Scheme: "http",
+				Host:   "a.com",
+			}, {
+				Scheme: "https",
+				Host:   "b.com",
+				Path:   "/c",
+			}},
+			labels: map[string]string{"foo": "bar", "baz": "qux"},
+			mode:   "fast",
+			name:   "abc",
+		},
+	}, {
+		name: "respect defaults",
+		conf: testConfig{
+			str:  "foo.bar",
+			dur:  time.Minute,
+			mode: "slow",
+		},
+		want: testConfig{
+			str:  "foo.bar",
+			dur:  time.Minute,
+			mode: "slow",
+		},
+	}, {
+		name: "bool error",
+		data: map[string]string{
+			"test-bool": "foo",
+		},
+		wantErr: true,
+	}, {
+		name: "int32 error",
+		data: map[string]string{
+			"test-int32": "foo",
+		},
+		wantErr: true,
+	}, {
+		name: "int64 error",
+		data: map[string]string{
+			"test-int64": "foo",
+		},
+		wantErr: true,
+	}, {
+		name: "int error",
+		data: map[string]string{
+			"test-int": "foo",
+		},
+		wantErr: true,
+	}, {
+		name: "float64 error",
+		data: map[string]string{
+			"test-float64": "foo",
+		},
+		wantErr: true,
+	}, {
+		name: "duration error",
+		data: map[string]string{
+			"test-duration": "foo",
+		},
+		wantErr: true,
+	}, {
+		name: "durations error",
+		data: map[string]string{
+			"test-durations": "1s,foo",
+		},
+		wantErr: true,
+	}, {
+		name: "quantity error",
+		data: map[string]string{
+			"test-quantity": "foo",
+		},
+		wantErr: true,
+	}, {
+		name: "urls error",
+		data: map[string]string{
+			"test-urls": "http://a.com,:foo",
+		},
+		wantErr: true,
+	}, {
+		name: "labels error",
+		data: map[string]string{
+			"test-labels": "- foo",
+		},
+		wantErr: true,
+	}, {
+		name: "mode not allowed",
+		data: map[string]string{
+			"test-mode": "medium",
+		},
+		wantErr: true,
+	}, {
+		name: "name does not match",
+		data: map[string]string{
+			"test-name": "ABC",
+		},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Parse(test.data,
+				AsString("test-string", &test.conf.str),
+				AsBool("test-bool", &test.conf.boo),
+				AsInt32("test-int32", &test.conf.i32),
+				AsInt64("test-int64", &test.conf.i64),
+				AsInt("test-int", &test.conf.i),
+				AsFloat64("test-float64", &test.conf.f64),
+				AsDuration("test-duration", &test.conf.dur),
+				AsDurations("test-durations", &test.conf.durs),
+				AsStringSet("test-set", &test.conf.set),
+				AsQuantity("test-quantity", &test.conf.qua),
+				AsURLs("test-urls", &test.conf.urls),
+				AsYAMLMap("test-labels", &test.conf.labels),
+				AsOneOf("test-mode", &test.conf.mode, "fast", "slow"),
+				AsStringMatching("test-name", &test.conf.name, nameRE),
+			)
+
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Parse() = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+
+			if diff := cmp.Diff(test.want, test.conf, cmp.AllowUnexported(testConfig{})); diff != "" {
+				t.Errorf("Parse (-want, +got) = %s", diff)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ package configmap
 
 import (
 	"fmt"
+	"sync"
 
 	corev1 "k8s.io/api/core/v1"
 )
@@ -28,13 +29,21 @@ func NewStaticWatcher(cms ...*corev1.ConfigMap) *StaticWatcher {
 	for _, cm := range cms {
 		cmm[cm.Name] = cm
 	}
-	return &StaticWatcher{cfgs: cmm}
+	return &StaticWatcher{
+		cfgs:      cmm,
+		observers: make(map[string][]Observer),
+	}
 }
 
 // StaticWatcher is a Watcher with static ConfigMaps. Callbacks will
-// occur when Watch is invoked for a specific Observer
+// occur when Watch is invoked for a specific Observer, and again when
+// OnChange is invoked with an updated ConfigMap, e.g. by tests of
+// config reloading.
 type StaticWatcher struct {
-	cfgs map[string]*corev1.ConfigMap
+	// m guards cfgs and observers.
+	m         sync.RWMutex
+	cfgs      map[string]*corev1.ConfigMap
+	observers map[string][]Observer
 }
 
 // Asserts that fixedImpl implements Watcher.
@@ -42,7 +51,13 @@ var _ Watcher = (*StaticWatcher)(nil)
 
 // Watch implements Watcher
 func (di *StaticWatcher) Watch(name string, o ...Observer) {
+	di.m.Lock()
 	cm, ok := di.cfgs[name]
+	if ok {
+		di.observers[name] = append(di.observers[name], o...)
+	}
+	di.m.Unlock()
+
 	if ok {
 		for _, observer := range o {
 			observer(cm)
@@ -56,3 +71,20 @@ func (di *StaticWatcher) Watch(name string, o ...Observer) {
 func (di *StaticWatcher) Start(<-chan struct{}) error {
 	return nil
 }
+
+// OnChange replaces the ConfigMap of the same name, which must be known
+// to the watcher, and notifies its observers.
+func (di *StaticWatcher) OnChange(cm *corev1.ConfigMap) {
+	di.m.Lock()
+	if _, ok := di.cfgs[cm.Name]; !ok {
+		di.m.Unlock()
+		panic(fmt.Sprintf("Tried to change unknown config with name %q", cm.Name))
+	}
+	di.cfgs[cm.Name] = cm
+	observers := di.observers[cm.Name]
+	di.m.Unlock()
+
+	for _, observer := range observers {
+		observer(cm)
+	}
+}
@@ -70,3 +70,65 @@ func TestUnknownConfigMapName(t *testing.T) {
 	cm := NewStaticWatcher()
 	cm.Watch("unknown", func(*corev1.ConfigMap) {})
 }
+
+func TestStaticWatcherOnChange(t *testing.T) {
+	fooCM := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "knative-system",
+			Name:      "foo",
+		},
+	}
+	barCM := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "knative-system",
+			Name:      "bar",
+		},
+	}
+
+	cm := NewStaticWatcher(fooCM, barCM)
+
+	foo1 := &counter{name: "foo1"}
+	cm.Watch("foo", foo1.callback)
+	bar := &counter{name: "bar"}
+	cm.Watch("bar", bar.callback)
+
+	if err := cm.Start(nil); err != nil {
+		t.Fatalf("cm.Start() = %v", err)
+	}
+
+	newFooCM := fooCM.DeepCopy()
+	newFooCM.Data = map[string]string{"key": "value"}
+	cm.OnChange(newFooCM)
+
+	if got, want := foo1.count(), 2; got != want {
+		t.Fatalf("foo1.count = %v, want %v", got, want)
+	}
+	if got := foo1.cfg[1]; got != newFooCM {
+		t.Errorf("foo1 observed %v, want %v", got, newFooCM)
+	}
+	if got, want := bar.count(), 1; got != want {
+		t.Errorf("bar.count = %v, want %v", got, want)
+	}
+
+	// Later observers see the updated ConfigMap.
+	foo2 := &counter{name: "foo2"}
+	cm.Watch("foo", foo2.callback)
+	if got := foo2.cfg[0]; got != newFooCM {
+		t.Errorf("foo2 observed %v, want %v", got, newFooCM)
+	}
+}
+
+func TestStaticWatcherOnChangeUnknownConfigMap(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected calling OnChange with an unknown configmap name to panic")
+		}
+	}()
+
+	cm := NewStaticWatcher()
+	cm.OnChange(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "unknown",
+		},
+	})
+}
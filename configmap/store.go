@@ -21,6 +21,7 @@ import (
 	"sync/atomic"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
 )
 
 // ExampleKey signifies a given example configuration in a ConfigMap.
@@ -54,8 +55,13 @@ type UntypedStore struct {
 	logger Logger
 
 	storages     map[string]*atomic.Value
+	versions     map[string]*atomic.Value
 	constructors map[string]reflect.Value
 
+	// validate and recorder are set by NewValidatedStore.
+	validate Validator
+	recorder record.EventRecorder
+
 	onAfterStore []func(name string, value interface{})
 }
 
@@ -89,6 +95,7 @@ func NewUntypedStore(
 		name:         name,
 		logger:       logger,
 		storages:     make(map[string]*atomic.Value),
+		versions:     make(map[string]*atomic.Value),
 		constructors: make(map[string]reflect.Value),
 		onAfterStore: onAfterStore,
 	}
@@ -106,6 +113,7 @@ func (s *UntypedStore) registerConfig(name string, constructor interface{}) {
 	}
 
 	s.storages[name] = &atomic.Value{}
+	s.versions[name] = &atomic.Value{}
 	s.constructors[name] = reflect.ValueOf(constructor)
 }
 
@@ -125,11 +133,26 @@ func (s *UntypedStore) UntypedLoad(name string) interface{} {
 	return storage.Load()
 }
 
+// UntypedVersion will return the resource version of the
+// ConfigMap from which the value for a given ConfigMap name
+// was constructed, or "" for unknown names.
+func (s *UntypedStore) UntypedVersion(name string) string {
+	version, ok := s.versions[name]
+	if !ok {
+		return ""
+	}
+	if v, ok := version.Load().(string); ok {
+		return v
+	}
+	return ""
+}
+
 // OnConfigChanged will invoke the mapped constructor against
-// a Kubernetes ConfigMap. If successful it will be stored.
+// a Kubernetes ConfigMap. If successful (and valid, for stores
+// made with NewValidatedStore) it will be stored.
 // If construction fails during the first appearance the store
 // will log a fatal error. If construction fails while updating
-// the store will log an error message.
+// the store will log an error message and keep the last value.
 func (s *UntypedStore) OnConfigChanged(c *corev1.ConfigMap) {
 	name := c.ObjectMeta.Name
 
@@ -144,10 +167,17 @@ func (s *UntypedStore) OnConfigChanged(c *corev1.ConfigMap) {
 	result := outputs[0].Interface()
 	errVal := outputs[1]
 
+	var err error
 	if !errVal.IsNil() {
-		err := errVal.Interface()
+		err = errVal.Interface().(error)
+	} else if s.validate != nil {
+		err = s.validate(name, result)
+	}
+
+	if err != nil {
 		if storage.Load() != nil {
 			s.logger.Errorf("Error updating %s config %q: %q", s.name, name, err)
+			s.reject(c, err)
 		} else {
 			s.logger.Fatalf("Error initializing %s config %q: %q", s.name, name, err)
 		}
@@ -156,6 +186,7 @@ func (s *UntypedStore) OnConfigChanged(c *corev1.ConfigMap) {
 
 	s.logger.Infof("%s config %q config was added or updated: %#v", s.name, name, result)
 	storage.Store(result)
+	s.versions[name].Store(c.ResourceVersion)
 
 	go func() {
 		for _, f := range s.onAfterStore {
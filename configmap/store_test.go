@@ -222,6 +222,25 @@ func TestStoreConfigChange(t *testing.T) {
 	}
 }
 
+func TestStoreUnknownVersion(t *testing.T) {
+	store := NewUntypedStore(
+		"name",
+		TestLogger(t),
+		Constructors{
+			"config-name-1": func(c *corev1.ConfigMap) (interface{}, error) {
+				return c.Name, nil
+			},
+		},
+	)
+
+	if got := store.UntypedVersion("config-name-1"); got != "" {
+		t.Errorf("UntypedVersion() = %q before any update, wanted empty", got)
+	}
+	if got := store.UntypedVersion("unknown"); got != "" {
+		t.Errorf("UntypedVersion() = %q for an unknown name, wanted empty", got)
+	}
+}
+
 func TestStoreFailedFirstConversionCrashes(t *testing.T) {
 	if os.Getenv("CRASH") == "1" {
 		constructor := func(c *corev1.ConfigMap) (interface{}, error) {
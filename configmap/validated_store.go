@@ -0,0 +1,100 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"context"
+	"sync"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// ReasonConfigRejected is the reason of the warning events emitted
+// when an update to a ConfigMap is rejected by a validated store.
+const ReasonConfigRejected = "ConfigRejected"
+
+// Validator is the signature of the predicates that a value
+// constructed from the named ConfigMap must satisfy for a
+// validated store to store it.
+type Validator func(name string, value interface{}) error
+
+var (
+	rejectedUpdateCountM = stats.Int64(
+		"config_rejected_update_count",
+		"The number of ConfigMap updates rejected by a config store",
+		stats.UnitDimensionless)
+
+	storeNameKey  = tag.MustNewKey("store")
+	configNameKey = tag.MustNewKey("config")
+
+	rejectedUpdateCountView = &view.View{
+		Description: rejectedUpdateCountM.Description(),
+		Measure:     rejectedUpdateCountM,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{storeNameKey, configNameKey},
+	}
+
+	// registerViewOnce registers the view with the first validated store,
+	// so that binaries without one don't export it.
+	registerViewOnce sync.Once
+)
+
+// NewValidatedStore creates an UntypedStore like NewUntypedStore,
+// whose values must additionally satisfy validate to be stored.
+//
+// An update whose construction or validation fails is rejected,
+// leaving the last-known-good value in the store.  Rejections are
+// counted by the config_rejected_update_count metric and, when the
+// recorder is not nil, reported as warning events on the ConfigMap.
+func NewValidatedStore(
+	name string,
+	logger Logger,
+	constructors Constructors,
+	validate Validator,
+	recorder record.EventRecorder,
+	onAfterStore ...func(name string, value interface{})) *UntypedStore {
+
+	registerViewOnce.Do(func() {
+		if err := view.Register(rejectedUpdateCountView); err != nil {
+			logger.Errorf("Failed to register the config_rejected_update_count view: %v", err)
+		}
+	})
+
+	store := NewUntypedStore(name, logger, constructors, onAfterStore...)
+	store.validate = validate
+	store.recorder = recorder
+	return store
+}
+
+// reject reports that an update of the ConfigMap was rejected.
+func (s *UntypedStore) reject(c *corev1.ConfigMap, err error) {
+	if s.recorder != nil {
+		s.recorder.Eventf(c, corev1.EventTypeWarning, ReasonConfigRejected,
+			"Rejected update of %s config %q, keeping the last good value: %v", s.name, c.Name, err)
+	}
+	ctx, terr := tag.New(context.Background(),
+		tag.Insert(storeNameKey, s.name),
+		tag.Insert(configNameKey, c.Name))
+	if terr != nil {
+		return
+	}
+	stats.Record(ctx, rejectedUpdateCountM.M(1))
+}
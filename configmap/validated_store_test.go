@@ -0,0 +1,125 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmap
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.opencensus.io/stats/view"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	. "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/metrics/metricstest"
+)
+
+func TestValidatedStoreRejectedUpdate(t *testing.T) {
+	constructor := func(c *corev1.ConfigMap) (interface{}, error) {
+		return c.Data["value"], nil
+	}
+	validate := func(name string, value interface{}) error {
+		if value.(string) == "" {
+			return errors.New("value is required")
+		}
+		return nil
+	}
+	recorder := record.NewFakeRecorder(10)
+
+	store := NewValidatedStore("validated", TestLogger(t),
+		Constructors{"config-name-1": constructor},
+		validate, recorder,
+	)
+
+	// Reset the rejections counted by the other tests.
+	view.Unregister(rejectedUpdateCountView)
+	if err := view.Register(rejectedUpdateCountView); err != nil {
+		t.Fatalf("view.Register() = %v", err)
+	}
+
+	store.OnConfigChanged(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "config-name-1",
+			ResourceVersion: "1",
+		},
+		Data: map[string]string{"value": "good"},
+	})
+	store.OnConfigChanged(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "config-name-1",
+			ResourceVersion: "2",
+		},
+	})
+
+	if got, want := store.UntypedLoad("config-name-1"), "good"; got != want {
+		t.Errorf("UntypedLoad() = %v, wanted %v", got, want)
+	}
+	if got, want := store.UntypedVersion("config-name-1"), "1"; got != want {
+		t.Errorf("UntypedVersion() = %q, wanted %q", got, want)
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, ReasonConfigRejected) {
+			t.Errorf("Event = %q, wanted reason %q", event, ReasonConfigRejected)
+		}
+	default:
+		t.Error("No event was recorded for the rejected update")
+	}
+
+	metricstest.CheckCountData(t, "config_rejected_update_count", map[string]string{
+		"store":  "validated",
+		"config": "config-name-1",
+	}, 1)
+}
+
+func TestValidatedStoreAcceptedUpdate(t *testing.T) {
+	constructor := func(c *corev1.ConfigMap) (interface{}, error) {
+		return c.Data["value"], nil
+	}
+	validate := func(name string, value interface{}) error {
+		return nil
+	}
+
+	store := NewValidatedStore("name", TestLogger(t),
+		Constructors{"config-name-1": constructor},
+		validate, nil,
+	)
+
+	if got := store.UntypedVersion("config-name-1"); got != "" {
+		t.Errorf("UntypedVersion() = %q before any update", got)
+	}
+
+	for _, v := range []string{"1", "2"} {
+		store.OnConfigChanged(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "config-name-1",
+				ResourceVersion: v,
+			},
+			Data: map[string]string{"value": v},
+		})
+	}
+
+	if got, want := store.UntypedLoad("config-name-1"), "2"; got != want {
+		t.Errorf("UntypedLoad() = %v, wanted %v", got, want)
+	}
+	if got, want := store.UntypedVersion("config-name-1"), "2"; got != want {
+		t.Errorf("UntypedVersion() = %q, wanted %q", got, want)
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// enqueueKey adds the key to the given lane of the work queue, once the
+// CoalesceWindow has passed if there is one.
+func (c *Impl) enqueueKey(key types.NamespacedName, lane int) {
+	if c.CoalesceWindow <= 0 {
+		c.addKey(key, lane)
+		return
+	}
+	c.delayedMu.Lock()
+	defer c.delayedMu.Unlock()
+	c.enqueueLocked(key, lane)
+}
+
+// enqueueLocked is enqueueKey for callers holding delayedMu.  The enqueues
+// of a key while it waits out the CoalesceWindow are merged into the first
+// one, keeping the highest priority lane among them.  Unlike delayed
+// enqueues, they do not affect the pending delayed enqueue of the key.
+func (c *Impl) enqueueLocked(key types.NamespacedName, lane int) {
+	window := c.CoalesceWindow
+	if window <= 0 {
+		c.addKey(key, lane)
+		return
+	}
+
+	if pending, ok := c.coalesced[key]; ok {
+		if lane < pending.lane {
+			pending.lane = lane
+		}
+		return
+	}
+
+	if c.coalesced == nil {
+		c.coalesced = make(map[types.NamespacedName]*delayedKey)
+	}
+	d := &delayedKey{at: time.Now().Add(window), lane: lane}
+	d.timer = time.AfterFunc(window, func() {
+		c.delayedMu.Lock()
+		defer c.delayedMu.Unlock()
+		// It may have been flushed while we were waiting for the lock.
+		if c.coalesced[key] != d {
+			return
+		}
+		delete(c.coalesced, key)
+		c.addKey(key, d.lane)
+	})
+	c.coalesced[key] = d
+}
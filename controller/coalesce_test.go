@@ -0,0 +1,115 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+)
+
+func TestCoalesceWindow(t *testing.T) {
+	r := &recordingReconciler{}
+	impl := NewImplWithStats(r, TestLogger(t), "Testing", &FakeStatsReporter{})
+	defer impl.WorkQueue.ShutDown()
+	impl.CoalesceWindow = 50 * time.Millisecond
+
+	key := types.NamespacedName{Namespace: "foo", Name: "bar"}
+	for i := 0; i < 5; i++ {
+		impl.EnqueueKey(key)
+	}
+	impl.EnqueueKey(types.NamespacedName{Namespace: "foo", Name: "baz"})
+	if got, want := impl.WorkQueue.Len(), 0; got != want {
+		t.Errorf("|Queue| = %d within the window, wanted %d", got, want)
+	}
+
+	waitFor(t, "the window to pass", func() bool {
+		return impl.WorkQueue.Len() == 2
+	})
+	if got, want := impl.ProcessAll(), 2; got != want {
+		t.Errorf("ProcessAll() = %d, wanted %d", got, want)
+	}
+}
+
+func TestCoalesceWindowKeepsDelayedEnqueue(t *testing.T) {
+	impl := NewImplWithStats(&NopReconciler{}, TestLogger(t), "Testing", &FakeStatsReporter{})
+	defer impl.WorkQueue.ShutDown()
+	impl.CoalesceWindow = time.Hour
+
+	key := types.NamespacedName{Namespace: "foo", Name: "bar"}
+	impl.EnqueueKeyAfter(key, 2*time.Hour)
+	impl.EnqueueKey(key)
+
+	impl.delayedMu.Lock()
+	defer impl.delayedMu.Unlock()
+	if _, ok := impl.delayed[key]; !ok {
+		t.Error("The delayed enqueue was replaced by the coalesced one.")
+	}
+	if _, ok := impl.coalesced[key]; !ok {
+		t.Error("The key is not waiting out the window.")
+	}
+}
+
+func TestCoalesceWindowPromotesLane(t *testing.T) {
+	impl := NewImplWithLanes(&NopReconciler{}, TestLogger(t), "Testing", &FakeStatsReporter{},
+		Lane{}, Lane{})
+	defer impl.WorkQueue.ShutDown()
+	impl.CoalesceWindow = time.Hour
+
+	key := types.NamespacedName{Namespace: "foo", Name: "bar"}
+	impl.EnqueueKeyWithPriority(key, 1)
+	impl.EnqueueKeyWithPriority(key, 0)
+
+	if got, want := impl.FlushDelayed(), 1; got != want {
+		t.Errorf("FlushDelayed() = %d, wanted %d", got, want)
+	}
+	pq := impl.WorkQueue.(*PriorityQueue)
+	if got, want := pq.Len(), 1; got != want {
+		t.Fatalf("|Queue| = %d, wanted %d", got, want)
+	}
+	if got, want := len(pq.queue[0]), 1; got != want {
+		t.Errorf("|Lane 0| = %d, wanted %d", got, want)
+	}
+}
+
+func TestCoalesceWindowFlushedOnShutdown(t *testing.T) {
+	r := &recordingReconciler{}
+	impl := NewImplWithStats(r, TestLogger(t), "Testing", &FakeStatsReporter{})
+	impl.CoalesceWindow = time.Hour
+
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+	go func() {
+		defer close(doneCh)
+		impl.Run(1, stopCh)
+	}()
+	impl.EnqueueKey(types.NamespacedName{Namespace: "foo", Name: "bar"})
+
+	close(stopCh)
+	select {
+	case <-doneCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for controller to finish.")
+	}
+	if got, want := len(r.keys), 1; got != want {
+		t.Errorf("Reconciled %d keys, wanted %d", got, want)
+	}
+}
@@ -22,10 +22,10 @@ import (
 	"sync"
 	"time"
 
-	"github.com/google/uuid"
-
+	"go.opencensus.io/trace"
 	"go.uber.org/zap"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/runtime"
@@ -34,9 +34,11 @@ import (
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 
+	"knative.dev/pkg/apis"
 	"knative.dev/pkg/kmeta"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/logging/logkey"
+	"knative.dev/pkg/reconciler"
 )
 
 const (
@@ -133,6 +135,62 @@ type Impl struct {
 
 	// StatsReporter is used to send common controller metrics.
 	statsReporter StatsReporter
+
+	// name is the name of the work queue, which identifies the
+	// reconciler in traces.
+	name string
+
+	// keys tracks the keys of the controller for its Status.
+	keys keyTracker
+
+	// rateLimiter is the rate limiter of the WorkQueue, when it can be
+	// reconfigured with UpdateRateLimiterFromConfigMap.
+	rateLimiter *dynamicRateLimiter
+
+	// DrainTimeout bounds how long Run waits, once stopped, for the keys
+	// in the work queue and those being reconciled to be processed.  When
+	// it expires, the workers stop taking keys, so those still queued are
+	// dropped, while the reconciles in progress finish in the background.
+	// Zero means Run waits for all of them.
+	DrainTimeout time.Duration
+
+	// IsLeader, when set, tells whether this replica is currently the
+	// leader.  It is passed on to the Reconciler (see reconciler.IsLeader),
+	// so that followers keep serving reads from their informers while
+	// skipping writes.
+	IsLeader func() bool
+
+	// CoalesceWindow, when set, holds the keys enqueued to be processed
+	// now for that long before adding them to the work queue, so that
+	// rapid-fire enqueues of a key are merged into a single reconcile.
+	CoalesceWindow time.Duration
+
+	// ParkingLot configures the parking of keys that keep failing to
+	// reconcile.  By default keys are never parked.
+	ParkingLot ParkingLot
+
+	// failures counts the consecutive failures of keys, and parked holds
+	// the keys that have been parked because of them.
+	parkingMu sync.Mutex
+	failures  map[types.NamespacedName]int
+	parked    map[types.NamespacedName]struct{}
+
+	// delayed holds the pending delayed enqueues, so that they may be
+	// canceled or rescheduled, and coalesced holds the keys waiting out
+	// the CoalesceWindow.
+	delayedMu sync.Mutex
+	delayed   map[types.NamespacedName]*delayedKey
+	coalesced map[types.NamespacedName]*delayedKey
+
+	// watches are the informers watched by the watchdog for progress.
+	watchMu sync.Mutex
+	watches []*informerWatch
+
+	// disabled is set while the controller is disabled (see SetEnabled),
+	// during which the keys it dequeues are held.
+	enabledMu sync.Mutex
+	disabled  bool
+	held      map[types.NamespacedName]struct{}
 }
 
 // NewImpl instantiates an instance of our controller that will feed work to the
@@ -142,14 +200,33 @@ func NewImpl(r Reconciler, logger *zap.SugaredLogger, workQueueName string) *Imp
 }
 
 func NewImplWithStats(r Reconciler, logger *zap.SugaredLogger, workQueueName string, reporter StatsReporter) *Impl {
+	rateLimiter := newDynamicRateLimiter(DefaultRateLimiterConfig())
 	return &Impl{
 		Reconciler: r,
 		WorkQueue: workqueue.NewNamedRateLimitingQueue(
-			workqueue.DefaultControllerRateLimiter(),
+			rateLimiter,
 			workQueueName,
 		),
 		logger:        logger,
 		statsReporter: reporter,
+		name:          workQueueName,
+		rateLimiter:   rateLimiter,
+	}
+}
+
+// NewImplWithLanes is like NewImplWithStats, but feeds the Reconciler
+// from a PriorityQueue with the given lanes.  Keys enqueued through the
+// Enqueue* methods go to lane 0, those enqueued by the GlobalResync
+// methods go to the last lane.
+func NewImplWithLanes(r Reconciler, logger *zap.SugaredLogger, workQueueName string, reporter StatsReporter, lanes ...Lane) *Impl {
+	rateLimiter := newDynamicRateLimiter(DefaultRateLimiterConfig())
+	return &Impl{
+		Reconciler:    r,
+		WorkQueue:     NewPriorityQueue(rateLimiter, lanes...),
+		logger:        logger,
+		statsReporter: reporter,
+		name:          workQueueName,
+		rateLimiter:   rateLimiter,
 	}
 }
 
@@ -191,6 +268,48 @@ func (c *Impl) EnqueueControllerOf(obj interface{}) {
 	}
 }
 
+// OwnerLookup fetches the object of the given kind with the given
+// namespace and name, e.g. from a lister.
+type OwnerLookup func(gk schema.GroupKind, namespace, name string) (metav1.Object, error)
+
+// EnqueueControllerOfType returns an Enqueue func that takes a resource and
+// walks up its chain of controller references, for at most depth levels,
+// looking up the intermediate controllers with lookup, until it finds a
+// controller of the given kind, which it passes to EnqueueKey.  The
+// controllers are looked up in the namespace of the resource.
+func (c *Impl) EnqueueControllerOfType(gk schema.GroupKind, depth int, lookup OwnerLookup) func(obj interface{}) {
+	return func(obj interface{}) {
+		object, err := kmeta.DeletionHandlingAccessor(obj)
+		if err != nil {
+			c.logger.Error(err)
+			return
+		}
+
+		namespace := object.GetNamespace()
+		var current metav1.Object = object
+		for i := 0; i < depth; i++ {
+			owner := metav1.GetControllerOf(current)
+			if owner == nil {
+				return
+			}
+			gv, err := schema.ParseGroupVersion(owner.APIVersion)
+			if err != nil {
+				c.logger.Errorw("Invalid controller reference", zap.Error(err))
+				return
+			}
+			ownerGK := schema.GroupKind{Group: gv.Group, Kind: owner.Kind}
+			if ownerGK == gk {
+				c.EnqueueKey(types.NamespacedName{Namespace: namespace, Name: owner.Name})
+				return
+			}
+			if current, err = lookup(ownerGK, namespace, owner.Name); err != nil {
+				c.logger.Debugf("Unable to look up controller %v %s/%s: %v", ownerGK, namespace, owner.Name, err)
+				return
+			}
+		}
+	}
+}
+
 // EnqueueLabelOfNamespaceScopedResource returns with an Enqueue func that
 // takes a resource, identifies its controller resource through given namespace
 // and name labels, converts it into a namespace/name string, and passes that
@@ -256,41 +375,80 @@ func (c *Impl) EnqueueLabelOfClusterScopedResource(nameLabel string) func(obj in
 
 // EnqueueKey takes a namespace/name string and puts it onto the work queue.
 func (c *Impl) EnqueueKey(key types.NamespacedName) {
-	c.WorkQueue.Add(key)
+	c.enqueueKey(key, 0)
 	c.logger.Debugf("Adding to queue %s (depth: %d)", safeKey(key), c.WorkQueue.Len())
 }
 
 // EnqueueKeyAfter takes a namespace/name string and schedules its execution in
-// the work queue after given delay.
+// the work queue after given delay.  The pending enqueue may be canceled with
+// CancelEnqueueKeyAfter or replaced with RescheduleKeyAfter.
 func (c *Impl) EnqueueKeyAfter(key types.NamespacedName, delay time.Duration) {
-	c.WorkQueue.AddAfter(key, delay)
+	c.scheduleKey(key, delay, 0, false)
 	c.logger.Debugf("Adding to queue %s (delay: %v, depth: %d)", safeKey(key), delay, c.WorkQueue.Len())
 }
 
+// EnqueueKeyWithPriority takes a namespace/name string and puts it onto
+// the given lane of the work queue.  When the work queue is not a
+// PriorityQueue this is the same as EnqueueKey.
+func (c *Impl) EnqueueKeyWithPriority(key types.NamespacedName, lane int) {
+	c.EnqueueKeyAfterWithPriority(key, 0, lane)
+}
+
+// EnqueueKeyAfterWithPriority schedules the namespace/name string onto
+// the given lane of the work queue after given delay.  When the work
+// queue is not a PriorityQueue this is the same as EnqueueKeyAfter.
+func (c *Impl) EnqueueKeyAfterWithPriority(key types.NamespacedName, delay time.Duration, lane int) {
+	if _, ok := c.WorkQueue.(*PriorityQueue); !ok {
+		c.EnqueueKeyAfter(key, delay)
+		return
+	}
+	c.scheduleKey(key, delay, lane, false)
+	c.logger.Debugf("Adding to queue %s (delay: %v, lane: %d, depth: %d)", safeKey(key), delay, lane, c.WorkQueue.Len())
+}
+
 // Run starts the controller's worker threads, the number of which is threadiness.
+// When the work queue is a PriorityQueue, the workers reserved by its lanes
+// are started in addition to these, which take keys from any lane.
 // It then blocks until stopCh is closed, at which point it shuts down its internal
-// work queue and waits for workers to finish processing their current work items.
+// work queue and waits for workers to finish processing their current work items,
+// for at most DrainTimeout when that is set.
 func (c *Impl) Run(threadiness int, stopCh <-chan struct{}) error {
 	defer runtime.HandleCrash()
 	sg := sync.WaitGroup{}
-	defer sg.Wait()
+	// stopWorkers is closed when draining times out.
+	stopWorkers := make(chan struct{})
 	defer func() {
+		c.stopDelayed()
 		c.WorkQueue.ShutDown()
-		for c.WorkQueue.Len() > 0 {
-			time.Sleep(time.Millisecond * 100)
-		}
+		c.drain(&sg, stopWorkers)
 	}()
+	work := func(get func() (interface{}, bool)) {
+		defer sg.Done()
+		for c.processNextWorkItem(get) {
+			select {
+			case <-stopWorkers:
+				return
+			default:
+			}
+		}
+	}
 
 	// Launch workers to process resources that get enqueued to our workqueue.
 	logger := c.logger
 	logger.Info("Starting controller and workers")
 	for i := 0; i < threadiness; i++ {
 		sg.Add(1)
-		go func() {
-			defer sg.Done()
-			for c.processNextWorkItem() {
+		go work(c.WorkQueue.Get)
+	}
+	if pq, ok := c.WorkQueue.(*PriorityQueue); ok {
+		for lane, l := range pq.Lanes() {
+			lane := lane
+			get := func() (interface{}, bool) { return pq.GetFromLane(lane) }
+			for i := 0; i < l.ReservedWorkers; i++ {
+				sg.Add(1)
+				go work(get)
 			}
-		}()
+		}
 	}
 
 	logger.Info("Started workers")
@@ -300,10 +458,40 @@ func (c *Impl) Run(threadiness int, stopCh <-chan struct{}) error {
 	return nil
 }
 
-// processNextWorkItem will read a single work item off the workqueue and
-// attempt to process it, by calling Reconcile on our Reconciler.
-func (c *Impl) processNextWorkItem() bool {
-	obj, shutdown := c.WorkQueue.Get()
+// drain waits for the workers to process the keys left in the shut down
+// work queue and exit, for at most DrainTimeout when that is set, after
+// which it closes stopWorkers so that they take no more keys.
+func (c *Impl) drain(workers *sync.WaitGroup, stopWorkers chan struct{}) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for c.WorkQueue.Len() > 0 {
+			time.Sleep(time.Millisecond * 100)
+		}
+		workers.Wait()
+	}()
+
+	if c.DrainTimeout <= 0 {
+		<-done
+		return
+	}
+	select {
+	case <-done:
+	case <-time.After(c.DrainTimeout):
+		close(stopWorkers)
+		dropped := c.WorkQueue.Len()
+		c.logger.Warnf("Dropping %d queued keys after draining for %v, leaving %d reconciles to finish",
+			dropped, c.DrainTimeout, c.keys.inFlightCount())
+		if r, ok := c.statsReporter.(DrainReporter); ok {
+			r.ReportDroppedKeys(int64(dropped))
+		}
+	}
+}
+
+// processNextWorkItem will read a single work item off the workqueue with
+// get and attempt to process it, by calling Reconcile on our Reconciler.
+func (c *Impl) processNextWorkItem(get func() (interface{}, bool)) bool {
+	obj, shutdown := get()
 	if shutdown {
 		return false
 	}
@@ -311,6 +499,7 @@ func (c *Impl) processNextWorkItem() bool {
 	keyStr := safeKey(key)
 
 	c.logger.Debugf("Processing from queue %s (depth: %d)", safeKey(key), c.WorkQueue.Len())
+	c.keys.started(key)
 
 	startTime := time.Now()
 	// Send the metrics for the current queue depth
@@ -323,23 +512,75 @@ func (c *Impl) processNextWorkItem() bool {
 	// delay.
 	defer c.WorkQueue.Done(key)
 
+	// Trace each reconcile, passing the span's context on to the Reconciler
+	// so that the calls it makes are part of the trace.
+	ctx, span := trace.StartSpan(context.TODO(), "reconcile/"+c.name)
+	defer span.End()
+	span.AddAttributes(
+		trace.StringAttribute("reconciler", c.name),
+		trace.StringAttribute("key", keyStr),
+		trace.Int64Attribute("attempt", int64(c.WorkQueue.NumRequeues(key)+1)))
+
+	// Let the Reconciler say why it reconciled the way it did.
+	ctx, reason := withReason(ctx)
+
 	var err error
 	defer func() {
 		status := trueString
+		outcome := OutcomeSuccess
 		if err != nil {
 			status = falseString
+			outcome = OutcomeError
+			if IsPermanentError(err) {
+				outcome = OutcomePermanentError
+			}
+			span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: err.Error()})
+		}
+		if *reason == "" {
+			*reason = outcome
 		}
+		span.AddAttributes(
+			trace.StringAttribute("outcome", outcome),
+			trace.StringAttribute("reason", *reason))
 		c.statsReporter.ReportReconcile(time.Since(startTime), keyStr, status)
+		if or, ok := c.statsReporter.(OutcomeReporter); ok {
+			or.ReportReconcileOutcome(outcome, *reason)
+		}
 	}()
 
-	// Embed the key into the logger and attach that to the context we pass
-	// to the Reconciler.
-	logger := c.logger.With(zap.String(logkey.TraceId, uuid.New().String()), zap.String(logkey.Key, keyStr))
-	ctx := logging.WithLogger(context.TODO(), logger)
+	// Embed the key and the trace into the logger and attach that to the
+	// context we pass to the Reconciler.
+	ctx = logging.WithTrace(logging.WithLogger(ctx, c.logger.With(zap.String(logkey.Key, keyStr))))
+	logger := logging.FromContext(ctx)
+	if c.IsLeader != nil {
+		ctx = reconciler.WithLeadership(ctx, c.IsLeader)
+	}
+
+	// Disabled controllers hold the key until they are enabled again.
+	if c.hold(key) {
+		*reason = ReasonDisabled
+		c.WorkQueue.Forget(key)
+		c.keys.finished(key, false)
+		logger.Debug("Controller disabled, holding the key.")
+		return true
+	}
 
 	// Run Reconcile, passing it the namespace/name string of the
 	// resource to be synced.
 	if err = c.Reconciler.Reconcile(ctx, keyStr); err != nil {
+		// Requeues are asked for by the Reconciler, they are not failures.
+		if ok, delay := reconciler.IsRequeueKey(err); ok {
+			err = nil
+			if *reason == "" {
+				*reason = ReasonRequeue
+			}
+			c.WorkQueue.Forget(key)
+			c.unpark(key)
+			c.keys.finished(key, false)
+			c.EnqueueKeyAfter(key, delay)
+			logger.Infof("Reconcile requeued (delay: %v). Time taken: %v.", delay, time.Since(startTime))
+			return true
+		}
 		c.handleErr(err, key)
 		logger.Infof("Reconcile failed. Time taken: %v.", time.Since(startTime))
 		return true
@@ -348,6 +589,8 @@ func (c *Impl) processNextWorkItem() bool {
 	// Finally, if no error occurs we Forget this item so it does not
 	// have any delay when another change happens.
 	c.WorkQueue.Forget(key)
+	c.unpark(key)
+	c.keys.finished(key, false)
 	logger.Infof("Reconcile succeeded. Time taken: %v.", time.Since(startTime))
 
 	return true
@@ -361,12 +604,21 @@ func (c *Impl) handleErr(err error, key types.NamespacedName) {
 	// since controller Run might have exited by now (since while this item was
 	// being processed, queue.Len==0).
 	if !IsPermanentError(err) && !c.WorkQueue.ShuttingDown() {
+		// Keys that keep failing are parked rather than rate limited.
+		if c.fail(key) {
+			c.park(key)
+			c.keys.finished(key, true)
+			return
+		}
 		c.WorkQueue.AddRateLimited(key)
+		c.keys.finished(key, true)
 		c.logger.Debugf("Requeuing key %s due to non-permanent error (depth: %d)", safeKey(key), c.WorkQueue.Len())
 		return
 	}
 
+	c.unpark(key)
 	c.WorkQueue.Forget(key)
+	c.keys.finished(key, false)
 }
 
 // GlobalResync enqueues (with a delay) all objects from the passed SharedInformer
@@ -375,21 +627,63 @@ func (c *Impl) GlobalResync(si cache.SharedInformer) {
 	c.FilteredGlobalResync(alwaysTrue, si)
 }
 
+// GlobalResyncWithSelector enqueues (with a delay) the objects from the
+// passed SharedInformer whose labels match the selector.
+func (c *Impl) GlobalResyncWithSelector(si cache.SharedInformer, selector labels.Selector) {
+	c.FilteredGlobalResync(func(obj interface{}) bool {
+		object, err := kmeta.DeletionHandlingAccessor(obj)
+		if err != nil {
+			return false
+		}
+		return selector.Matches(labels.Set(object.GetLabels()))
+	}, si)
+}
+
+// GlobalResyncInNamespace enqueues (with a delay) the objects from the
+// passed SharedInformer that live in the given namespace.
+func (c *Impl) GlobalResyncInNamespace(si cache.SharedInformer, namespace string) {
+	c.FilteredGlobalResync(func(obj interface{}) bool {
+		object, err := kmeta.DeletionHandlingAccessor(obj)
+		if err != nil {
+			return false
+		}
+		return object.GetNamespace() == namespace
+	}, si)
+}
+
 // FilteredGlobalResync enqueues (with a delay) all objects from the
-// SharedInformer that pass the filter function
+// SharedInformer that pass the filter function.  When the work queue is
+// a PriorityQueue, they are enqueued onto its lowest priority lane.
 func (c *Impl) FilteredGlobalResync(f func(interface{}) bool, si cache.SharedInformer) {
 	if c.WorkQueue.ShuttingDown() {
 		return
 	}
+	lane := c.lowestLane()
 	list := si.GetStore().List()
 	count := float64(len(list))
 	for _, obj := range list {
-		if f(obj) {
-			c.EnqueueAfter(obj, wait.Jitter(time.Second, count))
+		if !f(obj) {
+			continue
+		}
+		object, err := kmeta.DeletionHandlingAccessor(obj)
+		if err != nil {
+			c.logger.Errorw("Enqueue", zap.Error(err))
+			continue
 		}
+		c.EnqueueKeyAfterWithPriority(
+			types.NamespacedName{Namespace: object.GetNamespace(), Name: object.GetName()},
+			wait.Jitter(time.Second, count), lane)
 	}
 }
 
+// lowestLane returns the lowest priority lane of the work queue.
+func (c *Impl) lowestLane() int {
+	if pq, ok := c.WorkQueue.(*PriorityQueue); ok {
+		return len(pq.Lanes()) - 1
+	}
+	return 0
+}
+
 // NewPermanentError returns a new instance of permanentError.
 // Users can wrap an error as permanentError with this in reconcile,
 // when he does not expect the key to get re-queued.
@@ -401,6 +695,31 @@ func NewPermanentError(err error) error {
 // We should not re-queue keys when it returns with thus error in reconcile.
 type permanentError struct {
 	e error
+
+	// condition and reason, when set, are those of the condition to mark
+	// False on the resource, with the error as its message.
+	condition apis.ConditionType
+	reason    string
+}
+
+// NewPermanentErrorWithCondition is like NewPermanentError, but also carries
+// the condition to mark False, with the given reason and the error as its
+// message, on the resource that will not be reconciled further.  Reconcilers
+// apply it with MarkPermanentError before updating the resource's status, so
+// that users are told about unrecoverable misconfigurations.
+func NewPermanentErrorWithCondition(err error, condition apis.ConditionType, reason string) error {
+	return permanentError{e: err, condition: condition, reason: reason}
+}
+
+// MarkPermanentError marks the condition carried by the permanent error, if
+// any, False with the ConditionManager, and returns whether it did.
+func MarkPermanentError(err error, cm apis.ConditionManager) bool {
+	pe, ok := err.(permanentError)
+	if !ok || pe.condition == "" {
+		return false
+	}
+	cm.MarkFalse(pe.condition, pe.reason, "%s", pe.Error())
+	return true
 }
 
 // IsPermanentError returns true if given error is permanentError
@@ -24,15 +24,22 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.opencensus.io/trace"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 
+	"knative.dev/pkg/apis"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
 	. "knative.dev/pkg/controller/testing"
 	. "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/reconciler"
 	. "knative.dev/pkg/testing"
 )
 
@@ -499,6 +506,93 @@ func TestEnqueues(t *testing.T) {
 	}
 }
 
+func TestEnqueueControllerOfType(t *testing.T) {
+	owned := func(name, apiVersion, kind, owner string) *Resource {
+		return &Resource{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "ns",
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: apiVersion,
+					Kind:       kind,
+					Name:       owner,
+					Controller: &boolTrue,
+				}},
+			},
+		}
+	}
+	objects := map[string]metav1.Object{
+		"ReplicaSet/rs": owned("rs", "apps/v1", "Deployment", "deploy"),
+		"Deployment/deploy": &Resource{
+			ObjectMeta: metav1.ObjectMeta{Name: "deploy", Namespace: "ns"},
+		},
+	}
+	lookup := func(gk schema.GroupKind, namespace, name string) (metav1.Object, error) {
+		if object, ok := objects[gk.Kind+"/"+name]; ok && namespace == "ns" {
+			return object, nil
+		}
+		return nil, errors.New("not found")
+	}
+	deployments := schema.GroupKind{Group: "apps", Kind: "Deployment"}
+
+	tests := []struct {
+		name      string
+		gk        schema.GroupKind
+		depth     int
+		obj       interface{}
+		wantQueue []types.NamespacedName
+	}{{
+		name:      "two levels up",
+		gk:        deployments,
+		depth:     2,
+		obj:       owned("pod", "apps/v1", "ReplicaSet", "rs"),
+		wantQueue: []types.NamespacedName{{Namespace: "ns", Name: "deploy"}},
+	}, {
+		name:  "not deep enough",
+		gk:    deployments,
+		depth: 1,
+		obj:   owned("pod", "apps/v1", "ReplicaSet", "rs"),
+	}, {
+		name:      "direct controller",
+		gk:        deployments,
+		depth:     1,
+		obj:       owned("rs", "apps/v1", "Deployment", "deploy"),
+		wantQueue: []types.NamespacedName{{Namespace: "ns", Name: "deploy"}},
+	}, {
+		name:      "any version",
+		gk:        deployments,
+		depth:     1,
+		obj:       owned("rs", "apps/v1beta2", "Deployment", "deploy"),
+		wantQueue: []types.NamespacedName{{Namespace: "ns", Name: "deploy"}},
+	}, {
+		name:  "missing intermediate controller",
+		gk:    deployments,
+		depth: 3,
+		obj:   owned("pod", "apps/v1", "ReplicaSet", "gone"),
+	}, {
+		name:  "chain ends",
+		gk:    schema.GroupKind{Group: "apps", Kind: "StatefulSet"},
+		depth: 5,
+		obj:   owned("pod", "apps/v1", "ReplicaSet", "rs"),
+	}, {
+		name:  "bad resource",
+		gk:    deployments,
+		depth: 2,
+		obj:   "baz/blah",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			impl := NewImplWithStats(&NopReconciler{}, TestLogger(t), "Testing", &FakeStatsReporter{})
+			impl.EnqueueControllerOfType(test.gk, test.depth, lookup)(test.obj)
+			impl.WorkQueue.ShutDown()
+			if diff := cmp.Diff(test.wantQueue, drainWorkQueue(impl.WorkQueue)); diff != "" {
+				t.Errorf("unexpected queue (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
 func TestEnqeueAfter(t *testing.T) {
 	defer ClearAll()
 	impl := NewImplWithStats(&NopReconciler{}, TestLogger(t), "Testing", &FakeStatsReporter{})
@@ -556,6 +650,86 @@ func TestEnqeueKeyAfter(t *testing.T) {
 	}
 }
 
+func TestCancelEnqueueKeyAfter(t *testing.T) {
+	defer ClearAll()
+	impl := NewImplWithStats(&NopReconciler{}, TestLogger(t), "Testing", &FakeStatsReporter{})
+	impl.EnqueueKeyAfter(types.NamespacedName{Namespace: "waiting", Name: "for"}, 100*time.Millisecond)
+	impl.EnqueueAfter(&Resource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "waterfall",
+			Namespace: "the",
+		},
+	}, 100*time.Millisecond)
+
+	if !impl.CancelEnqueueKeyAfter(types.NamespacedName{Namespace: "waiting", Name: "for"}) {
+		t.Error("CancelEnqueueKeyAfter() = false, wanted true")
+	}
+	if impl.CancelEnqueueKeyAfter(types.NamespacedName{Namespace: "waiting", Name: "for"}) {
+		t.Error("CancelEnqueueKeyAfter() = true for a canceled key, wanted false")
+	}
+	if impl.CancelEnqueueKeyAfter(types.NamespacedName{Namespace: "never", Name: "enqueued"}) {
+		t.Error("CancelEnqueueKeyAfter() = true for an unknown key, wanted false")
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	impl.WorkQueue.ShutDown()
+	if got, want := drainWorkQueue(impl.WorkQueue), []types.NamespacedName{{Namespace: "the", Name: "waterfall"}}; !cmp.Equal(got, want) {
+		t.Errorf("Queue = %v, want: %v, diff: %s", got, want, cmp.Diff(got, want))
+	}
+}
+
+func TestRescheduleKeyAfter(t *testing.T) {
+	defer ClearAll()
+	key := types.NamespacedName{Namespace: "the", Name: "waterfall"}
+
+	tests := []struct {
+		name    string
+		enqueue func(impl *Impl)
+		wantLen int
+	}{{
+		name: "sooner enqueue wins",
+		enqueue: func(impl *Impl) {
+			impl.EnqueueKeyAfter(key, 20*time.Second)
+			impl.EnqueueKeyAfter(key, 100*time.Millisecond)
+		},
+		wantLen: 1,
+	}, {
+		name: "later enqueue is dropped",
+		enqueue: func(impl *Impl) {
+			impl.EnqueueKeyAfter(key, 100*time.Millisecond)
+			impl.EnqueueKeyAfter(key, 20*time.Second)
+		},
+		wantLen: 1,
+	}, {
+		name: "reschedule postpones",
+		enqueue: func(impl *Impl) {
+			impl.EnqueueKeyAfter(key, 100*time.Millisecond)
+			impl.RescheduleKeyAfter(key, 20*time.Second)
+		},
+		wantLen: 0,
+	}, {
+		name: "reschedule brings forward",
+		enqueue: func(impl *Impl) {
+			impl.EnqueueKeyAfter(key, 20*time.Second)
+			impl.RescheduleKeyAfter(key, 0)
+		},
+		wantLen: 1,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			impl := NewImplWithStats(&NopReconciler{}, TestLogger(t), "Testing", &FakeStatsReporter{})
+			test.enqueue(impl)
+			time.Sleep(300 * time.Millisecond)
+			if got, want := impl.WorkQueue.Len(), test.wantLen; got != want {
+				t.Errorf("|Queue| = %d, want: %d", got, want)
+			}
+			impl.stopDelayed()
+			impl.WorkQueue.ShutDown()
+		})
+	}
+}
+
 type CountingReconciler struct {
 	m     sync.Mutex
 	Count int
@@ -696,6 +870,89 @@ func TestStartAndShutdownWithErroringWork(t *testing.T) {
 	}
 }
 
+// spanRecorder is a trace.Exporter that remembers the exported spans.
+type spanRecorder struct {
+	spans []*trace.SpanData
+}
+
+func (sr *spanRecorder) ExportSpan(s *trace.SpanData) {
+	sr.spans = append(sr.spans, s)
+}
+
+// spanReconciler returns err, remembering the span it was called with.
+type spanReconciler struct {
+	err  error
+	span *trace.Span
+}
+
+func (sr *spanReconciler) Reconcile(ctx context.Context, key string) error {
+	sr.span = trace.FromContext(ctx)
+	return sr.err
+}
+
+func TestReconcileTracing(t *testing.T) {
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.AlwaysSample()})
+	defer trace.ApplyConfig(trace.Config{DefaultSampler: trace.ProbabilitySampler(1e-4)})
+
+	tests := []struct {
+		name        string
+		err         error
+		wantOutcome string
+		wantCode    int32
+	}{{
+		name:        "success",
+		wantOutcome: "success",
+	}, {
+		name:        "error",
+		err:         errors.New("I always error"),
+		wantOutcome: "error",
+		wantCode:    trace.StatusCodeUnknown,
+	}, {
+		name:        "permanent error",
+		err:         NewPermanentError(errors.New("I always error")),
+		wantOutcome: "permanent_error",
+		wantCode:    trace.StatusCodeUnknown,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sr := &spanRecorder{}
+			trace.RegisterExporter(sr)
+			defer trace.UnregisterExporter(sr)
+
+			r := &spanReconciler{err: test.err}
+			impl := NewImplWithStats(r, TestLogger(t), "Testing", &FakeStatsReporter{})
+			defer impl.WorkQueue.ShutDown()
+			impl.EnqueueKey(types.NamespacedName{Namespace: "foo", Name: "bar"})
+			impl.processNextWorkItem(impl.WorkQueue.Get)
+
+			if len(sr.spans) != 1 {
+				t.Fatalf("Got %d spans, wanted 1", len(sr.spans))
+			}
+			span := sr.spans[0]
+			if got, want := span.Name, "reconcile/Testing"; got != want {
+				t.Errorf("Span name = %q, wanted %q", got, want)
+			}
+			if r.span == nil || r.span.SpanContext() != span.SpanContext {
+				t.Error("Reconcile was not passed the reconcile span.")
+			}
+			wantAttrs := map[string]interface{}{
+				"reconciler": "Testing",
+				"key":        "foo/bar",
+				"attempt":    int64(1),
+				"outcome":    test.wantOutcome,
+				"reason":     test.wantOutcome,
+			}
+			if diff := cmp.Diff(wantAttrs, span.Attributes); diff != "" {
+				t.Errorf("Span attributes (-want, +got) = %s", diff)
+			}
+			if got, want := span.Status.Code, test.wantCode; got != want {
+				t.Errorf("Span status = %v, wanted %v", got, want)
+			}
+		})
+	}
+}
+
 type PermanentErrorReconciler struct{}
 
 func (er *PermanentErrorReconciler) Reconcile(context.Context, string) error {
@@ -771,6 +1028,7 @@ var dummyObjs = []interface{}{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "bar",
 			Namespace: "foo",
+			Labels:    map[string]string{"resync": "please"},
 		},
 	},
 	&Resource{
@@ -832,6 +1090,64 @@ func TestImplGlobalResync(t *testing.T) {
 	}
 }
 
+func TestImplGlobalResyncFiltered(t *testing.T) {
+	tests := []struct {
+		name   string
+		resync func(*Impl, cache.SharedInformer)
+		want   []types.NamespacedName
+	}{{
+		name: "matching selector",
+		resync: func(impl *Impl, si cache.SharedInformer) {
+			impl.GlobalResyncWithSelector(si, labels.SelectorFromSet(labels.Set{"resync": "please"}))
+		},
+		want: []types.NamespacedName{{Namespace: "foo", Name: "bar"}},
+	}, {
+		name: "everything selector",
+		resync: func(impl *Impl, si cache.SharedInformer) {
+			impl.GlobalResyncWithSelector(si, labels.Everything())
+		},
+		want: []types.NamespacedName{
+			{Namespace: "bar", Name: "foo"},
+			{Namespace: "fizz", Name: "buzz"},
+			{Namespace: "foo", Name: "bar"},
+		},
+	}, {
+		name: "nothing selector",
+		resync: func(impl *Impl, si cache.SharedInformer) {
+			impl.GlobalResyncWithSelector(si, labels.Nothing())
+		},
+	}, {
+		name: "namespace",
+		resync: func(impl *Impl, si cache.SharedInformer) {
+			impl.GlobalResyncInNamespace(si, "fizz")
+		},
+		want: []types.NamespacedName{{Namespace: "fizz", Name: "buzz"}},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			impl := NewImplWithStats(&NopReconciler{}, TestLogger(t), "Testing", &FakeStatsReporter{})
+			defer impl.stopDelayed()
+			test.resync(impl, &dummyInformer{})
+
+			// The resync delays its enqueues, so look at the pending ones.
+			impl.delayedMu.Lock()
+			var got []types.NamespacedName
+			for key := range impl.delayed {
+				got = append(got, key)
+			}
+			impl.delayedMu.Unlock()
+
+			sortKeys := cmpopts.SortSlices(func(a, b types.NamespacedName) bool {
+				return a.String() < b.String()
+			})
+			if diff := cmp.Diff(test.want, got, sortKeys, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Resynced keys (-want, +got) = %s", diff)
+			}
+		})
+	}
+}
+
 func checkStats(t *testing.T, r *FakeStatsReporter, reportCount, lastQueueDepth, reconcileCount int, lastReconcileSuccess string) {
 	qd := r.GetQueueDepths()
 	if got, want := len(qd), reportCount; got != want {
@@ -987,3 +1303,122 @@ func TestGetEventRecorder(t *testing.T) {
 		t.Error("GetEventRecorder() = nil, wanted non-nil")
 	}
 }
+
+func TestReconcileRequeue(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantQueue int
+		wantDelay bool
+	}{{
+		name:      "immediately",
+		err:       reconciler.NewRequeueImmediately(),
+		wantQueue: 1,
+	}, {
+		name:      "after",
+		err:       reconciler.NewRequeueAfter(time.Hour),
+		wantDelay: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reporter := &FakeStatsReporter{}
+			impl := NewImplWithStats(&reasonReconciler{err: test.err}, TestLogger(t), "Testing", reporter)
+			defer impl.WorkQueue.ShutDown()
+			key := types.NamespacedName{Namespace: "foo", Name: "bar"}
+			impl.EnqueueKey(key)
+			impl.processNextWorkItem(impl.WorkQueue.Get)
+
+			if got := impl.WorkQueue.Len(); got != test.wantQueue {
+				t.Errorf("|Queue| = %d, wanted %d", got, test.wantQueue)
+			}
+			impl.delayedMu.Lock()
+			_, delayed := impl.delayed[key]
+			impl.delayedMu.Unlock()
+			if delayed != test.wantDelay {
+				t.Errorf("Delayed = %v, wanted %v", delayed, test.wantDelay)
+			}
+			if got := impl.WorkQueue.NumRequeues(key); got != 0 {
+				t.Errorf("NumRequeues() = %d, wanted 0", got)
+			}
+			if got, want := reporter.GetReconcileData()[0].Success, trueString; got != want {
+				t.Errorf("Reported success = %q, wanted %q", got, want)
+			}
+		})
+	}
+}
+
+func TestMarkPermanentError(t *testing.T) {
+	const ready apis.ConditionType = "Ready"
+	condSet := apis.NewLivingConditionSet()
+
+	tests := []struct {
+		name     string
+		err      error
+		wantMark bool
+		want     *apis.Condition
+	}{{
+		name: "not permanent",
+		err:  errors.New("transient"),
+	}, {
+		name: "permanent without condition",
+		err:  NewPermanentError(errors.New("permanent")),
+	}, {
+		name:     "permanent with condition",
+		err:      NewPermanentErrorWithCondition(errors.New("spec.image is not a valid reference"), ready, "InvalidImage"),
+		wantMark: true,
+		want: &apis.Condition{
+			Type:    ready,
+			Status:  corev1.ConditionFalse,
+			Reason:  "InvalidImage",
+			Message: "spec.image is not a valid reference",
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status := &duckv1beta1.Status{}
+			cm := condSet.Manage(status)
+			cm.InitializeConditions()
+
+			if got := MarkPermanentError(test.err, cm); got != test.wantMark {
+				t.Errorf("MarkPermanentError() = %v, wanted %v", got, test.wantMark)
+			}
+			if !test.wantMark {
+				return
+			}
+			if !IsPermanentError(test.err) {
+				t.Error("IsPermanentError() = false, wanted true")
+			}
+			got := cm.GetCondition(ready)
+			if diff := cmp.Diff(test.want, got, cmpopts.IgnoreFields(apis.Condition{}, "LastTransitionTime", "Severity")); diff != "" {
+				t.Errorf("Condition (-want, +got) = %s", diff)
+			}
+		})
+	}
+}
+
+// leaderReconciler remembers whether it was told it is the leader.
+type leaderReconciler struct {
+	leader bool
+}
+
+func (lr *leaderReconciler) Reconcile(ctx context.Context, key string) error {
+	lr.leader = reconciler.IsLeader(ctx)
+	return nil
+}
+
+func TestImplIsLeader(t *testing.T) {
+	for _, leader := range []bool{true, false} {
+		r := &leaderReconciler{leader: !leader}
+		impl := NewImplWithStats(r, TestLogger(t), "Testing", &FakeStatsReporter{})
+		impl.IsLeader = func() bool { return leader }
+		impl.EnqueueKey(types.NamespacedName{Namespace: "foo", Name: "bar"})
+		impl.ProcessAll()
+		impl.WorkQueue.ShutDown()
+
+		if r.leader != leader {
+			t.Errorf("reconciler.IsLeader() = %v, wanted %v", r.leader, leader)
+		}
+	}
+}
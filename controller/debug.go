@@ -0,0 +1,182 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DebugPath is the path at which DebugHandler is conventionally mounted
+// on the profiling server, e.g.
+//
+//	profilingHandler.Handle(controller.DebugPath, controller.DebugHandler(controllers...))
+const DebugPath = "/debug/controllers"
+
+// QueueStatus is a snapshot of the work of a controller, as served by
+// DebugHandler.
+type QueueStatus struct {
+	// Name is the name of the controller's work queue.
+	Name string `json:"name"`
+
+	// Depth is the number of keys waiting in the work queue.
+	Depth int `json:"depth"`
+
+	// OldestKeyAge is how long the oldest key enqueued by the
+	// controller has been waiting, if any is.
+	OldestKeyAge string `json:"oldestKeyAge,omitempty"`
+
+	// InFlight are the keys being reconciled.
+	InFlight []KeyStatus `json:"inFlight,omitempty"`
+
+	// Retrying are the keys waiting to be retried after failing.
+	Retrying []KeyStatus `json:"retrying,omitempty"`
+}
+
+// KeyStatus describes a single key of a QueueStatus.
+type KeyStatus struct {
+	Key string `json:"key"`
+
+	// Age is how long the key has been reconciling, for in-flight keys.
+	Age string `json:"age,omitempty"`
+
+	// Retries is the number of times the key has been requeued after
+	// failing since it last reconciled successfully.
+	Retries int `json:"retries"`
+}
+
+// keyTracker keeps track of where the keys of a controller are, for
+// debugging.  Keys requeued by the work queue itself (e.g. when rate
+// limiting) are only known about once they are handed out.
+type keyTracker struct {
+	m        sync.Mutex
+	queued   map[types.NamespacedName]time.Time
+	inFlight map[types.NamespacedName]time.Time
+	retrying map[types.NamespacedName]struct{}
+}
+
+func (kt *keyTracker) init() {
+	if kt.queued == nil {
+		kt.queued = make(map[types.NamespacedName]time.Time)
+		kt.inFlight = make(map[types.NamespacedName]time.Time)
+		kt.retrying = make(map[types.NamespacedName]struct{})
+	}
+}
+
+// enqueued records that the key was added to the work queue.
+func (kt *keyTracker) enqueued(key types.NamespacedName) {
+	kt.m.Lock()
+	defer kt.m.Unlock()
+	kt.init()
+	if _, ok := kt.queued[key]; !ok {
+		kt.queued[key] = time.Now()
+	}
+}
+
+// started records that the key was taken off the work queue.
+func (kt *keyTracker) started(key types.NamespacedName) {
+	kt.m.Lock()
+	defer kt.m.Unlock()
+	kt.init()
+	delete(kt.queued, key)
+	kt.inFlight[key] = time.Now()
+}
+
+// finished records that the reconcile of the key is over, and whether
+// it is going to be retried.
+func (kt *keyTracker) finished(key types.NamespacedName, retrying bool) {
+	kt.m.Lock()
+	defer kt.m.Unlock()
+	kt.init()
+	delete(kt.inFlight, key)
+	if retrying {
+		kt.retrying[key] = struct{}{}
+	} else {
+		delete(kt.retrying, key)
+	}
+}
+
+// inFlightCount returns the number of keys being reconciled.
+func (kt *keyTracker) inFlightCount() int {
+	kt.m.Lock()
+	defer kt.m.Unlock()
+	return len(kt.inFlight)
+}
+
+// Status returns a snapshot of the work of the controller.
+func (c *Impl) Status() QueueStatus {
+	c.keys.m.Lock()
+	defer c.keys.m.Unlock()
+
+	now := time.Now()
+	status := QueueStatus{
+		Name:  c.name,
+		Depth: c.WorkQueue.Len(),
+	}
+	var oldest time.Time
+	for _, at := range c.keys.queued {
+		if oldest.IsZero() || at.Before(oldest) {
+			oldest = at
+		}
+	}
+	if !oldest.IsZero() {
+		status.OldestKeyAge = now.Sub(oldest).String()
+	}
+	for key, at := range c.keys.inFlight {
+		status.InFlight = append(status.InFlight, KeyStatus{
+			Key:     safeKey(key),
+			Age:     now.Sub(at).String(),
+			Retries: c.WorkQueue.NumRequeues(key),
+		})
+	}
+	for key := range c.keys.retrying {
+		status.Retrying = append(status.Retrying, KeyStatus{
+			Key:     safeKey(key),
+			Retries: c.WorkQueue.NumRequeues(key),
+		})
+	}
+	sortKeyStatuses(status.InFlight)
+	sortKeyStatuses(status.Retrying)
+	return status
+}
+
+func sortKeyStatuses(keys []KeyStatus) {
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Key < keys[j].Key
+	})
+}
+
+// DebugHandler returns an http.Handler that serves the Status of the
+// given controllers as JSON.  It is meant to be mounted on the profiling
+// server, at DebugPath.
+func DebugHandler(controllers ...*Impl) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		statuses := make([]QueueStatus, 0, len(controllers))
+		for _, c := range controllers {
+			statuses = append(statuses, c.Status())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(statuses); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"k8s.io/apimachinery/pkg/types"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+)
+
+func TestDebugHandler(t *testing.T) {
+	r := &blockingReconciler{
+		started: make(chan string, 10),
+		release: make(chan struct{}),
+	}
+	impl := NewImplWithStats(r, TestLogger(t), "Testing", &FakeStatsReporter{})
+	idle := NewImplWithStats(&NopReconciler{}, TestLogger(t), "Idle", &FakeStatsReporter{})
+	defer impl.WorkQueue.ShutDown()
+
+	// One key being reconciled, one waiting behind it and one waiting to
+	// be retried.
+	impl.EnqueueKey(types.NamespacedName{Name: "slow"})
+	go impl.processNextWorkItem(impl.WorkQueue.Get)
+	<-r.started
+	defer close(r.release)
+
+	impl.EnqueueKey(types.NamespacedName{Namespace: "the", Name: "waterfall"})
+	failing := types.NamespacedName{Namespace: "foo", Name: "bar"}
+	impl.keys.started(failing)
+	impl.handleErr(errors.New("I always error"), failing)
+	time.Sleep(10 * time.Millisecond)
+
+	rr := httptest.NewRecorder()
+	DebugHandler(impl, idle).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, DebugPath, nil))
+	if got, want := rr.Code, http.StatusOK; got != want {
+		t.Fatalf("StatusCode = %d, wanted %d", got, want)
+	}
+
+	var got []QueueStatus
+	if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+		t.Fatalf("Error decoding status: %v", err)
+	}
+	if got[0].OldestKeyAge == "" {
+		t.Error("OldestKeyAge is empty, wanted the age of the/waterfall")
+	}
+	if got[0].InFlight[0].Age == "" {
+		t.Error("InFlight[0].Age is empty, wanted the age of slow")
+	}
+
+	want := []QueueStatus{{
+		Name:  "Testing",
+		Depth: 2,
+		InFlight: []KeyStatus{{
+			Key: "slow",
+		}},
+		Retrying: []KeyStatus{{
+			Key:     "foo/bar",
+			Retries: 1,
+		}},
+	}, {
+		Name: "Idle",
+	}}
+	ignoreAges := cmpopts.IgnoreFields(QueueStatus{}, "OldestKeyAge")
+	ignoreKeyAges := cmpopts.IgnoreFields(KeyStatus{}, "Age")
+	if diff := cmp.Diff(want, got, ignoreAges, ignoreKeyAges); diff != "" {
+		t.Errorf("Status (-want, +got) = %s", diff)
+	}
+}
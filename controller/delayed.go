@@ -0,0 +1,138 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/types"
+
+	"knative.dev/pkg/kmeta"
+)
+
+// delayedKey is a pending delayed enqueue of a key.
+type delayedKey struct {
+	timer *time.Timer
+	at    time.Time
+	lane  int
+}
+
+// CancelEnqueueAfter takes a resource, converts it into a namespace/name
+// string, and passes it to CancelEnqueueKeyAfter.
+func (c *Impl) CancelEnqueueAfter(obj interface{}) {
+	object, err := kmeta.DeletionHandlingAccessor(obj)
+	if err != nil {
+		c.logger.Errorw("CancelEnqueueAfter", zap.Error(err))
+		return
+	}
+	c.CancelEnqueueKeyAfter(types.NamespacedName{Namespace: object.GetNamespace(), Name: object.GetName()})
+}
+
+// CancelEnqueueKeyAfter cancels the pending delayed enqueue of the
+// namespace/name string, if any, returning whether there was one.  Keys
+// already in the work queue are not affected.
+func (c *Impl) CancelEnqueueKeyAfter(key types.NamespacedName) bool {
+	c.delayedMu.Lock()
+	defer c.delayedMu.Unlock()
+	d, ok := c.delayed[key]
+	if !ok {
+		return false
+	}
+	d.timer.Stop()
+	delete(c.delayed, key)
+	c.logger.Debugf("Canceled delayed enqueue of %s", safeKey(key))
+	return true
+}
+
+// RescheduleKeyAfter is like EnqueueKeyAfter, except that it replaces any
+// pending delayed enqueue of the namespace/name string, even one that is
+// due sooner, keeping the lane that enqueue was for.
+func (c *Impl) RescheduleKeyAfter(key types.NamespacedName, delay time.Duration) {
+	c.scheduleKey(key, delay, 0, true)
+	c.logger.Debugf("Rescheduled %s (delay: %v)", safeKey(key), delay)
+}
+
+// scheduleKey adds the key to the given lane of the work queue once the
+// delay has passed.  As with the client-go delaying queue, only the
+// soonest of the pending delayed enqueues of a key is kept, unless
+// reschedule is set.
+func (c *Impl) scheduleKey(key types.NamespacedName, delay time.Duration, lane int, reschedule bool) {
+	c.delayedMu.Lock()
+	defer c.delayedMu.Unlock()
+
+	at := time.Now().Add(delay)
+	if pending, ok := c.delayed[key]; ok {
+		if !reschedule && !at.Before(pending.at) {
+			return
+		}
+		if reschedule {
+			lane = pending.lane
+		}
+		pending.timer.Stop()
+		delete(c.delayed, key)
+	}
+
+	if delay <= 0 {
+		c.enqueueLocked(key, lane)
+		return
+	}
+
+	if c.delayed == nil {
+		c.delayed = make(map[types.NamespacedName]*delayedKey)
+	}
+	d := &delayedKey{at: at, lane: lane}
+	d.timer = time.AfterFunc(delay, func() {
+		c.delayedMu.Lock()
+		defer c.delayedMu.Unlock()
+		// It may have been canceled or replaced while we were waiting
+		// for the lock.
+		if c.delayed[key] != d {
+			return
+		}
+		delete(c.delayed, key)
+		c.enqueueLocked(key, d.lane)
+	})
+	c.delayed[key] = d
+}
+
+// stopDelayed cancels all of the pending delayed enqueues, and adds the
+// keys waiting out the CoalesceWindow to the work queue now.
+func (c *Impl) stopDelayed() {
+	c.delayedMu.Lock()
+	defer c.delayedMu.Unlock()
+	for key, d := range c.delayed {
+		d.timer.Stop()
+		delete(c.delayed, key)
+	}
+	for key, d := range c.coalesced {
+		d.timer.Stop()
+		delete(c.coalesced, key)
+		c.addKey(key, d.lane)
+	}
+}
+
+func (c *Impl) addKey(key types.NamespacedName, lane int) {
+	if !c.WorkQueue.ShuttingDown() {
+		c.keys.enqueued(key)
+	}
+	if pq, ok := c.WorkQueue.(*PriorityQueue); ok {
+		pq.AddWithPriority(key, lane)
+		return
+	}
+	c.WorkQueue.Add(key)
+}
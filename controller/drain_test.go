@@ -0,0 +1,101 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/types"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+)
+
+func TestDrainTimeout(t *testing.T) {
+	r := &blockingReconciler{
+		started: make(chan string, 10),
+		release: make(chan struct{}),
+	}
+	reporter := &FakeStatsReporter{}
+	impl := NewImplWithStats(r, TestLogger(t), "Testing", reporter)
+	impl.DrainTimeout = 200 * time.Millisecond
+
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+	go func() {
+		defer close(doneCh)
+		impl.Run(1, stopCh)
+	}()
+
+	// The only worker gets stuck, with another key waiting behind it.
+	impl.EnqueueKey(types.NamespacedName{Name: "slow"})
+	<-r.started
+	impl.EnqueueKey(types.NamespacedName{Name: "waiting"})
+
+	start := time.Now()
+	close(stopCh)
+	select {
+	case <-doneCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for controller to finish.")
+	}
+	if elapsed := time.Since(start); elapsed < impl.DrainTimeout {
+		t.Errorf("Run returned after %v, wanted at least %v", elapsed, impl.DrainTimeout)
+	}
+	if diff := cmp.Diff([]int64{1}, reporter.GetDroppedKeys()); diff != "" {
+		t.Errorf("Dropped keys (-want, +got) = %s", diff)
+	}
+
+	// Once the slow key finishes, the worker stops rather than
+	// reconciling the dropped key.
+	close(r.release)
+	select {
+	case key := <-r.started:
+		t.Errorf("Reconciled %s after Run returned", key)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestDrainTimeoutNotReached(t *testing.T) {
+	r := &CountingReconciler{}
+	reporter := &FakeStatsReporter{}
+	impl := NewImplWithStats(r, TestLogger(t), "Testing", reporter)
+	impl.DrainTimeout = 5 * time.Second
+
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+	impl.EnqueueKey(types.NamespacedName{Namespace: "foo", Name: "bar"})
+	go func() {
+		defer close(doneCh)
+		impl.Run(1, stopCh)
+	}()
+
+	close(stopCh)
+	select {
+	case <-doneCh:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for controller to finish.")
+	}
+	if got, want := r.Count, 1; got != want {
+		t.Errorf("Count = %v, wanted %v", got, want)
+	}
+	if got := reporter.GetDroppedKeys(); len(got) != 0 {
+		t.Errorf("Dropped keys = %v, wanted none", got)
+	}
+}
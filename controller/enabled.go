@@ -0,0 +1,104 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"strconv"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ControllersConfigName is the name of the ConfigMap with which operators
+// enable and disable controllers at runtime, by setting the name of their
+// work queue to "true" or "false".  Controllers are enabled by default.
+const ControllersConfigName = "config-controllers"
+
+// ReasonDisabled is the reason of the reconciles skipped because the
+// controller is disabled, which have the OutcomeSuccess outcome.
+const ReasonDisabled = "disabled"
+
+// SetEnabled enables or disables the controller.  A disabled controller
+// holds the keys it dequeues rather than reconciling them, and enqueues
+// them again once it is enabled.
+func (c *Impl) SetEnabled(enabled bool) {
+	c.enabledMu.Lock()
+	if c.disabled == !enabled {
+		c.enabledMu.Unlock()
+		return
+	}
+	c.disabled = !enabled
+	held := c.held
+	c.held = nil
+	c.enabledMu.Unlock()
+
+	c.logger.Infof("Controller enabled: %t", enabled)
+	for key := range held {
+		c.EnqueueKey(key)
+	}
+}
+
+// Enabled returns whether the controller is enabled.
+func (c *Impl) Enabled() bool {
+	c.enabledMu.Lock()
+	defer c.enabledMu.Unlock()
+	return !c.disabled
+}
+
+// hold holds the key when the controller is disabled, and returns whether
+// it did.
+func (c *Impl) hold(key types.NamespacedName) bool {
+	c.enabledMu.Lock()
+	defer c.enabledMu.Unlock()
+	if !c.disabled {
+		return false
+	}
+	if c.held == nil {
+		c.held = make(map[types.NamespacedName]struct{})
+	}
+	c.held[key] = struct{}{}
+	return true
+}
+
+// NewEnabledFromConfigMap returns whether the controller whose work queue
+// has the given name is enabled by the ConfigMap.
+func NewEnabledFromConfigMap(name string, configMap *corev1.ConfigMap) (bool, error) {
+	raw, ok := configMap.Data[name]
+	if !ok {
+		return true, nil
+	}
+	enabled, err := strconv.ParseBool(raw)
+	if err != nil {
+		return true, fmt.Errorf("failed to parse %q: %v", name, err)
+	}
+	return enabled, nil
+}
+
+// UpdateEnabledFromConfigMap enables or disables the controller from the
+// given ControllersConfigName ConfigMap.  It is meant to be passed to a
+// configmap.Watcher, so controllers can be turned off for debugging or
+// staged rollouts without rebuilding images.
+func (c *Impl) UpdateEnabledFromConfigMap(configMap *corev1.ConfigMap) {
+	enabled, err := NewEnabledFromConfigMap(c.name, configMap)
+	if err != nil {
+		c.logger.Errorw("Failed to parse whether the controller is enabled. Previous setting will be used.", zap.Error(err))
+		return
+	}
+	c.SetEnabled(enabled)
+}
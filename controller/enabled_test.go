@@ -0,0 +1,118 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+)
+
+func TestNewEnabledFromConfigMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		want    bool
+		wantErr bool
+	}{{
+		name: "not configured",
+		data: map[string]string{"Other": "false"},
+		want: true,
+	}, {
+		name: "disabled",
+		data: map[string]string{"Testing": "false"},
+	}, {
+		name: "enabled",
+		data: map[string]string{"Testing": "true"},
+		want: true,
+	}, {
+		name:    "invalid",
+		data:    map[string]string{"Testing": "maybe"},
+		want:    true,
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := NewEnabledFromConfigMap("Testing", &corev1.ConfigMap{Data: test.data})
+			if (err != nil) != test.wantErr {
+				t.Errorf("NewEnabledFromConfigMap() = %v, wanted error: %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("NewEnabledFromConfigMap() = %v, wanted %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDisabledController(t *testing.T) {
+	r := &flakyReconciler{}
+	reporter := &FakeStatsReporter{}
+	impl := NewImplWithStats(r, TestLogger(t), "Testing", reporter)
+	key := types.NamespacedName{Namespace: "foo", Name: "bar"}
+
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+	defer func() {
+		close(stopCh)
+		<-doneCh
+	}()
+	go func() {
+		defer close(doneCh)
+		impl.Run(1, stopCh)
+	}()
+
+	impl.UpdateEnabledFromConfigMap(&corev1.ConfigMap{Data: map[string]string{"Testing": "false"}})
+	if impl.Enabled() {
+		t.Error("Enabled() = true, wanted false")
+	}
+
+	// A disabled controller holds the keys rather than reconciling them.
+	impl.EnqueueKey(key)
+	waitFor(t, "the key to be held", func() bool {
+		return len(reporter.GetReconcileOutcomes()) == 1
+	})
+	time.Sleep(50 * time.Millisecond)
+	if got, want := r.Count(), 0; got != want {
+		t.Errorf("Reconcile count while disabled = %d, wanted %d", got, want)
+	}
+
+	// Once enabled again, it reconciles the keys it held.
+	impl.UpdateEnabledFromConfigMap(&corev1.ConfigMap{})
+	waitFor(t, "the key to be reconciled", func() bool {
+		return r.Count() == 1
+	})
+	waitFor(t, "the outcome to be reported", func() bool {
+		return len(reporter.GetReconcileOutcomes()) == 2
+	})
+	want := []FakeReconcileOutcomeData{{
+		Outcome: OutcomeSuccess,
+		Reason:  ReasonDisabled,
+	}, {
+		Outcome: OutcomeSuccess,
+		Reason:  OutcomeSuccess,
+	}}
+	if diff := cmp.Diff(want, reporter.GetReconcileOutcomes()); diff != "" {
+		t.Errorf("Reconcile outcomes (-want, +got) = %s", diff)
+	}
+}
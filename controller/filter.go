@@ -0,0 +1,129 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// filterObject adapts a predicate on metav1.Objects into a FilterFunc
+// for use with cache.FilteringResourceEventHandler, which rejects
+// anything that is not a metav1.Object.
+func filterObject(f func(metav1.Object) bool) func(obj interface{}) bool {
+	return func(obj interface{}) bool {
+		if object, ok := obj.(metav1.Object); ok {
+			return f(object)
+		}
+		return false
+	}
+}
+
+// FilterGroupKind is like Filter, but accepts controlling resources of
+// any version of the schema.GroupKind.
+func FilterGroupKind(gk schema.GroupKind) func(obj interface{}) bool {
+	return filterObject(func(object metav1.Object) bool {
+		owner := metav1.GetControllerOf(object)
+		if owner == nil || owner.Kind != gk.Kind {
+			return false
+		}
+		gv, err := schema.ParseGroupVersion(owner.APIVersion)
+		return err == nil && gv.Group == gk.Group
+	})
+}
+
+// FilterWithName makes it simple to create FilterFunc's for use with
+// cache.FilteringResourceEventHandler that filter based on a name.
+func FilterWithName(name string) func(obj interface{}) bool {
+	return filterObject(func(object metav1.Object) bool {
+		return name == object.GetName()
+	})
+}
+
+// FilterWithNamespace makes it simple to create FilterFunc's for use with
+// cache.FilteringResourceEventHandler that filter based on a namespace.
+func FilterWithNamespace(namespace string) func(obj interface{}) bool {
+	return filterObject(func(object metav1.Object) bool {
+		return namespace == object.GetNamespace()
+	})
+}
+
+// FilterWithNames is like FilterWithName, but accepts any of the names.
+func FilterWithNames(names sets.String) func(obj interface{}) bool {
+	return filterObject(func(object metav1.Object) bool {
+		return names.Has(object.GetName())
+	})
+}
+
+// FilterWithNamespaces is like FilterWithNamespace, but accepts any of
+// the namespaces.
+func FilterWithNamespaces(namespaces sets.String) func(obj interface{}) bool {
+	return filterObject(func(object metav1.Object) bool {
+		return namespaces.Has(object.GetNamespace())
+	})
+}
+
+// FilterWithNamespacedNames is like FilterWithNameAndNamespace, but
+// accepts any of the given namespace/name pairs.
+func FilterWithNamespacedNames(keys ...types.NamespacedName) func(obj interface{}) bool {
+	set := make(map[types.NamespacedName]struct{}, len(keys))
+	for _, key := range keys {
+		set[key] = struct{}{}
+	}
+	return filterObject(func(object metav1.Object) bool {
+		_, ok := set[types.NamespacedName{Namespace: object.GetNamespace(), Name: object.GetName()}]
+		return ok
+	})
+}
+
+// FilterWithSelector makes it simple to create FilterFunc's for use with
+// cache.FilteringResourceEventHandler that filter based on the labels
+// of the resources.
+func FilterWithSelector(selector labels.Selector) func(obj interface{}) bool {
+	return filterObject(func(object metav1.Object) bool {
+		return selector.Matches(labels.Set(object.GetLabels()))
+	})
+}
+
+// And combines FilterFunc's into one that accepts the objects every one
+// of them accepts.  With no filters, it accepts everything.
+func And(filters ...func(obj interface{}) bool) func(obj interface{}) bool {
+	return func(obj interface{}) bool {
+		for _, f := range filters {
+			if !f(obj) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or combines FilterFunc's into one that accepts the objects any of them
+// accepts.  With no filters, it accepts nothing.
+func Or(filters ...func(obj interface{}) bool) func(obj interface{}) bool {
+	return func(obj interface{}) bool {
+		for _, f := range filters {
+			if f(obj) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	. "knative.dev/pkg/testing"
+)
+
+func TestFilters(t *testing.T) {
+	object := &Resource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-name",
+			Namespace: "test-namespace",
+			Labels:    map[string]string{"app": "test"},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "pkg.knative.dev/v1beta3",
+				Kind:       "Parent",
+				Controller: &boolTrue,
+			}},
+		},
+	}
+	accept := func(interface{}) bool { return true }
+	reject := func(interface{}) bool { return false }
+
+	tests := []struct {
+		name   string
+		filter func(interface{}) bool
+		want   bool
+	}{{
+		name:   "group kind, other version",
+		filter: FilterGroupKind(schema.GroupKind{Group: "pkg.knative.dev", Kind: "Parent"}),
+		want:   true,
+	}, {
+		name:   "group kind, wrong group",
+		filter: FilterGroupKind(schema.GroupKind{Group: "other.knative.dev", Kind: "Parent"}),
+	}, {
+		name:   "group kind, wrong kind",
+		filter: FilterGroupKind(schema.GroupKind{Group: "pkg.knative.dev", Kind: "Child"}),
+	}, {
+		name:   "name",
+		filter: FilterWithName("test-name"),
+		want:   true,
+	}, {
+		name:   "wrong name",
+		filter: FilterWithName("wrong-name"),
+	}, {
+		name:   "namespace",
+		filter: FilterWithNamespace("test-namespace"),
+		want:   true,
+	}, {
+		name:   "wrong namespace",
+		filter: FilterWithNamespace("wrong-namespace"),
+	}, {
+		name:   "names",
+		filter: FilterWithNames(sets.NewString("other-name", "test-name")),
+		want:   true,
+	}, {
+		name:   "wrong names",
+		filter: FilterWithNames(sets.NewString("other-name")),
+	}, {
+		name:   "namespaces",
+		filter: FilterWithNamespaces(sets.NewString("other-namespace", "test-namespace")),
+		want:   true,
+	}, {
+		name:   "wrong namespaces",
+		filter: FilterWithNamespaces(sets.NewString()),
+	}, {
+		name: "namespaced names",
+		filter: FilterWithNamespacedNames(
+			types.NamespacedName{Namespace: "other-namespace", Name: "test-name"},
+			types.NamespacedName{Namespace: "test-namespace", Name: "test-name"}),
+		want: true,
+	}, {
+		name: "wrong namespaced names",
+		filter: FilterWithNamespacedNames(
+			types.NamespacedName{Namespace: "other-namespace", Name: "test-name"},
+			types.NamespacedName{Namespace: "test-namespace", Name: "other-name"}),
+	}, {
+		name:   "selector",
+		filter: FilterWithSelector(labels.SelectorFromSet(labels.Set{"app": "test"})),
+		want:   true,
+	}, {
+		name:   "wrong selector",
+		filter: FilterWithSelector(labels.SelectorFromSet(labels.Set{"app": "other"})),
+	}, {
+		name:   "and",
+		filter: And(accept, FilterWithName("test-name")),
+		want:   true,
+	}, {
+		name:   "and, one rejects",
+		filter: And(accept, reject),
+	}, {
+		name:   "and, nothing",
+		filter: And(),
+		want:   true,
+	}, {
+		name:   "or",
+		filter: Or(reject, FilterWithName("test-name")),
+		want:   true,
+	}, {
+		name:   "or, all reject",
+		filter: Or(reject, FilterWithName("wrong-name")),
+	}, {
+		name:   "or, nothing",
+		filter: Or(),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.filter(object); got != test.want {
+				t.Errorf("filter() = %v, wanted %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFiltersRejectNonObjects(t *testing.T) {
+	for name, filter := range map[string]func(interface{}) bool{
+		"group kind": FilterGroupKind(schema.GroupKind{Group: "pkg.knative.dev", Kind: "Parent"}),
+		"name":       FilterWithName(""),
+		"namespace":  FilterWithNamespace(""),
+		"names":      FilterWithNames(sets.NewString("")),
+		"namespaces": FilterWithNamespaces(sets.NewString("")),
+		"keys":       FilterWithNamespacedNames(types.NamespacedName{}),
+		"selector":   FilterWithSelector(labels.Everything()),
+	} {
+		for _, input := range []interface{}{"foo", nil} {
+			if filter(input) {
+				t.Errorf("%s filter(%v) = true, wanted false", name, input)
+			}
+		}
+	}
+}
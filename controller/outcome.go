@@ -0,0 +1,58 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import "context"
+
+// The outcomes of a reconcile, as reported to an OutcomeReporter.
+const (
+	// OutcomeSuccess is the outcome of reconciles that return no error.
+	OutcomeSuccess = "success"
+
+	// OutcomeError is the outcome of reconciles that return an error
+	// which leads to the key being retried.
+	OutcomeError = "error"
+
+	// OutcomePermanentError is the outcome of reconciles that return an
+	// error wrapped with NewPermanentError.
+	OutcomePermanentError = "permanent_error"
+)
+
+// ReasonRequeue is the reason of reconciles that ask for their key to be
+// requeued with reconciler.NewRequeueAfter or NewRequeueImmediately, and
+// which have the OutcomeSuccess outcome.
+const ReasonRequeue = "requeue"
+
+// reasonKey is used as the key for associating the reason of a
+// reconcile with the context.Context passed to the Reconciler.
+type reasonKey struct{}
+
+func withReason(ctx context.Context) (context.Context, *string) {
+	reason := new(string)
+	return context.WithValue(ctx, reasonKey{}, reason), reason
+}
+
+// SetReconcileReason records why the reconcile of the context had the
+// outcome it did (e.g. "skipped" or "leader-not-owner"), so that expected
+// skips can be told apart from real errors in the reconcile outcome
+// metrics.  The reason defaults to the outcome itself.  It has no effect
+// outside of a Reconcile called by an Impl.
+func SetReconcileReason(ctx context.Context, reason string) {
+	if r, ok := ctx.Value(reasonKey{}).(*string); ok {
+		*r = reason
+	}
+}
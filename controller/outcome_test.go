@@ -0,0 +1,99 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/types"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/reconciler"
+)
+
+// reasonReconciler sets the reason, if any, and returns err.
+type reasonReconciler struct {
+	reason string
+	err    error
+}
+
+func (rr *reasonReconciler) Reconcile(ctx context.Context, key string) error {
+	if rr.reason != "" {
+		SetReconcileReason(ctx, rr.reason)
+	}
+	return rr.err
+}
+
+func TestReconcileOutcome(t *testing.T) {
+	tests := []struct {
+		name string
+		r    *reasonReconciler
+		want FakeReconcileOutcomeData
+	}{{
+		name: "success",
+		r:    &reasonReconciler{},
+		want: FakeReconcileOutcomeData{Outcome: OutcomeSuccess, Reason: OutcomeSuccess},
+	}, {
+		name: "skipped",
+		r:    &reasonReconciler{reason: "skipped"},
+		want: FakeReconcileOutcomeData{Outcome: OutcomeSuccess, Reason: "skipped"},
+	}, {
+		name: "error",
+		r:    &reasonReconciler{err: errors.New("transient")},
+		want: FakeReconcileOutcomeData{Outcome: OutcomeError, Reason: OutcomeError},
+	}, {
+		name: "error with reason",
+		r:    &reasonReconciler{reason: "requeued", err: errors.New("transient")},
+		want: FakeReconcileOutcomeData{Outcome: OutcomeError, Reason: "requeued"},
+	}, {
+		name: "requeue",
+		r:    &reasonReconciler{err: reconciler.NewRequeueImmediately()},
+		want: FakeReconcileOutcomeData{Outcome: OutcomeSuccess, Reason: ReasonRequeue},
+	}, {
+		name: "requeue with reason",
+		r:    &reasonReconciler{reason: "polling", err: reconciler.NewRequeueAfter(time.Minute)},
+		want: FakeReconcileOutcomeData{Outcome: OutcomeSuccess, Reason: "polling"},
+	}, {
+		name: "permanent error",
+		r:    &reasonReconciler{err: NewPermanentError(errors.New("permanent"))},
+		want: FakeReconcileOutcomeData{Outcome: OutcomePermanentError, Reason: OutcomePermanentError},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reporter := &FakeStatsReporter{}
+			impl := NewImplWithStats(test.r, TestLogger(t), "Testing", reporter)
+			defer impl.WorkQueue.ShutDown()
+			impl.EnqueueKey(types.NamespacedName{Namespace: "foo", Name: "bar"})
+			impl.processNextWorkItem(impl.WorkQueue.Get)
+
+			if diff := cmp.Diff([]FakeReconcileOutcomeData{test.want}, reporter.GetReconcileOutcomes()); diff != "" {
+				t.Errorf("Outcomes (-want, +got) = %s", diff)
+			}
+		})
+	}
+}
+
+func TestSetReconcileReasonOutsideReconcile(t *testing.T) {
+	// This should not panic.
+	SetReconcileReason(context.Background(), "skipped")
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DefaultParkingBackoff is how long parked keys wait before they are
+// retried when the ParkingLot does not say otherwise.
+const DefaultParkingBackoff = 10 * time.Minute
+
+// ParkingLot configures how the controller treats keys that keep failing
+// to reconcile.  Instead of being requeued with the work queue's rate
+// limiting, a key that has failed Threshold times in a row is "parked":
+// it is retried only after Backoff, on the lowest priority lane of the
+// work queue, until it reconciles successfully again.
+type ParkingLot struct {
+	// Threshold is the number of consecutive failures after which a key
+	// is parked.  Zero disables parking.
+	Threshold int
+
+	// Backoff is how long a parked key waits before it is retried.
+	// It defaults to DefaultParkingBackoff.
+	Backoff time.Duration
+}
+
+// ParkedKeys returns the keys that are currently parked, so that they
+// may be surfaced (e.g. in a condition of some resource).
+func (c *Impl) ParkedKeys() []types.NamespacedName {
+	c.parkingMu.Lock()
+	defer c.parkingMu.Unlock()
+	keys := make([]types.NamespacedName, 0, len(c.parked))
+	for key := range c.parked {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	return keys
+}
+
+// fail records a failed reconcile of the key, returning whether it
+// should be parked.
+func (c *Impl) fail(key types.NamespacedName) bool {
+	if c.ParkingLot.Threshold <= 0 {
+		return false
+	}
+
+	c.parkingMu.Lock()
+	defer c.parkingMu.Unlock()
+	if c.failures == nil {
+		c.failures = make(map[types.NamespacedName]int)
+		c.parked = make(map[types.NamespacedName]struct{})
+	}
+	c.failures[key]++
+	if c.failures[key] < c.ParkingLot.Threshold {
+		return false
+	}
+	if _, ok := c.parked[key]; !ok {
+		c.parked[key] = struct{}{}
+		c.reportParkedKeys()
+	}
+	return true
+}
+
+// park schedules the retry of a parked key.
+func (c *Impl) park(key types.NamespacedName) {
+	backoff := c.ParkingLot.Backoff
+	if backoff <= 0 {
+		backoff = DefaultParkingBackoff
+	}
+	c.logger.Warnf("Parking key %s after %d consecutive failures, retrying in %v", safeKey(key), c.ParkingLot.Threshold, backoff)
+	c.WorkQueue.Forget(key)
+	c.EnqueueKeyAfterWithPriority(key, backoff, c.lowestLane())
+}
+
+// unpark forgets about the failures of the key.
+func (c *Impl) unpark(key types.NamespacedName) {
+	c.parkingMu.Lock()
+	defer c.parkingMu.Unlock()
+	delete(c.failures, key)
+	if _, ok := c.parked[key]; ok {
+		delete(c.parked, key)
+		c.logger.Infof("Unparking key %s", safeKey(key))
+		c.reportParkedKeys()
+	}
+}
+
+// reportParkedKeys reports the number of parked keys, when the stats
+// reporter supports it; the caller holds parkingMu.
+func (c *Impl) reportParkedKeys() {
+	if r, ok := c.statsReporter.(ParkingLotReporter); ok {
+		r.ReportParkedKeys(int64(len(c.parked)))
+	}
+}
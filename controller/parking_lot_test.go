@@ -0,0 +1,144 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/types"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+)
+
+type flakyReconciler struct {
+	m     sync.Mutex
+	count int
+	fail  bool
+}
+
+func (fr *flakyReconciler) Reconcile(context.Context, string) error {
+	fr.m.Lock()
+	defer fr.m.Unlock()
+	fr.count++
+	if fr.fail {
+		return errors.New("I fail until told otherwise")
+	}
+	return nil
+}
+
+func (fr *flakyReconciler) Count() int {
+	fr.m.Lock()
+	defer fr.m.Unlock()
+	return fr.count
+}
+
+func (fr *flakyReconciler) Succeed() {
+	fr.m.Lock()
+	defer fr.m.Unlock()
+	fr.fail = false
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %s", what)
+}
+
+func TestParkingLot(t *testing.T) {
+	r := &flakyReconciler{fail: true}
+	reporter := &FakeStatsReporter{}
+	impl := NewImplWithStats(r, TestLogger(t), "Testing", reporter)
+	impl.ParkingLot = ParkingLot{
+		Threshold: 3,
+		Backoff:   300 * time.Millisecond,
+	}
+	key := types.NamespacedName{Namespace: "foo", Name: "bar"}
+
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+	defer func() {
+		close(stopCh)
+		<-doneCh
+	}()
+	go func() {
+		defer close(doneCh)
+		impl.Run(1, stopCh)
+	}()
+
+	impl.EnqueueKey(key)
+	waitFor(t, "the key to be parked", func() bool {
+		return len(impl.ParkedKeys()) == 1
+	})
+	if diff := cmp.Diff([]types.NamespacedName{key}, impl.ParkedKeys()); diff != "" {
+		t.Errorf("ParkedKeys (-want, +got) = %s", diff)
+	}
+	if got, want := r.Count(), 3; got != want {
+		t.Errorf("Reconcile count = %d, wanted %d", got, want)
+	}
+
+	// A parked key is left alone until its backoff has passed.
+	time.Sleep(100 * time.Millisecond)
+	if got, want := r.Count(), 3; got != want {
+		t.Errorf("Reconcile count while parked = %d, wanted %d", got, want)
+	}
+
+	// Once it reconciles successfully it is unparked.
+	r.Succeed()
+	waitFor(t, "the key to be unparked", func() bool {
+		return len(impl.ParkedKeys()) == 0
+	})
+	if got, want := r.Count(), 4; got != want {
+		t.Errorf("Reconcile count = %d, wanted %d", got, want)
+	}
+	if diff := cmp.Diff([]int64{1, 0}, reporter.GetParkedKeys()); diff != "" {
+		t.Errorf("Parked key counts (-want, +got) = %s", diff)
+	}
+}
+
+func TestParkingLotDisabled(t *testing.T) {
+	r := &flakyReconciler{fail: true}
+	impl := NewImplWithStats(r, TestLogger(t), "Testing", &FakeStatsReporter{})
+
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+	go func() {
+		defer close(doneCh)
+		impl.Run(1, stopCh)
+	}()
+
+	impl.EnqueueKey(types.NamespacedName{Namespace: "foo", Name: "bar"})
+	waitFor(t, "the key to be retried", func() bool {
+		return r.Count() > 5
+	})
+	close(stopCh)
+	<-doneCh
+
+	if got := impl.ParkedKeys(); len(got) != 0 {
+		t.Errorf("ParkedKeys = %v, wanted none", got)
+	}
+}
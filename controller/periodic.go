@@ -0,0 +1,65 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+)
+
+// EnqueueKeyEvery enqueues the namespace/name string every period, until
+// stopCh is closed or the work queue is shut down.  Each period is
+// lengthened by a random amount of up to jitterFactor times the period,
+// so that keys scheduled together do not stay in lock step.
+//
+// It is meant for reconcilers that poll external systems, in place of
+// calling EnqueueKeyAfter from Reconcile.  When the work queue is a
+// PriorityQueue, the key is enqueued onto its lowest priority lane.
+func (c *Impl) EnqueueKeyEvery(key types.NamespacedName, period time.Duration, jitterFactor float64, stopCh <-chan struct{}) {
+	c.every(period, jitterFactor, stopCh, func() {
+		c.EnqueueKeyWithPriority(key, c.lowestLane())
+	})
+}
+
+// EnqueueAllEvery is like EnqueueKeyEvery, but enqueues all of the objects
+// from the SharedInformer, as GlobalResync does, every period.  Unlike the
+// resync of the informer it does not replay the informer's events, so the
+// objects are only enqueued once.
+func (c *Impl) EnqueueAllEvery(si cache.SharedInformer, period time.Duration, jitterFactor float64, stopCh <-chan struct{}) {
+	c.every(period, jitterFactor, stopCh, func() {
+		c.GlobalResync(si)
+	})
+}
+
+func (c *Impl) every(period time.Duration, jitterFactor float64, stopCh <-chan struct{}, f func()) {
+	go func() {
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-time.After(wait.Jitter(period, jitterFactor)):
+				if c.WorkQueue.ShuttingDown() {
+					return
+				}
+				f()
+			}
+		}
+	}()
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+)
+
+func TestEnqueueKeyEvery(t *testing.T) {
+	r := &recordingReconciler{}
+	impl := NewImplWithStats(r, TestLogger(t), "Testing", &FakeStatsReporter{})
+	defer impl.WorkQueue.ShutDown()
+
+	stopCh := make(chan struct{})
+	impl.EnqueueKeyEvery(types.NamespacedName{Namespace: "foo", Name: "bar"}, 10*time.Millisecond, 0.5, stopCh)
+
+	// The key is enqueued again after each time it is processed.
+	for i := 0; i < 3; i++ {
+		waitFor(t, "the key to be enqueued", func() bool {
+			return impl.WorkQueue.Len() == 1
+		})
+		impl.ProcessAll()
+	}
+
+	close(stopCh)
+	time.Sleep(50 * time.Millisecond)
+	impl.ProcessAll()
+	time.Sleep(50 * time.Millisecond)
+	if got, want := impl.WorkQueue.Len(), 0; got != want {
+		t.Errorf("|Queue| = %d after stopping, wanted %d", got, want)
+	}
+	if got, want := len(r.keys), 3; got < want {
+		t.Errorf("Reconciled %d times, wanted at least %d", got, want)
+	}
+}
+
+func TestEnqueueAllEvery(t *testing.T) {
+	impl := NewImplWithStats(&NopReconciler{}, TestLogger(t), "Testing", &FakeStatsReporter{})
+	defer impl.WorkQueue.ShutDown()
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	impl.EnqueueAllEvery(&dummyInformer{}, 10*time.Millisecond, 0, stopCh)
+
+	// Like a GlobalResync, the objects are enqueued with a delay.
+	waitFor(t, "the objects to be scheduled", func() bool {
+		impl.delayedMu.Lock()
+		defer impl.delayedMu.Unlock()
+		return len(impl.delayed) == len(dummyObjs)
+	})
+	if got, want := impl.FlushDelayed(), len(dummyObjs); got < want {
+		t.Errorf("FlushDelayed() = %d, wanted at least %d", got, want)
+	}
+}
+
+func TestEnqueueKeyEveryStopsWithQueue(t *testing.T) {
+	impl := NewImplWithStats(&NopReconciler{}, TestLogger(t), "Testing", &FakeStatsReporter{})
+	impl.WorkQueue.ShutDown()
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	impl.EnqueueKeyEvery(types.NamespacedName{Namespace: "foo", Name: "bar"}, time.Millisecond, 0, stopCh)
+	time.Sleep(20 * time.Millisecond)
+	if got, want := impl.WorkQueue.Len(), 0; got != want {
+		t.Errorf("|Queue| = %d, wanted %d", got, want)
+	}
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+)
+
+// Lane configures one priority level of a PriorityQueue.
+type Lane struct {
+	// ReservedWorkers is the number of workers that only ever process
+	// the keys of this lane, so that it makes progress no matter how
+	// busy the shared workers are with the other lanes.
+	ReservedWorkers int
+}
+
+// PriorityQueue is a workqueue.RateLimitingInterface that keeps its items
+// in a fixed number of lanes, lane 0 having the highest priority.  Get
+// returns the oldest item of the highest priority lane that has any.
+//
+// Like the client-go work queues, an item is never handed out to two
+// workers at the same time, and an item added again while it is queued
+// only moves it to the higher priority lane of the two.
+type PriorityQueue struct {
+	rateLimiter workqueue.RateLimiter
+	lanes       []Lane
+
+	cond  *sync.Cond
+	queue [][]interface{}
+	// dirty maps the items that need processing to the lane they are
+	// (or will be, once they are Done) queued on.
+	dirty map[interface{}]int
+	// processing maps the items handed out by Get to the lane they
+	// were taken from.
+	processing   map[interface{}]int
+	shuttingDown bool
+}
+
+var _ workqueue.RateLimitingInterface = (*PriorityQueue)(nil)
+
+// NewPriorityQueue creates a PriorityQueue with the given lanes, of
+// which there must be at least one.
+func NewPriorityQueue(rateLimiter workqueue.RateLimiter, lanes ...Lane) *PriorityQueue {
+	if len(lanes) == 0 {
+		lanes = []Lane{{}}
+	}
+	return &PriorityQueue{
+		rateLimiter: rateLimiter,
+		lanes:       lanes,
+		cond:        sync.NewCond(&sync.Mutex{}),
+		queue:       make([][]interface{}, len(lanes)),
+		dirty:       make(map[interface{}]int),
+		processing:  make(map[interface{}]int),
+	}
+}
+
+// Lanes returns the configuration of the queue's lanes.
+func (q *PriorityQueue) Lanes() []Lane {
+	return q.lanes
+}
+
+// Add implements workqueue.Interface, adding the item to lane 0.
+func (q *PriorityQueue) Add(item interface{}) {
+	q.AddWithPriority(item, 0)
+}
+
+// AddWithPriority adds the item to the given lane.  Lanes out of range
+// are clamped to the closest existing one.
+func (q *PriorityQueue) AddWithPriority(item interface{}, lane int) {
+	lane = q.clamp(lane)
+
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	if q.shuttingDown {
+		return
+	}
+
+	current, queued := q.dirty[item]
+	if queued && current <= lane {
+		return
+	}
+	q.dirty[item] = lane
+	if _, ok := q.processing[item]; ok {
+		// Done will queue it.
+		return
+	}
+	if queued {
+		q.remove(current, item)
+	}
+	q.queue[lane] = append(q.queue[lane], item)
+	q.cond.Broadcast()
+}
+
+// AddAfter implements workqueue.DelayingInterface, adding the item to
+// lane 0 once the duration has passed.
+func (q *PriorityQueue) AddAfter(item interface{}, duration time.Duration) {
+	q.AddAfterWithPriority(item, duration, 0)
+}
+
+// AddAfterWithPriority adds the item to the given lane once the duration
+// has passed.
+func (q *PriorityQueue) AddAfterWithPriority(item interface{}, duration time.Duration, lane int) {
+	if q.ShuttingDown() {
+		return
+	}
+	if duration <= 0 {
+		q.AddWithPriority(item, lane)
+		return
+	}
+	time.AfterFunc(duration, func() {
+		q.AddWithPriority(item, lane)
+	})
+}
+
+// AddRateLimited implements workqueue.RateLimitingInterface.  Items being
+// processed are requeued on the lane they were taken from.
+func (q *PriorityQueue) AddRateLimited(item interface{}) {
+	q.cond.L.Lock()
+	lane := q.processing[item]
+	q.cond.L.Unlock()
+	q.AddAfterWithPriority(item, q.rateLimiter.When(item), lane)
+}
+
+// Forget implements workqueue.RateLimitingInterface
+func (q *PriorityQueue) Forget(item interface{}) {
+	q.rateLimiter.Forget(item)
+}
+
+// NumRequeues implements workqueue.RateLimitingInterface
+func (q *PriorityQueue) NumRequeues(item interface{}) int {
+	return q.rateLimiter.NumRequeues(item)
+}
+
+// Len implements workqueue.Interface, returning the number of items
+// queued across all lanes.
+func (q *PriorityQueue) Len() int {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	n := 0
+	for _, items := range q.queue {
+		n += len(items)
+	}
+	return n
+}
+
+// Get implements workqueue.Interface, blocking until an item is available
+// on any lane.
+func (q *PriorityQueue) Get() (interface{}, bool) {
+	return q.get(0, len(q.lanes)-1)
+}
+
+// GetFromLane is like Get, but only returns items of the given lane.
+func (q *PriorityQueue) GetFromLane(lane int) (interface{}, bool) {
+	lane = q.clamp(lane)
+	return q.get(lane, lane)
+}
+
+func (q *PriorityQueue) get(from, to int) (interface{}, bool) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	for {
+		for lane := from; lane <= to; lane++ {
+			if len(q.queue[lane]) == 0 {
+				continue
+			}
+			item := q.queue[lane][0]
+			q.queue[lane][0] = nil
+			q.queue[lane] = q.queue[lane][1:]
+			delete(q.dirty, item)
+			q.processing[item] = lane
+			return item, false
+		}
+		if q.shuttingDown {
+			return nil, true
+		}
+		q.cond.Wait()
+	}
+}
+
+// Done implements workqueue.Interface
+func (q *PriorityQueue) Done(item interface{}) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	delete(q.processing, item)
+	if lane, ok := q.dirty[item]; ok {
+		q.queue[lane] = append(q.queue[lane], item)
+		q.cond.Broadcast()
+	}
+}
+
+// ShutDown implements workqueue.Interface
+func (q *PriorityQueue) ShutDown() {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	q.shuttingDown = true
+	q.cond.Broadcast()
+}
+
+// ShuttingDown implements workqueue.Interface
+func (q *PriorityQueue) ShuttingDown() bool {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	return q.shuttingDown
+}
+
+func (q *PriorityQueue) clamp(lane int) int {
+	switch {
+	case lane < 0:
+		return 0
+	case lane >= len(q.lanes):
+		return len(q.lanes) - 1
+	default:
+		return lane
+	}
+}
+
+// remove drops the item from the given lane; the caller holds the lock.
+func (q *PriorityQueue) remove(lane int, item interface{}) {
+	for i, it := range q.queue[lane] {
+		if it == item {
+			q.queue[lane] = append(q.queue[lane][:i], q.queue[lane][i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+)
+
+func drainPriorityQueue(q *PriorityQueue) (got []interface{}) {
+	q.ShutDown()
+	for {
+		item, shutdown := q.Get()
+		if shutdown {
+			return got
+		}
+		got = append(got, item)
+		q.Done(item)
+	}
+}
+
+func TestPriorityQueueOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		add  func(q *PriorityQueue)
+		want []interface{}
+	}{{
+		name: "fifo within a lane",
+		add: func(q *PriorityQueue) {
+			q.Add("a")
+			q.Add("b")
+			q.Add("c")
+		},
+		want: []interface{}{"a", "b", "c"},
+	}, {
+		name: "higher lanes first",
+		add: func(q *PriorityQueue) {
+			q.AddWithPriority("low", 2)
+			q.AddWithPriority("mid", 1)
+			q.Add("high")
+		},
+		want: []interface{}{"high", "mid", "low"},
+	}, {
+		name: "lanes are clamped",
+		add: func(q *PriorityQueue) {
+			q.AddWithPriority("low", 42)
+			q.AddWithPriority("high", -1)
+			q.AddWithPriority("mid", 1)
+		},
+		want: []interface{}{"high", "mid", "low"},
+	}, {
+		name: "duplicates are promoted",
+		add: func(q *PriorityQueue) {
+			q.AddWithPriority("a", 2)
+			q.AddWithPriority("b", 2)
+			q.AddWithPriority("b", 0)
+		},
+		want: []interface{}{"b", "a"},
+	}, {
+		name: "duplicates are not demoted",
+		add: func(q *PriorityQueue) {
+			q.AddWithPriority("a", 1)
+			q.AddWithPriority("b", 0)
+			q.AddWithPriority("b", 2)
+		},
+		want: []interface{}{"b", "a"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q := NewPriorityQueue(workqueue.DefaultControllerRateLimiter(), Lane{}, Lane{}, Lane{})
+			test.add(q)
+			if got, want := q.Len(), len(test.want); got != want {
+				t.Errorf("Len() = %d, wanted %d", got, want)
+			}
+			if diff := cmp.Diff(test.want, drainPriorityQueue(q)); diff != "" {
+				t.Errorf("Get() (-want, +got) = %s", diff)
+			}
+		})
+	}
+}
+
+func TestPriorityQueueProcessing(t *testing.T) {
+	q := NewPriorityQueue(workqueue.DefaultControllerRateLimiter(), Lane{}, Lane{})
+
+	q.AddWithPriority("a", 1)
+	item, _ := q.Get()
+
+	// While being processed the item is not handed out again ...
+	q.AddWithPriority("a", 1)
+	q.Add("a")
+	if got, want := q.Len(), 0; got != want {
+		t.Errorf("Len() = %d, wanted %d", got, want)
+	}
+
+	// ... but it is requeued, on the highest lane it was added to, once Done.
+	q.AddWithPriority("b", 1)
+	q.Done(item)
+	if diff := cmp.Diff([]interface{}{"a", "b"}, drainPriorityQueue(q)); diff != "" {
+		t.Errorf("Get() (-want, +got) = %s", diff)
+	}
+}
+
+func TestPriorityQueueGetFromLane(t *testing.T) {
+	q := NewPriorityQueue(workqueue.DefaultControllerRateLimiter(), Lane{}, Lane{})
+	q.Add("high")
+	q.AddWithPriority("low", 1)
+
+	if got, _ := q.GetFromLane(1); got != "low" {
+		t.Errorf("GetFromLane(1) = %v, wanted low", got)
+	}
+
+	q.ShutDown()
+	if got, shutdown := q.GetFromLane(1); !shutdown {
+		t.Errorf("GetFromLane(1) = %v, wanted shutdown", got)
+	}
+	if got, _ := q.GetFromLane(0); got != "high" {
+		t.Errorf("GetFromLane(0) = %v, wanted high", got)
+	}
+}
+
+func TestPriorityQueueAddRateLimited(t *testing.T) {
+	q := NewPriorityQueue(workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond, time.Millisecond), Lane{}, Lane{})
+	q.AddWithPriority("a", 1)
+	item, _ := q.Get()
+
+	q.AddRateLimited(item)
+	q.Done(item)
+	if got, want := q.NumRequeues(item), 1; got != want {
+		t.Errorf("NumRequeues() = %d, wanted %d", got, want)
+	}
+
+	// The item is requeued on the lane it was taken from.
+	if got, _ := q.GetFromLane(1); got != item {
+		t.Errorf("GetFromLane(1) = %v, wanted %v", got, item)
+	}
+	q.Forget(item)
+	if got, want := q.NumRequeues(item), 0; got != want {
+		t.Errorf("NumRequeues() = %d, wanted %d", got, want)
+	}
+}
+
+func TestPriorityQueueShutDown(t *testing.T) {
+	q := NewPriorityQueue(workqueue.DefaultControllerRateLimiter())
+	q.ShutDown()
+	q.Add("a")
+	q.AddAfter("b", time.Millisecond)
+	if got, want := q.Len(), 0; got != want {
+		t.Errorf("Len() = %d, wanted %d", got, want)
+	}
+	if !q.ShuttingDown() {
+		t.Error("ShuttingDown() = false, wanted true")
+	}
+}
+
+type blockingReconciler struct {
+	started chan string
+	release chan struct{}
+}
+
+func (br *blockingReconciler) Reconcile(ctx context.Context, key string) error {
+	br.started <- key
+	if key == "slow" {
+		<-br.release
+	}
+	return nil
+}
+
+func TestReservedWorkers(t *testing.T) {
+	r := &blockingReconciler{
+		started: make(chan string, 10),
+		release: make(chan struct{}),
+	}
+	impl := NewImplWithLanes(r, TestLogger(t), "Testing", &FakeStatsReporter{}, Lane{ReservedWorkers: 1}, Lane{})
+
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+	go func() {
+		defer close(doneCh)
+		impl.Run(1, stopCh)
+	}()
+
+	// Occupy the only shared worker with a low priority key.
+	impl.EnqueueKeyWithPriority(types.NamespacedName{Name: "slow"}, 1)
+	if got := <-r.started; got != "slow" {
+		t.Fatalf("Reconcile(%q), wanted slow", got)
+	}
+
+	// The reserved worker still picks up high priority keys.
+	impl.EnqueueKey(types.NamespacedName{Name: "fast"})
+	select {
+	case got := <-r.started:
+		if got != "fast" {
+			t.Errorf("Reconcile(%q), wanted fast", got)
+		}
+	case <-time.After(time.Second):
+		t.Error("Timed out waiting for the reserved worker.")
+	}
+
+	close(r.release)
+	close(stopCh)
+	select {
+	case <-doneCh:
+	case <-time.After(time.Second):
+		t.Error("Timed out waiting for controller to finish.")
+	}
+}
+
+func TestGlobalResyncUsesLowestLane(t *testing.T) {
+	impl := NewImplWithLanes(&NopReconciler{}, TestLogger(t), "Testing", &FakeStatsReporter{}, Lane{}, Lane{}, Lane{})
+	impl.EnqueueKey(types.NamespacedName{Namespace: "the", Name: "waterfall"})
+	impl.GlobalResync(&dummyInformer{})
+
+	pq := impl.WorkQueue.(*PriorityQueue)
+	// Wait for the jittered resync to enqueue its keys.
+	for i := 0; i < 50 && pq.Len() < 1+len(dummyObjs); i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	if got, _ := pq.GetFromLane(0); got != (types.NamespacedName{Namespace: "the", Name: "waterfall"}) {
+		t.Errorf("GetFromLane(0) = %v, wanted the/waterfall", got)
+	}
+	pq.ShutDown()
+	var got int
+	for {
+		if _, shutdown := pq.GetFromLane(2); shutdown {
+			break
+		}
+		got++
+	}
+	if want := len(dummyObjs); got != want {
+		t.Errorf("Resynced keys on lowest lane = %d, wanted %d", got, want)
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ProcessAll reconciles the keys in the work queue on the calling
+// goroutine, in the order they were enqueued, until the queue is empty,
+// and returns the number of reconciles it performed.  Keys requeued
+// during a reconcile are processed again, but keys retried with a delay
+// after failing are not waited for.
+//
+// It is meant for tests, which call it instead of Run to drive the
+// handler → enqueue → reconcile flow deterministically, without sleeping.
+func (c *Impl) ProcessAll() int {
+	n := 0
+	for c.WorkQueue.Len() > 0 {
+		c.processNextWorkItem(c.WorkQueue.Get)
+		n++
+	}
+	return n
+}
+
+// FlushDelayed adds every key whose enqueue was delayed (e.g. with
+// EnqueueAfter, by a GlobalResync or by the CoalesceWindow) to the work
+// queue now, in the order they were due, and returns the number of keys
+// it added.  Like ProcessAll it is meant for tests.
+func (c *Impl) FlushDelayed() int {
+	c.delayedMu.Lock()
+	type pending struct {
+		key types.NamespacedName
+		*delayedKey
+	}
+	all := make([]pending, 0, len(c.delayed)+len(c.coalesced))
+	for _, m := range []map[types.NamespacedName]*delayedKey{c.delayed, c.coalesced} {
+		for key, d := range m {
+			d.timer.Stop()
+			all = append(all, pending{key: key, delayedKey: d})
+		}
+	}
+	c.delayed = nil
+	c.coalesced = nil
+	c.delayedMu.Unlock()
+
+	sort.Slice(all, func(i, j int) bool {
+		if !all[i].at.Equal(all[j].at) {
+			return all[i].at.Before(all[j].at)
+		}
+		return all[i].key.String() < all[j].key.String()
+	})
+	for _, p := range all {
+		c.addKey(p.key, p.lane)
+	}
+	return len(all)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/types"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+)
+
+// recordingReconciler remembers the keys it reconciled, and runs the
+// optional hook for each of them.
+type recordingReconciler struct {
+	keys []string
+	hook func(key string) error
+}
+
+func (rr *recordingReconciler) Reconcile(ctx context.Context, key string) error {
+	rr.keys = append(rr.keys, key)
+	if rr.hook != nil {
+		return rr.hook(key)
+	}
+	return nil
+}
+
+func TestProcessAll(t *testing.T) {
+	r := &recordingReconciler{}
+	impl := NewImplWithStats(r, TestLogger(t), "Testing", &FakeStatsReporter{})
+	defer impl.WorkQueue.ShutDown()
+
+	// The first reconcile enqueues another key, and fails, which only
+	// retries it after a delay.
+	r.hook = func(key string) error {
+		if key == "first" {
+			impl.EnqueueKey(types.NamespacedName{Name: "third"})
+			return errors.New("try again later")
+		}
+		return nil
+	}
+
+	impl.EnqueueKey(types.NamespacedName{Name: "first"})
+	impl.EnqueueKey(types.NamespacedName{Name: "second"})
+	if got, want := impl.ProcessAll(), 3; got != want {
+		t.Errorf("ProcessAll() = %d, wanted %d", got, want)
+	}
+	if diff := cmp.Diff([]string{"first", "second", "third"}, r.keys); diff != "" {
+		t.Errorf("Reconciled keys (-want, +got) = %s", diff)
+	}
+
+	if got, want := impl.ProcessAll(), 0; got != want {
+		t.Errorf("ProcessAll() = %d, wanted %d", got, want)
+	}
+}
+
+func TestFlushDelayed(t *testing.T) {
+	r := &recordingReconciler{}
+	impl := NewImplWithStats(r, TestLogger(t), "Testing", &FakeStatsReporter{})
+	defer impl.WorkQueue.ShutDown()
+
+	impl.EnqueueKeyAfter(types.NamespacedName{Name: "later"}, time.Hour)
+	impl.EnqueueKeyAfter(types.NamespacedName{Name: "sooner"}, time.Minute)
+	impl.EnqueueKey(types.NamespacedName{Name: "now"})
+
+	if got, want := impl.ProcessAll(), 1; got != want {
+		t.Errorf("ProcessAll() = %d, wanted %d", got, want)
+	}
+	if got, want := impl.FlushDelayed(), 2; got != want {
+		t.Errorf("FlushDelayed() = %d, wanted %d", got, want)
+	}
+	if got, want := impl.ProcessAll(), 2; got != want {
+		t.Errorf("ProcessAll() = %d, wanted %d", got, want)
+	}
+	if diff := cmp.Diff([]string{"now", "sooner", "later"}, r.keys); diff != "" {
+		t.Errorf("Reconciled keys (-want, +got) = %s", diff)
+	}
+
+	if got, want := impl.FlushDelayed(), 0; got != want {
+		t.Errorf("FlushDelayed() = %d, wanted %d", got, want)
+	}
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	rateLimiterBaseDelayKey = "rate-limiter.base-delay"
+	rateLimiterMaxDelayKey  = "rate-limiter.max-delay"
+	rateLimiterQPSKey       = "rate-limiter.qps"
+	rateLimiterBurstKey     = "rate-limiter.burst"
+)
+
+// RateLimiterConfig holds the parameters of the rate limiter that delays
+// the retries of failed keys in a controller's work queue.
+type RateLimiterConfig struct {
+	// BaseDelay and MaxDelay bound the exponential backoff of each key.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// QPS and Burst limit the overall rate of retries.
+	QPS   float64
+	Burst int
+}
+
+// DefaultRateLimiterConfig returns the configuration of the rate limiter
+// of client-go's workqueue.DefaultControllerRateLimiter.
+func DefaultRateLimiterConfig() RateLimiterConfig {
+	return RateLimiterConfig{
+		BaseDelay: 5 * time.Millisecond,
+		MaxDelay:  1000 * time.Second,
+		QPS:       10,
+		Burst:     100,
+	}
+}
+
+// NewRateLimiterConfigFromMap creates a RateLimiterConfig for the work
+// queue with the given name from a map.  Keys prefixed with the name of
+// the work queue and a dot (e.g. "Revisions.rate-limiter.qps") take
+// precedence over the unprefixed ones, which apply to every controller.
+func NewRateLimiterConfigFromMap(name string, data map[string]string) (RateLimiterConfig, error) {
+	config := DefaultRateLimiterConfig()
+	lookup := func(key string) (string, bool) {
+		if v, ok := data[name+"."+key]; ok {
+			return v, true
+		}
+		v, ok := data[key]
+		return v, ok
+	}
+
+	for _, d := range []struct {
+		key   string
+		field *time.Duration
+	}{
+		{rateLimiterBaseDelayKey, &config.BaseDelay},
+		{rateLimiterMaxDelayKey, &config.MaxDelay},
+	} {
+		if raw, ok := lookup(d.key); ok {
+			v, err := time.ParseDuration(raw)
+			if err != nil {
+				return config, fmt.Errorf("failed to parse %q: %v", d.key, err)
+			}
+			*d.field = v
+		}
+	}
+	if raw, ok := lookup(rateLimiterQPSKey); ok {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return config, fmt.Errorf("failed to parse %q: %v", rateLimiterQPSKey, err)
+		}
+		config.QPS = v
+	}
+	if raw, ok := lookup(rateLimiterBurstKey); ok {
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return config, fmt.Errorf("failed to parse %q: %v", rateLimiterBurstKey, err)
+		}
+		config.Burst = v
+	}
+
+	switch {
+	case config.BaseDelay <= 0:
+		return config, fmt.Errorf("%s must be positive, was: %v", rateLimiterBaseDelayKey, config.BaseDelay)
+	case config.MaxDelay < config.BaseDelay:
+		return config, fmt.Errorf("%s must not be less than %s, was: %v", rateLimiterMaxDelayKey, rateLimiterBaseDelayKey, config.MaxDelay)
+	case config.QPS <= 0:
+		return config, fmt.Errorf("%s must be positive, was: %v", rateLimiterQPSKey, config.QPS)
+	case config.Burst <= 0:
+		return config, fmt.Errorf("%s must be positive, was: %v", rateLimiterBurstKey, config.Burst)
+	}
+	return config, nil
+}
+
+// NewRateLimiterConfigFromConfigMap creates a RateLimiterConfig for the
+// work queue with the given name from the supplied ConfigMap.
+func NewRateLimiterConfigFromConfigMap(name string, configMap *corev1.ConfigMap) (RateLimiterConfig, error) {
+	return NewRateLimiterConfigFromMap(name, configMap.Data)
+}
+
+// UpdateRateLimiterFromConfigMap reconfigures the rate limiter of the
+// controller's work queue from the given ConfigMap.  It is meant to be
+// passed to a configmap.Watcher, so retries can be tuned without
+// restarting the controller.
+func (c *Impl) UpdateRateLimiterFromConfigMap(configMap *corev1.ConfigMap) {
+	if c.rateLimiter == nil {
+		c.logger.Warn("The work queue's rate limiter cannot be reconfigured.")
+		return
+	}
+	config, err := NewRateLimiterConfigFromConfigMap(c.name, configMap)
+	if err != nil {
+		c.logger.Errorw("Failed to parse the rate limiter configuration. Previous configuration will be used.", zap.Error(err))
+		return
+	}
+	if c.rateLimiter.update(config) {
+		c.logger.Infof("Updated the rate limiter configuration to %+v", config)
+	}
+}
+
+// dynamicRateLimiter is a workqueue.RateLimiter like the one of
+// workqueue.DefaultControllerRateLimiter, that can be reconfigured while
+// in use.
+type dynamicRateLimiter struct {
+	m        sync.RWMutex
+	config   RateLimiterConfig
+	failures workqueue.RateLimiter
+	bucket   *rate.Limiter
+}
+
+var _ workqueue.RateLimiter = (*dynamicRateLimiter)(nil)
+
+func newDynamicRateLimiter(config RateLimiterConfig) *dynamicRateLimiter {
+	return &dynamicRateLimiter{
+		config:   config,
+		failures: workqueue.NewItemExponentialFailureRateLimiter(config.BaseDelay, config.MaxDelay),
+		bucket:   rate.NewLimiter(rate.Limit(config.QPS), config.Burst),
+	}
+}
+
+// update applies the config, returning whether it changed anything.
+// Changing the delays resets the backoff of every key.
+func (d *dynamicRateLimiter) update(config RateLimiterConfig) bool {
+	d.m.Lock()
+	defer d.m.Unlock()
+	if config == d.config {
+		return false
+	}
+	if config.BaseDelay != d.config.BaseDelay || config.MaxDelay != d.config.MaxDelay {
+		d.failures = workqueue.NewItemExponentialFailureRateLimiter(config.BaseDelay, config.MaxDelay)
+	}
+	if config.Burst != d.config.Burst {
+		d.bucket = rate.NewLimiter(rate.Limit(config.QPS), config.Burst)
+	} else {
+		d.bucket.SetLimit(rate.Limit(config.QPS))
+	}
+	d.config = config
+	return true
+}
+
+// When implements workqueue.RateLimiter
+func (d *dynamicRateLimiter) When(item interface{}) time.Duration {
+	d.m.RLock()
+	defer d.m.RUnlock()
+	delay := d.failures.When(item)
+	if bucket := d.bucket.Reserve().Delay(); bucket > delay {
+		delay = bucket
+	}
+	return delay
+}
+
+// Forget implements workqueue.RateLimiter
+func (d *dynamicRateLimiter) Forget(item interface{}) {
+	d.m.RLock()
+	defer d.m.RUnlock()
+	d.failures.Forget(item)
+}
+
+// NumRequeues implements workqueue.RateLimiter
+func (d *dynamicRateLimiter) NumRequeues(item interface{}) int {
+	d.m.RLock()
+	defer d.m.RUnlock()
+	return d.failures.NumRequeues(item)
+}
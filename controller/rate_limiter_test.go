@@ -0,0 +1,175 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+)
+
+func TestNewRateLimiterConfigFromMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		want    RateLimiterConfig
+		wantErr bool
+	}{{
+		name: "defaults",
+		want: DefaultRateLimiterConfig(),
+	}, {
+		name: "all controllers",
+		data: map[string]string{
+			"rate-limiter.base-delay": "10ms",
+			"rate-limiter.max-delay":  "1m",
+			"rate-limiter.qps":        "2.5",
+			"rate-limiter.burst":      "5",
+		},
+		want: RateLimiterConfig{
+			BaseDelay: 10 * time.Millisecond,
+			MaxDelay:  time.Minute,
+			QPS:       2.5,
+			Burst:     5,
+		},
+	}, {
+		name: "this controller takes precedence",
+		data: map[string]string{
+			"rate-limiter.qps":         "2.5",
+			"Testing.rate-limiter.qps": "50",
+			"Other.rate-limiter.burst": "1",
+		},
+		want: RateLimiterConfig{
+			BaseDelay: 5 * time.Millisecond,
+			MaxDelay:  1000 * time.Second,
+			QPS:       50,
+			Burst:     100,
+		},
+	}, {
+		name:    "bad duration",
+		data:    map[string]string{"rate-limiter.base-delay": "soon"},
+		wantErr: true,
+	}, {
+		name:    "bad qps",
+		data:    map[string]string{"rate-limiter.qps": "lots"},
+		wantErr: true,
+	}, {
+		name:    "bad burst",
+		data:    map[string]string{"rate-limiter.burst": "1.5"},
+		wantErr: true,
+	}, {
+		name:    "max less than base",
+		data:    map[string]string{"rate-limiter.max-delay": "1ms"},
+		wantErr: true,
+	}, {
+		name:    "zero qps",
+		data:    map[string]string{"rate-limiter.qps": "0"},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := NewRateLimiterConfigFromMap("Testing", test.data)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("NewRateLimiterConfigFromMap() = %v, wanted error: %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("NewRateLimiterConfigFromMap (-want, +got) = %s", diff)
+			}
+		})
+	}
+}
+
+func TestUpdateRateLimiterFromConfigMap(t *testing.T) {
+	impl := NewImplWithStats(&NopReconciler{}, TestLogger(t), "Testing", &FakeStatsReporter{})
+	defer impl.WorkQueue.ShutDown()
+
+	if got, want := impl.rateLimiter.When("a"), 5*time.Millisecond; got != want {
+		t.Errorf("When() = %v, wanted %v", got, want)
+	}
+
+	impl.UpdateRateLimiterFromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"Testing.rate-limiter.base-delay": "1s",
+		},
+	})
+	// Changing the delays resets the backoff.
+	if got, want := impl.rateLimiter.When("a"), time.Second; got != want {
+		t.Errorf("When() = %v, wanted %v", got, want)
+	}
+	if got, want := impl.rateLimiter.When("a"), 2*time.Second; got != want {
+		t.Errorf("When() = %v, wanted %v", got, want)
+	}
+
+	// Bad configurations are ignored.
+	impl.UpdateRateLimiterFromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"rate-limiter.base-delay": "never",
+		},
+	})
+	if got, want := impl.rateLimiter.When("a"), 4*time.Second; got != want {
+		t.Errorf("When() = %v, wanted %v", got, want)
+	}
+
+	// Changing only the rate keeps the backoff.
+	impl.UpdateRateLimiterFromConfigMap(&corev1.ConfigMap{
+		Data: map[string]string{
+			"Testing.rate-limiter.base-delay": "1s",
+			"rate-limiter.qps":                "100",
+		},
+	})
+	if got, want := impl.rateLimiter.When("a"), 8*time.Second; got != want {
+		t.Errorf("When() = %v, wanted %v", got, want)
+	}
+	if got, want := impl.WorkQueue.NumRequeues("a"), 4; got != want {
+		t.Errorf("NumRequeues() = %v, wanted %v", got, want)
+	}
+}
+
+func TestDynamicRateLimiterBucket(t *testing.T) {
+	rl := newDynamicRateLimiter(RateLimiterConfig{
+		BaseDelay: time.Nanosecond,
+		MaxDelay:  time.Nanosecond,
+		QPS:       1,
+		Burst:     1,
+	})
+
+	// The first retry uses up the burst, the next waits on the bucket.
+	rl.When("a")
+	if got := rl.When("b"); got < 500*time.Millisecond {
+		t.Errorf("When() = %v, wanted about a second", got)
+	}
+
+	if !rl.update(RateLimiterConfig{
+		BaseDelay: time.Nanosecond,
+		MaxDelay:  time.Nanosecond,
+		QPS:       1,
+		Burst:     10,
+	}) {
+		t.Error("update() = false, wanted true")
+	}
+	if got, want := rl.When("c"), time.Nanosecond; got != want {
+		t.Errorf("When() = %v, wanted %v", got, want)
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Snapshot holds the keys a controller has yet to process, so that they
+// can be handed over to another replica, e.g. when it loses leadership.
+type Snapshot struct {
+	Keys []SnapshotKey `json:"keys,omitempty"`
+}
+
+// SnapshotKey is a single key of a Snapshot.
+type SnapshotKey struct {
+	// Key is the namespace/name string of the key.
+	Key string `json:"key"`
+
+	// Delay is how long until the key was due to be processed, for keys
+	// with a pending delayed enqueue.
+	Delay string `json:"delay,omitempty"`
+
+	// Lane is the lane of the PriorityQueue the key was enqueued onto.
+	Lane int `json:"lane,omitempty"`
+}
+
+// Snapshot returns the keys that are waiting in the work queue, waiting
+// on a delayed enqueue, being retried or being reconciled.  Keys requeued
+// by the work queue itself are only known once they are handed out.
+func (c *Impl) Snapshot() Snapshot {
+	keys := make(map[types.NamespacedName]SnapshotKey)
+	now := time.Now()
+
+	c.delayedMu.Lock()
+	for key, d := range c.delayed {
+		keys[key] = SnapshotKey{
+			Key:   safeKey(key),
+			Delay: d.at.Sub(now).String(),
+			Lane:  d.lane,
+		}
+	}
+	for key, d := range c.coalesced {
+		keys[key] = SnapshotKey{Key: safeKey(key), Lane: d.lane}
+	}
+	c.delayedMu.Unlock()
+
+	// Keys that are due now take precedence over delayed ones.
+	c.keys.m.Lock()
+	for _, m := range []map[types.NamespacedName]time.Time{c.keys.queued, c.keys.inFlight} {
+		for key := range m {
+			keys[key] = SnapshotKey{Key: safeKey(key)}
+		}
+	}
+	for key := range c.keys.retrying {
+		keys[key] = SnapshotKey{Key: safeKey(key)}
+	}
+	c.keys.m.Unlock()
+
+	snapshot := Snapshot{}
+	for _, key := range keys {
+		snapshot.Keys = append(snapshot.Keys, key)
+	}
+	sort.Slice(snapshot.Keys, func(i, j int) bool {
+		return snapshot.Keys[i].Key < snapshot.Keys[j].Key
+	})
+	return snapshot
+}
+
+// Restore enqueues the keys of the Snapshot, with their remaining delays.
+// Keys that were being retried are enqueued right away, as their backoff
+// does not carry over.  Invalid keys are skipped, returning an error that
+// describes them.
+func (c *Impl) Restore(snapshot Snapshot) error {
+	var errs []string
+	for _, sk := range snapshot.Keys {
+		namespace, name, err := cache.SplitMetaNamespaceKey(sk.Key)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		var delay time.Duration
+		if sk.Delay != "" {
+			if delay, err = time.ParseDuration(sk.Delay); err != nil {
+				errs = append(errs, fmt.Sprintf("invalid delay for key %q: %v", sk.Key, err))
+				continue
+			}
+		}
+		c.EnqueueKeyAfterWithPriority(types.NamespacedName{Namespace: namespace, Name: name}, delay, sk.Lane)
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("failed to restore %d keys: %v", len(errs), errs)
+	}
+	return nil
+}
+
+// SaveSnapshotToConfigMap stores the Snapshot of the controller in the
+// ConfigMap, under the name of its work queue.  Writing the ConfigMap back
+// to the API server is left to the caller.
+func (c *Impl) SaveSnapshotToConfigMap(configMap *corev1.ConfigMap) error {
+	b, err := json.Marshal(c.Snapshot())
+	if err != nil {
+		return err
+	}
+	if configMap.Data == nil {
+		configMap.Data = make(map[string]string, 1)
+	}
+	configMap.Data[c.name] = string(b)
+	return nil
+}
+
+// RestoreFromConfigMap restores the Snapshot of the controller stored in
+// the ConfigMap by SaveSnapshotToConfigMap, if there is one.
+func (c *Impl) RestoreFromConfigMap(configMap *corev1.ConfigMap) error {
+	raw, ok := configMap.Data[c.name]
+	if !ok {
+		return nil
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal([]byte(raw), &snapshot); err != nil {
+		return fmt.Errorf("failed to parse snapshot of %s: %v", c.name, err)
+	}
+	return c.Restore(snapshot)
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	old := NewImplWithLanes(&NopReconciler{}, TestLogger(t), "Testing", &FakeStatsReporter{},
+		Lane{}, Lane{})
+	defer old.WorkQueue.ShutDown()
+
+	old.EnqueueKey(types.NamespacedName{Namespace: "foo", Name: "bar"})
+	old.EnqueueKeyAfterWithPriority(types.NamespacedName{Namespace: "foo", Name: "baz"}, time.Hour, 1)
+	old.EnqueueKey(types.NamespacedName{Name: "cluster-scoped"})
+	// Due now takes precedence over the pending delayed enqueue.
+	old.EnqueueKeyAfter(types.NamespacedName{Name: "cluster-scoped"}, time.Hour)
+
+	cm := &corev1.ConfigMap{}
+	if err := old.SaveSnapshotToConfigMap(cm); err != nil {
+		t.Fatalf("SaveSnapshotToConfigMap() = %v", err)
+	}
+	if _, ok := cm.Data["Testing"]; !ok {
+		t.Fatalf("ConfigMap data = %v, wanted a snapshot under Testing", cm.Data)
+	}
+
+	impl := NewImplWithLanes(&NopReconciler{}, TestLogger(t), "Testing", &FakeStatsReporter{},
+		Lane{}, Lane{})
+	defer impl.WorkQueue.ShutDown()
+	if err := impl.RestoreFromConfigMap(cm); err != nil {
+		t.Fatalf("RestoreFromConfigMap() = %v", err)
+	}
+
+	if got, want := impl.WorkQueue.Len(), 2; got != want {
+		t.Errorf("|Queue| = %d, wanted %d", got, want)
+	}
+	impl.delayedMu.Lock()
+	d, ok := impl.delayed[types.NamespacedName{Namespace: "foo", Name: "baz"}]
+	impl.delayedMu.Unlock()
+	if !ok {
+		t.Fatal("foo/baz was not restored as a delayed key")
+	}
+	if d.lane != 1 {
+		t.Errorf("lane = %d, wanted 1", d.lane)
+	}
+	if left := time.Until(d.at); left <= 59*time.Minute || left > time.Hour {
+		t.Errorf("foo/baz is due in %v, wanted about an hour", left)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	impl := NewImplWithStats(&NopReconciler{}, TestLogger(t), "Testing", &FakeStatsReporter{})
+	defer impl.WorkQueue.ShutDown()
+
+	impl.EnqueueKey(types.NamespacedName{Namespace: "foo", Name: "bar"})
+	impl.keys.started(types.NamespacedName{Namespace: "foo", Name: "in-flight"})
+	impl.keys.finished(types.NamespacedName{Namespace: "foo", Name: "retrying"}, true)
+
+	want := Snapshot{
+		Keys: []SnapshotKey{{
+			Key: "foo/bar",
+		}, {
+			Key: "foo/in-flight",
+		}, {
+			Key: "foo/retrying",
+		}},
+	}
+	if diff := cmp.Diff(want, impl.Snapshot()); diff != "" {
+		t.Errorf("Snapshot (-want, +got) = %s", diff)
+	}
+}
+
+func TestRestoreErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cm   *corev1.ConfigMap
+	}{{
+		name: "bad json",
+		cm: &corev1.ConfigMap{Data: map[string]string{
+			"Testing": "{",
+		}},
+	}, {
+		name: "bad key",
+		cm: &corev1.ConfigMap{Data: map[string]string{
+			"Testing": `{"keys":[{"key":"a/b/c"}]}`,
+		}},
+	}, {
+		name: "bad delay",
+		cm: &corev1.ConfigMap{Data: map[string]string{
+			"Testing": `{"keys":[{"key":"a/b","delay":"soon"}]}`,
+		}},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			impl := NewImplWithStats(&NopReconciler{}, TestLogger(t), "Testing", &FakeStatsReporter{})
+			defer impl.WorkQueue.ShutDown()
+			if err := impl.RestoreFromConfigMap(test.cm); err == nil {
+				t.Error("RestoreFromConfigMap() = nil, wanted an error")
+			}
+		})
+	}
+}
+
+func TestRestoreNoSnapshot(t *testing.T) {
+	impl := NewImplWithStats(&NopReconciler{}, TestLogger(t), "Testing", &FakeStatsReporter{})
+	defer impl.WorkQueue.ShutDown()
+	if err := impl.RestoreFromConfigMap(&corev1.ConfigMap{}); err != nil {
+		t.Errorf("RestoreFromConfigMap() = %v", err)
+	}
+	if got := impl.WorkQueue.Len(); got != 0 {
+		t.Errorf("|Queue| = %d, wanted 0", got)
+	}
+}
@@ -35,6 +35,10 @@ var (
 	workQueueDepthStat   = stats.Int64("work_queue_depth", "Depth of the work queue", stats.UnitNone)
 	reconcileCountStat   = stats.Int64("reconcile_count", "Number of reconcile operations", stats.UnitNone)
 	reconcileLatencyStat = stats.Int64("reconcile_latency", "Latency of reconcile operations", stats.UnitMilliseconds)
+	parkedKeyCountStat   = stats.Int64("parked_key_count", "Number of keys parked after failing repeatedly", stats.UnitNone)
+	droppedKeyCountStat  = stats.Int64("dropped_key_count", "Number of queued keys dropped when shutting down", stats.UnitNone)
+	outcomeCountStat     = stats.Int64("reconcile_outcome_count", "Number of reconcile operations by outcome and reason", stats.UnitNone)
+	staleInformerStat    = stats.Int64("stale_informer_count", "Number of informers that have made no progress for their window", stats.UnitNone)
 
 	// reconcileDistribution defines the bucket boundaries for the histogram of reconcile latency metric.
 	// Bucket boundaries are 10ms, 100ms, 1s, 10s, 30s and 60s.
@@ -48,6 +52,8 @@ var (
 	reconcilerTagKey = tag.MustNewKey("reconciler")
 	keyTagKey        = tag.MustNewKey("key")
 	successTagKey    = tag.MustNewKey("success")
+	outcomeTagKey    = tag.MustNewKey("outcome")
+	reasonTagKey     = tag.MustNewKey("reason")
 )
 
 func init() {
@@ -173,6 +179,26 @@ func init() {
 		Measure:     reconcileLatencyStat,
 		Aggregation: reconcileDistribution,
 		TagKeys:     []tag.Key{reconcilerTagKey, keyTagKey, successTagKey},
+	}, {
+		Description: "Number of keys parked after failing repeatedly",
+		Measure:     parkedKeyCountStat,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{reconcilerTagKey},
+	}, {
+		Description: "Number of queued keys dropped when shutting down",
+		Measure:     droppedKeyCountStat,
+		Aggregation: view.Sum(),
+		TagKeys:     []tag.Key{reconcilerTagKey},
+	}, {
+		Description: "Number of reconcile operations by outcome and reason",
+		Measure:     outcomeCountStat,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{reconcilerTagKey, outcomeTagKey, reasonTagKey},
+	}, {
+		Description: "Number of informers that have made no progress for their window",
+		Measure:     staleInformerStat,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{reconcilerTagKey},
 	}}
 	for _, view := range wp.DefaultViews() {
 		views = append(views, view)
@@ -201,6 +227,35 @@ type StatsReporter interface {
 	ReportReconcile(duration time.Duration, key, success string) error
 }
 
+// ParkingLotReporter is implemented by the StatsReporters that also
+// report the number of keys parked by the controller's ParkingLot.
+type ParkingLotReporter interface {
+	// ReportParkedKeys reports the parked key count metric
+	ReportParkedKeys(v int64) error
+}
+
+// DrainReporter is implemented by the StatsReporters that also report the
+// number of queued keys dropped when the controller's DrainTimeout expires.
+type DrainReporter interface {
+	// ReportDroppedKeys reports the dropped key count metric
+	ReportDroppedKeys(v int64) error
+}
+
+// OutcomeReporter is implemented by the StatsReporters that also report
+// the outcomes of reconciles, broken down by reason.
+type OutcomeReporter interface {
+	// ReportReconcileOutcome reports the count metric for the outcome of
+	// a reconcile operation and the reason for it
+	ReportReconcileOutcome(outcome, reason string) error
+}
+
+// WatchdogReporter is implemented by the StatsReporters that also report
+// the number of stale informers found by the watchdog.
+type WatchdogReporter interface {
+	// ReportStaleInformers reports the stale informer count metric
+	ReportStaleInformers(v int64) error
+}
+
 // Reporter holds cached metric objects to report metrics
 type reporter struct {
 	reconciler string
@@ -254,3 +309,47 @@ func (r *reporter) ReportReconcile(duration time.Duration, key, success string)
 	metrics.Record(ctx, reconcileLatencyStat.M(int64(duration/time.Millisecond)))
 	return nil
 }
+
+// ReportParkedKeys reports the parked key count metric
+func (r *reporter) ReportParkedKeys(v int64) error {
+	if r.globalCtx == nil {
+		return errors.New("reporter is not initialized correctly")
+	}
+	metrics.Record(r.globalCtx, parkedKeyCountStat.M(v))
+	return nil
+}
+
+// ReportDroppedKeys reports the dropped key count metric
+func (r *reporter) ReportDroppedKeys(v int64) error {
+	if r.globalCtx == nil {
+		return errors.New("reporter is not initialized correctly")
+	}
+	metrics.Record(r.globalCtx, droppedKeyCountStat.M(v))
+	return nil
+}
+
+// ReportReconcileOutcome reports the count metric for the outcome of a
+// reconcile operation and the reason for it
+func (r *reporter) ReportReconcileOutcome(outcome, reason string) error {
+	if r.globalCtx == nil {
+		return errors.New("reporter is not initialized correctly")
+	}
+	ctx, err := tag.New(
+		r.globalCtx,
+		tag.Insert(outcomeTagKey, outcome),
+		tag.Insert(reasonTagKey, reason))
+	if err != nil {
+		return err
+	}
+	metrics.Record(ctx, outcomeCountStat.M(1))
+	return nil
+}
+
+// ReportStaleInformers reports the stale informer count metric
+func (r *reporter) ReportStaleInformers(v int64) error {
+	if r.globalCtx == nil {
+		return errors.New("reporter is not initialized correctly")
+	}
+	metrics.Record(r.globalCtx, staleInformerStat.M(v))
+	return nil
+}
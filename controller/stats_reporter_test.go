@@ -62,6 +62,77 @@ func TestReportQueueDepth(t *testing.T) {
 	checkLastValueData(t, "work_queue_depth", wantTags, 3)
 }
 
+func TestReportParkedKeys(t *testing.T) {
+	r1 := &reporter{}
+	if err := r1.ReportParkedKeys(1); err == nil {
+		t.Error("Reporter.Report() expected an error for Report call before init. Got success.")
+	}
+
+	r, _ := NewStatsReporter("testreconciler")
+	wantTags := map[string]string{
+		"reconciler": "testreconciler",
+	}
+
+	pr := r.(ParkingLotReporter)
+	expectSuccess(t, func() error { return pr.ReportParkedKeys(2) })
+	expectSuccess(t, func() error { return pr.ReportParkedKeys(1) })
+	checkLastValueData(t, "parked_key_count", wantTags, 1)
+}
+
+func TestReportDroppedKeys(t *testing.T) {
+	r1 := &reporter{}
+	if err := r1.ReportDroppedKeys(1); err == nil {
+		t.Error("Reporter.Report() expected an error for Report call before init. Got success.")
+	}
+
+	r, _ := NewStatsReporter("testreconciler")
+	wantTags := map[string]string{
+		"reconciler": "testreconciler",
+	}
+
+	dr := r.(DrainReporter)
+	expectSuccess(t, func() error { return dr.ReportDroppedKeys(2) })
+	expectSuccess(t, func() error { return dr.ReportDroppedKeys(1) })
+	checkSumData(t, "dropped_key_count", wantTags, 3)
+}
+
+func TestReportStaleInformers(t *testing.T) {
+	r1 := &reporter{}
+	if err := r1.ReportStaleInformers(1); err == nil {
+		t.Error("Reporter.Report() expected an error for Report call before init. Got success.")
+	}
+
+	r, _ := NewStatsReporter("testreconciler")
+	wantTags := map[string]string{
+		"reconciler": "testreconciler",
+	}
+
+	wr := r.(WatchdogReporter)
+	expectSuccess(t, func() error { return wr.ReportStaleInformers(2) })
+	checkLastValueData(t, "stale_informer_count", wantTags, 2)
+	expectSuccess(t, func() error { return wr.ReportStaleInformers(0) })
+	checkLastValueData(t, "stale_informer_count", wantTags, 0)
+}
+
+func TestReportReconcileOutcome(t *testing.T) {
+	r1 := &reporter{}
+	if err := r1.ReportReconcileOutcome(OutcomeSuccess, "skipped"); err == nil {
+		t.Error("Reporter.Report() expected an error for Report call before init. Got success.")
+	}
+
+	r, _ := NewStatsReporter("testreconciler")
+	wantTags := map[string]string{
+		"reconciler": "testreconciler",
+		"outcome":    OutcomeSuccess,
+		"reason":     "skipped",
+	}
+
+	or := r.(OutcomeReporter)
+	expectSuccess(t, func() error { return or.ReportReconcileOutcome(OutcomeSuccess, "skipped") })
+	expectSuccess(t, func() error { return or.ReportReconcileOutcome(OutcomeSuccess, "skipped") })
+	checkCountData(t, "reconcile_outcome_count", wantTags, 2)
+}
+
 func TestReportReconcile(t *testing.T) {
 	r, _ := NewStatsReporter("testreconciler")
 	wantTags := map[string]string{
@@ -122,6 +193,21 @@ func checkCountData(t *testing.T, name string, wantTags map[string]string, wantV
 	}
 }
 
+func checkSumData(t *testing.T, name string, wantTags map[string]string, wantValue float64) {
+	t.Helper()
+	row := checkRow(t, name)
+	if row == nil {
+		return
+	}
+
+	checkTags(t, wantTags, row)
+	if s, ok := row.Data.(*view.SumData); !ok {
+		t.Error("Reporter.Report() expected a SumData type")
+	} else if s.Value != wantValue {
+		t.Errorf("Reporter.Report() expected %v got %v. metric: %v", wantValue, s.Value, name)
+	}
+}
+
 func checkDistributionData(t *testing.T, name string, wantTags map[string]string, wantValue float64) {
 	t.Helper()
 	row := checkRow(t, name)
@@ -25,6 +25,10 @@ import (
 type FakeStatsReporter struct {
 	queueDepths   []int64
 	reconcileData []FakeReconcileStatData
+	parkedKeys    []int64
+	droppedKeys   []int64
+	outcomes      []FakeReconcileOutcomeData
+	staleInfs     []int64
 	Lock          sync.Mutex
 }
 
@@ -34,6 +38,12 @@ type FakeReconcileStatData struct {
 	Key, Success string
 }
 
+// FakeReconcileOutcomeData is used to record the calls to
+// ReportReconcileOutcome
+type FakeReconcileOutcomeData struct {
+	Outcome, Reason string
+}
+
 // ReportQueueDepth records the call and returns success.
 func (r *FakeStatsReporter) ReportQueueDepth(v int64) error {
 	r.Lock.Lock()
@@ -50,6 +60,38 @@ func (r *FakeStatsReporter) ReportReconcile(duration time.Duration, key, success
 	return nil
 }
 
+// ReportParkedKeys records the call and returns success.
+func (r *FakeStatsReporter) ReportParkedKeys(v int64) error {
+	r.Lock.Lock()
+	defer r.Lock.Unlock()
+	r.parkedKeys = append(r.parkedKeys, v)
+	return nil
+}
+
+// ReportDroppedKeys records the call and returns success.
+func (r *FakeStatsReporter) ReportDroppedKeys(v int64) error {
+	r.Lock.Lock()
+	defer r.Lock.Unlock()
+	r.droppedKeys = append(r.droppedKeys, v)
+	return nil
+}
+
+// ReportReconcileOutcome records the call and returns success.
+func (r *FakeStatsReporter) ReportReconcileOutcome(outcome, reason string) error {
+	r.Lock.Lock()
+	defer r.Lock.Unlock()
+	r.outcomes = append(r.outcomes, FakeReconcileOutcomeData{outcome, reason})
+	return nil
+}
+
+// ReportStaleInformers records the call and returns success.
+func (r *FakeStatsReporter) ReportStaleInformers(v int64) error {
+	r.Lock.Lock()
+	defer r.Lock.Unlock()
+	r.staleInfs = append(r.staleInfs, v)
+	return nil
+}
+
 // GetQueueDepths returns the recorded queue depth values
 func (r *FakeStatsReporter) GetQueueDepths() []int64 {
 	r.Lock.Lock()
@@ -63,3 +105,31 @@ func (r *FakeStatsReporter) GetReconcileData() []FakeReconcileStatData {
 	defer r.Lock.Unlock()
 	return r.reconcileData
 }
+
+// GetParkedKeys returns the recorded parked key counts
+func (r *FakeStatsReporter) GetParkedKeys() []int64 {
+	r.Lock.Lock()
+	defer r.Lock.Unlock()
+	return r.parkedKeys
+}
+
+// GetDroppedKeys returns the recorded dropped key counts
+func (r *FakeStatsReporter) GetDroppedKeys() []int64 {
+	r.Lock.Lock()
+	defer r.Lock.Unlock()
+	return r.droppedKeys
+}
+
+// GetReconcileOutcomes returns the recorded reconcile outcomes
+func (r *FakeStatsReporter) GetReconcileOutcomes() []FakeReconcileOutcomeData {
+	r.Lock.Lock()
+	defer r.Lock.Unlock()
+	return r.outcomes
+}
+
+// GetStaleInformers returns the recorded stale informer counts
+func (r *FakeStatsReporter) GetStaleInformers() []int64 {
+	r.Lock.Lock()
+	defer r.Lock.Unlock()
+	return r.staleInfs
+}
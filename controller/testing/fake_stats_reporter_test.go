@@ -26,7 +26,11 @@ import (
 	"knative.dev/pkg/controller"
 )
 
-var _ controller.StatsReporter = (*FakeStatsReporter)(nil)
+var (
+	_ controller.StatsReporter      = (*FakeStatsReporter)(nil)
+	_ controller.ParkingLotReporter = (*FakeStatsReporter)(nil)
+	_ controller.DrainReporter      = (*FakeStatsReporter)(nil)
+)
 
 func TestReportQueueDepth(t *testing.T) {
 	r := &FakeStatsReporter{}
@@ -43,3 +47,19 @@ func TestReportReconcile(t *testing.T) {
 		t.Errorf("reconcile data len: want: %v, got: %v", want, got)
 	}
 }
+
+func TestReportParkedKeys(t *testing.T) {
+	r := &FakeStatsReporter{}
+	r.ReportParkedKeys(1)
+	if diff := cmp.Diff(r.GetParkedKeys(), []int64{1}); diff != "" {
+		t.Errorf("parked keys: %v", diff)
+	}
+}
+
+func TestReportDroppedKeys(t *testing.T) {
+	r := &FakeStatsReporter{}
+	r.ReportDroppedKeys(3)
+	if diff := cmp.Diff(r.GetDroppedKeys(), []int64{3}); diff != "" {
+		t.Errorf("dropped keys: %v", diff)
+	}
+}
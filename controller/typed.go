@@ -0,0 +1,199 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opencensus.io/trace"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/util/workqueue"
+
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/logging/logkey"
+	"knative.dev/pkg/reconciler"
+)
+
+// KeyReconciler is like Reconciler, but is fed the keys of a TypedImpl
+// as they were enqueued, rather than as namespace/name strings.
+type KeyReconciler interface {
+	ReconcileKey(ctx context.Context, key interface{}) error
+}
+
+// TypedImpl is a controller whose work queue carries keys of any
+// comparable type (e.g. a GroupVersionKind and name, or the ID of an
+// external resource), so that controllers which are not keyed by
+// namespace/name don't have to encode and decode their keys as strings.
+// With Go 1.18 and later, NewTypedImpl constrains the type of the keys.
+//
+// Like Impl, it traces its reconciles and reports their outcomes.  Unlike
+// Impl, it doesn't park failing keys, hold keys while disabled, nor track
+// leadership.  Keys are logged and reported with fmt.Sprint.
+type TypedImpl struct {
+	// Reconciler is fed the keys from the WorkQueue.
+	Reconciler KeyReconciler
+
+	// name is the name of the spans of the reconciles.
+	name string
+
+	// WorkQueue is a rate limited work queue of the keys.
+	WorkQueue workqueue.RateLimitingInterface
+
+	// Sugared logger is easier to use but is not as performant as the
+	// raw logger. In performance critical paths, call logger.Desugar()
+	// and use the returned raw logger instead.
+	logger *zap.SugaredLogger
+
+	// StatsReporter is used to send common controller metrics.
+	statsReporter StatsReporter
+}
+
+// newTypedImpl instantiates a controller that feeds the keys enqueued on
+// its work queue to the KeyReconciler.
+func newTypedImpl(r KeyReconciler, logger *zap.SugaredLogger, workQueueName string, reporter StatsReporter) *TypedImpl {
+	return &TypedImpl{
+		Reconciler: r,
+		name:       workQueueName,
+		WorkQueue: workqueue.NewNamedRateLimitingQueue(
+			newDynamicRateLimiter(DefaultRateLimiterConfig()),
+			workQueueName,
+		),
+		logger:        logger,
+		statsReporter: reporter,
+	}
+}
+
+// EnqueueKey puts the key onto the work queue.  It must be comparable.
+func (c *TypedImpl) EnqueueKey(key interface{}) {
+	c.WorkQueue.Add(key)
+	c.logger.Debugf("Adding to queue %v (depth: %d)", key, c.WorkQueue.Len())
+}
+
+// EnqueueKeyAfter schedules the key onto the work queue after the delay.
+func (c *TypedImpl) EnqueueKeyAfter(key interface{}, delay time.Duration) {
+	c.WorkQueue.AddAfter(key, delay)
+	c.logger.Debugf("Adding to queue %v (delay: %v, depth: %d)", key, delay, c.WorkQueue.Len())
+}
+
+// Run starts the controller's worker threads, the number of which is threadiness.
+// It then blocks until stopCh is closed, at which point it shuts down its internal
+// work queue and waits for workers to finish processing their current work items.
+func (c *TypedImpl) Run(threadiness int, stopCh <-chan struct{}) error {
+	defer runtime.HandleCrash()
+	sg := sync.WaitGroup{}
+	defer sg.Wait()
+	defer c.WorkQueue.ShutDown()
+
+	logger := c.logger
+	logger.Info("Starting controller and workers")
+	for i := 0; i < threadiness; i++ {
+		sg.Add(1)
+		go func() {
+			defer sg.Done()
+			for c.processNextWorkItem() {
+			}
+		}()
+	}
+
+	logger.Info("Started workers")
+	<-stopCh
+	logger.Info("Shutting down workers")
+
+	return nil
+}
+
+// processNextWorkItem will read a single key off the workqueue and attempt
+// to process it, by calling ReconcileKey on our KeyReconciler.
+func (c *TypedImpl) processNextWorkItem() bool {
+	key, shutdown := c.WorkQueue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.WorkQueue.Done(key)
+	keyStr := fmt.Sprint(key)
+
+	startTime := time.Now()
+	c.statsReporter.ReportQueueDepth(int64(c.WorkQueue.Len()))
+
+	ctx, span := trace.StartSpan(context.TODO(), "reconcile/"+c.name)
+	defer span.End()
+	span.AddAttributes(
+		trace.StringAttribute("reconciler", c.name),
+		trace.StringAttribute("key", keyStr),
+		trace.Int64Attribute("attempt", int64(c.WorkQueue.NumRequeues(key)+1)))
+
+	// Let the KeyReconciler say why it reconciled the way it did.
+	ctx, reason := withReason(ctx)
+
+	var err error
+	defer func() {
+		status := trueString
+		outcome := OutcomeSuccess
+		if err != nil {
+			status = falseString
+			outcome = OutcomeError
+			if IsPermanentError(err) {
+				outcome = OutcomePermanentError
+			}
+			span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: err.Error()})
+		}
+		if *reason == "" {
+			*reason = outcome
+		}
+		span.AddAttributes(
+			trace.StringAttribute("outcome", outcome),
+			trace.StringAttribute("reason", *reason))
+		c.statsReporter.ReportReconcile(time.Since(startTime), keyStr, status)
+		if or, ok := c.statsReporter.(OutcomeReporter); ok {
+			or.ReportReconcileOutcome(outcome, *reason)
+		}
+	}()
+
+	ctx = logging.WithTrace(logging.WithLogger(ctx, c.logger.With(zap.String(logkey.Key, keyStr))))
+	logger := logging.FromContext(ctx)
+
+	err = c.Reconciler.ReconcileKey(ctx, key)
+	if ok, delay := reconciler.IsRequeueKey(err); ok {
+		err = nil
+		if *reason == "" {
+			*reason = ReasonRequeue
+		}
+		c.WorkQueue.Forget(key)
+		c.WorkQueue.AddAfter(key, delay)
+		logger.Infof("Reconcile requeued (delay: %v). Time taken: %v.", delay, time.Since(startTime))
+		return true
+	}
+	if err != nil {
+		logger.Errorw("Reconcile error", zap.Error(err))
+		// Re-queue the key if it's a transient error.
+		if !IsPermanentError(err) && !c.WorkQueue.ShuttingDown() {
+			c.WorkQueue.AddRateLimited(key)
+		} else {
+			c.WorkQueue.Forget(key)
+		}
+		logger.Infof("Reconcile failed. Time taken: %v.", time.Since(startTime))
+		return true
+	}
+
+	c.WorkQueue.Forget(key)
+	logger.Infof("Reconcile succeeded. Time taken: %v.", time.Since(startTime))
+	return true
+}
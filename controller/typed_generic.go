@@ -0,0 +1,64 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// TypedReconciler is like KeyReconciler, but is fed keys of type K.
+type TypedReconciler[K comparable] interface {
+	ReconcileKey(ctx context.Context, key K) error
+}
+
+// TypedImplOf is a TypedImpl whose keys are of type K.
+type TypedImplOf[K comparable] struct {
+	*TypedImpl
+}
+
+// NewTypedImpl instantiates a controller that feeds the keys of type K
+// enqueued on its work queue to the TypedReconciler.
+func NewTypedImpl[K comparable](r TypedReconciler[K], logger *zap.SugaredLogger, workQueueName string, reporter StatsReporter) *TypedImplOf[K] {
+	return &TypedImplOf[K]{
+		TypedImpl: newTypedImpl(typedKeyReconciler[K]{r}, logger, workQueueName, reporter),
+	}
+}
+
+// EnqueueKey puts the key onto the work queue.
+func (c *TypedImplOf[K]) EnqueueKey(key K) {
+	c.TypedImpl.EnqueueKey(key)
+}
+
+// EnqueueKeyAfter schedules the key onto the work queue after the delay.
+func (c *TypedImplOf[K]) EnqueueKeyAfter(key K, delay time.Duration) {
+	c.TypedImpl.EnqueueKeyAfter(key, delay)
+}
+
+// typedKeyReconciler adapts a TypedReconciler to a KeyReconciler.
+type typedKeyReconciler[K comparable] struct {
+	r TypedReconciler[K]
+}
+
+func (tr typedKeyReconciler[K]) ReconcileKey(ctx context.Context, key interface{}) error {
+	return tr.r.ReconcileKey(ctx, key.(K))
+}
@@ -0,0 +1,69 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+)
+
+type gvkNameRecorder struct {
+	keyRecorder
+}
+
+func (r *gvkNameRecorder) ReconcileKey(ctx context.Context, key gvkName) error {
+	return r.keyRecorder.ReconcileKey(ctx, key)
+}
+
+func TestNewTypedImpl(t *testing.T) {
+	r := &gvkNameRecorder{}
+	impl := NewTypedImpl[gvkName](r, TestLogger(t), "Testing", &FakeStatsReporter{})
+
+	foo := gvkName{
+		GroupVersionKind: schema.GroupVersionKind{Group: "pkg.knative.dev", Version: "v1", Kind: "Thing"},
+		Name:             "foo",
+	}
+	bar := gvkName{GroupVersionKind: foo.GroupVersionKind, Name: "bar"}
+	impl.EnqueueKey(foo)
+	impl.EnqueueKeyAfter(bar, 10*time.Millisecond)
+
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+	go func() {
+		defer close(doneCh)
+		impl.Run(1, stopCh)
+	}()
+	waitFor(t, "the keys to be reconciled", func() bool {
+		return len(r.recorded()) == 2
+	})
+	close(stopCh)
+	<-doneCh
+
+	if diff := cmp.Diff([]interface{}{foo, bar}, r.recorded()); diff != "" {
+		t.Errorf("Reconciled keys (-want, +got) = %s", diff)
+	}
+}
@@ -0,0 +1,31 @@
+//go:build !go1.18
+// +build !go1.18
+
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"go.uber.org/zap"
+)
+
+// NewTypedImpl instantiates a controller that feeds the keys enqueued on
+// its work queue to the KeyReconciler, which asserts their type.  With Go
+// 1.18 and later, NewTypedImpl takes the type of the keys instead.
+func NewTypedImpl(r KeyReconciler, logger *zap.SugaredLogger, workQueueName string, reporter StatsReporter) *TypedImpl {
+	return newTypedImpl(r, logger, workQueueName, reporter)
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+)
+
+// gvkName is an example of a key that is not a namespace/name.
+type gvkName struct {
+	schema.GroupVersionKind
+	Name string
+}
+
+type keyRecorder struct {
+	m    sync.Mutex
+	keys []interface{}
+	err  error
+}
+
+func (kr *keyRecorder) ReconcileKey(ctx context.Context, key interface{}) error {
+	kr.m.Lock()
+	defer kr.m.Unlock()
+	kr.keys = append(kr.keys, key)
+	return kr.err
+}
+
+func (kr *keyRecorder) recorded() []interface{} {
+	kr.m.Lock()
+	defer kr.m.Unlock()
+	return append([]interface{}(nil), kr.keys...)
+}
+
+func TestTypedImpl(t *testing.T) {
+	r := &keyRecorder{}
+	reporter := &FakeStatsReporter{}
+	impl := newTypedImpl(r, TestLogger(t), "Testing", reporter)
+
+	key := gvkName{
+		GroupVersionKind: schema.GroupVersionKind{Group: "pkg.knative.dev", Version: "v1", Kind: "Thing"},
+		Name:             "foo",
+	}
+	impl.EnqueueKey(key)
+	impl.EnqueueKey(key)
+	impl.EnqueueKeyAfter(42, 10*time.Millisecond)
+
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+	go func() {
+		defer close(doneCh)
+		impl.Run(1, stopCh)
+	}()
+	waitFor(t, "the keys to be reconciled", func() bool {
+		return len(r.recorded()) == 2
+	})
+	close(stopCh)
+	<-doneCh
+
+	if diff := cmp.Diff([]interface{}{key, 42}, r.recorded()); diff != "" {
+		t.Errorf("Reconciled keys (-want, +got) = %s", diff)
+	}
+	if got, want := reporter.GetReconcileData()[1].Key, "42"; got != want {
+		t.Errorf("Reported key = %q, wanted %q", got, want)
+	}
+}
+
+func TestTypedImplErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantRequeue bool
+		wantOutcome string
+	}{{
+		name:        "success",
+		wantOutcome: OutcomeSuccess,
+	}, {
+		name:        "transient error",
+		err:         errors.New("transient"),
+		wantRequeue: true,
+		wantOutcome: OutcomeError,
+	}, {
+		name:        "permanent error",
+		err:         NewPermanentError(errors.New("permanent")),
+		wantOutcome: OutcomePermanentError,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reporter := &FakeStatsReporter{}
+			impl := newTypedImpl(&keyRecorder{err: test.err}, TestLogger(t), "Testing", reporter)
+			defer impl.WorkQueue.ShutDown()
+			impl.EnqueueKey("key")
+			impl.processNextWorkItem()
+
+			if got := impl.WorkQueue.NumRequeues("key") > 0; got != test.wantRequeue {
+				t.Errorf("Requeued = %v, wanted %v", got, test.wantRequeue)
+			}
+			want := []FakeReconcileOutcomeData{{Outcome: test.wantOutcome, Reason: test.wantOutcome}}
+			if diff := cmp.Diff(want, reporter.GetReconcileOutcomes()); diff != "" {
+				t.Errorf("Reported outcomes (-want, +got) = %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/cache"
+)
+
+// DefaultWatchdogWindow is the window that WatchInformer uses when it is
+// given one that is not positive.
+const DefaultWatchdogWindow = 10 * time.Minute
+
+// informerWatch tracks the progress of an informer watched by the
+// watchdog of an Impl.
+type informerWatch struct {
+	name   string
+	si     cache.SharedInformer
+	window time.Duration
+
+	// These are guarded by the watchMu of the Impl.
+	version  string
+	events   bool
+	progress time.Time
+	stale    bool
+}
+
+// WatchInformer starts a watchdog that considers the informer stale when,
+// for the window, its LastSyncResourceVersion has not advanced and it has
+// delivered no events (resyncs do not count).  That is how silently dead
+// watches show: stale informers are logged, counted by the stale informer
+// metric and make CheckInformers fail, until they make progress again.
+// The watchdog stops when stopCh is closed, but the event handler that it
+// adds to the informer is not removed, as SharedInformers cannot remove
+// handlers; it is cheap, but watch each informer once.
+func (c *Impl) WatchInformer(name string, si cache.SharedInformer, window time.Duration, stopCh <-chan struct{}) {
+	if window <= 0 {
+		window = DefaultWatchdogWindow
+	}
+	// Check twice per window, but never with a zero period.
+	period := window / 2
+	if period == 0 {
+		period = window
+	}
+
+	w := &informerWatch{
+		name:     name,
+		si:       si,
+		window:   window,
+		version:  si.LastSyncResourceVersion(),
+		progress: time.Now(),
+	}
+	progressed := func() {
+		c.watchMu.Lock()
+		defer c.watchMu.Unlock()
+		w.events = true
+	}
+	si.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(interface{}) { progressed() },
+		UpdateFunc: func(old, new interface{}) {
+			// Resyncs replay the cache, so they say nothing of the watch.
+			if !isResync(old, new) {
+				progressed()
+			}
+		},
+		DeleteFunc: func(interface{}) { progressed() },
+	})
+
+	c.watchMu.Lock()
+	c.watches = append(c.watches, w)
+	c.watchMu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				c.watchMu.Lock()
+				defer c.watchMu.Unlock()
+				for i, other := range c.watches {
+					if other == w {
+						c.watches = append(c.watches[:i], c.watches[i+1:]...)
+						break
+					}
+				}
+				return
+			case now := <-ticker.C:
+				c.checkInformer(w, now)
+			}
+		}
+	}()
+}
+
+// checkInformer updates whether the informer is stale as of now.
+func (c *Impl) checkInformer(w *informerWatch, now time.Time) {
+	version := w.si.LastSyncResourceVersion()
+
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+	switch {
+	case version != w.version || w.events:
+		w.version, w.events, w.progress = version, false, now
+		if w.stale {
+			w.stale = false
+			c.logger.Infof("Informer %s is making progress again", w.name)
+		}
+	case !w.stale && now.Sub(w.progress) >= w.window:
+		w.stale = true
+		c.logger.Warnf("Informer %s has made no progress for %v, its watch may be dead",
+			w.name, now.Sub(w.progress))
+	default:
+		return
+	}
+
+	if r, ok := c.statsReporter.(WatchdogReporter); ok {
+		r.ReportStaleInformers(int64(len(c.staleInformersLocked())))
+	}
+}
+
+// CheckInformers returns an error naming the informers watched with
+// WatchInformer that are stale, if any are, for use in health checks.
+func (c *Impl) CheckInformers() error {
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+	if stale := c.staleInformersLocked(); len(stale) != 0 {
+		return fmt.Errorf("informers have made no progress: %v", stale)
+	}
+	return nil
+}
+
+func (c *Impl) staleInformersLocked() []string {
+	var stale []string
+	for _, w := range c.watches {
+		if w.stale {
+			stale = append(stale, w.name)
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+// isResync returns whether the update of the objects is an informer
+// resync, which delivers the cached object again.
+func isResync(old, new interface{}) bool {
+	oldObj, err := meta.Accessor(old)
+	if err != nil {
+		return false
+	}
+	newObj, err := meta.Accessor(new)
+	if err != nil {
+		return false
+	}
+	return oldObj.GetResourceVersion() == newObj.GetResourceVersion()
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	. "knative.dev/pkg/controller/testing"
+	. "knative.dev/pkg/logging/testing"
+	. "knative.dev/pkg/testing"
+)
+
+// progressInformer is a SharedInformer whose progress is driven by tests.
+type progressInformer struct {
+	cache.SharedInformer
+
+	m       sync.Mutex
+	version string
+	handler cache.ResourceEventHandler
+}
+
+func (pi *progressInformer) AddEventHandler(h cache.ResourceEventHandler) {
+	pi.handler = h
+}
+
+func (pi *progressInformer) LastSyncResourceVersion() string {
+	pi.m.Lock()
+	defer pi.m.Unlock()
+	return pi.version
+}
+
+func (pi *progressInformer) setVersion(v string) {
+	pi.m.Lock()
+	defer pi.m.Unlock()
+	pi.version = v
+}
+
+func withVersion(v string) *Resource {
+	return &Resource{ObjectMeta: metav1.ObjectMeta{Name: "foo", ResourceVersion: v}}
+}
+
+func TestInformerWatchdog(t *testing.T) {
+	const window = time.Minute
+	reporter := &FakeStatsReporter{}
+	impl := NewImplWithStats(&NopReconciler{}, TestLogger(t), "Testing", reporter)
+	defer impl.WorkQueue.ShutDown()
+
+	si := &progressInformer{version: "1"}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	impl.WatchInformer("resources", si, window, stopCh)
+	w := impl.watches[0]
+	start := w.progress
+
+	steps := []struct {
+		name      string
+		progress  func()
+		after     time.Duration
+		wantStale bool
+	}{{
+		name:  "within the window",
+		after: window / 2,
+	}, {
+		name:      "past the window",
+		after:     window,
+		wantStale: true,
+	}, {
+		name:      "resyncs do not count",
+		progress:  func() { si.handler.OnUpdate(withVersion("1"), withVersion("1")) },
+		after:     2 * window,
+		wantStale: true,
+	}, {
+		name:     "events count",
+		progress: func() { si.handler.OnUpdate(withVersion("1"), withVersion("2")) },
+		after:    3 * window,
+	}, {
+		name:      "stale again",
+		after:     4 * window,
+		wantStale: true,
+	}, {
+		name:     "resource version advanced",
+		progress: func() { si.setVersion("3") },
+		after:    5 * window,
+	}}
+
+	for _, step := range steps {
+		if step.progress != nil {
+			step.progress()
+		}
+		impl.checkInformer(w, start.Add(step.after))
+		if err := impl.CheckInformers(); (err != nil) != step.wantStale {
+			t.Errorf("%s: CheckInformers() = %v, wanted stale: %v", step.name, err, step.wantStale)
+		}
+	}
+
+	if diff := cmp.Diff([]int64{1, 0, 1, 0}, reporter.GetStaleInformers()); diff != "" {
+		t.Errorf("Stale informers (-want, +got) = %s", diff)
+	}
+}
+
+func TestInformerWatchdogStops(t *testing.T) {
+	impl := NewImplWithStats(&NopReconciler{}, TestLogger(t), "Testing", &FakeStatsReporter{})
+	defer impl.WorkQueue.ShutDown()
+
+	stopCh := make(chan struct{})
+	impl.WatchInformer("resources", &progressInformer{}, time.Hour, stopCh)
+	close(stopCh)
+
+	waitFor(t, "the watch to stop", func() bool {
+		impl.watchMu.Lock()
+		defer impl.watchMu.Unlock()
+		return len(impl.watches) == 0
+	})
+}
+
+func TestInformerWatchdogWindow(t *testing.T) {
+	for _, window := range []time.Duration{-time.Second, 0, time.Nanosecond} {
+		t.Run(window.String(), func(t *testing.T) {
+			impl := NewImplWithStats(&NopReconciler{}, TestLogger(t), "Testing", &FakeStatsReporter{})
+			defer impl.WorkQueue.ShutDown()
+
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+			impl.WatchInformer("resources", &progressInformer{}, window, stopCh)
+
+			impl.watchMu.Lock()
+			defer impl.watchMu.Unlock()
+			if got := impl.watches[0].window; got <= 0 {
+				t.Errorf("window = %v, wanted a positive one", got)
+			}
+		})
+	}
+}
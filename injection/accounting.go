@@ -0,0 +1,152 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injection
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
+
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/metrics"
+)
+
+var (
+	cacheObjectsStat = stats.Int64("informer_cache_objects", "Number of objects in the caches of informers", stats.UnitNone)
+	cacheBytesStat   = stats.Int64("informer_cache_bytes", "Approximate size of the objects in the caches of informers", stats.UnitBytes)
+
+	kindTagKey = tag.MustNewKey("kind")
+
+	// accountingViews are only registered once cache accounting is
+	// enabled, so that controllers without it don't export them.
+	accountingViews = []*view.View{{
+		Description: cacheObjectsStat.Description(),
+		Measure:     cacheObjectsStat,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{kindTagKey},
+	}, {
+		Description: cacheBytesStat.Description(),
+		Measure:     cacheBytesStat,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{kindTagKey},
+	}}
+	registerAccountingViews sync.Once
+)
+
+// accountingKey is used as the key for associating the period of the
+// cache accounting with a context.Context.
+type accountingKey struct{}
+
+// WithCacheAccounting makes StartInformers report the number of objects
+// in the caches of the informers it starts, and their approximate size,
+// by kind, every period, so that the memory of a controller can be
+// attributed to its caches.  Sizing serializes every cached object, so
+// the period of large caches should be minutes rather than seconds.
+func WithCacheAccounting(ctx context.Context, period time.Duration) context.Context {
+	return context.WithValue(ctx, accountingKey{}, period)
+}
+
+// accountCaches reports the usage of the caches of the informers every
+// period, until the context is done.
+func accountCaches(ctx context.Context, period time.Duration, informers func() []controller.Informer) {
+	registerAccountingViews.Do(func() {
+		if err := view.Register(accountingViews...); err != nil {
+			logging.FromContext(ctx).Errorw("Failed to register the cache accounting views", zap.Error(err))
+		}
+	})
+
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	var kinds map[string]struct{}
+	for {
+		kinds = reportCaches(ctx, informers(), kinds)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reportCaches reports the usage of the caches of the informers, by kind,
+// and returns the kinds reported.  Informers without a store are skipped.
+// When a kind of those previously reported is gone, the views are reset,
+// so that its rows don't linger with their last values.
+func reportCaches(ctx context.Context, informers []controller.Informer, previous map[string]struct{}) map[string]struct{} {
+	objects := make(map[string]int64)
+	bytes := make(map[string]int64)
+	for _, inf := range informers {
+		si, ok := inf.(cache.SharedInformer)
+		if !ok {
+			continue
+		}
+		for _, obj := range si.GetStore().List() {
+			kind := kindOf(obj)
+			objects[kind]++
+			if b, err := json.Marshal(obj); err == nil {
+				bytes[kind] += int64(len(b))
+			}
+		}
+	}
+
+	for kind := range previous {
+		if _, ok := objects[kind]; !ok {
+			view.Unregister(accountingViews...)
+			if err := view.Register(accountingViews...); err != nil {
+				logging.FromContext(ctx).Errorw("Failed to reset the cache accounting views", zap.Error(err))
+			}
+			break
+		}
+	}
+
+	kinds := make(map[string]struct{}, len(objects))
+	for kind, n := range objects {
+		kinds[kind] = struct{}{}
+		kindCtx, err := tag.New(ctx, tag.Insert(kindTagKey, kind))
+		if err != nil {
+			continue
+		}
+		metrics.Record(kindCtx, cacheObjectsStat.M(n))
+		metrics.Record(kindCtx, cacheBytesStat.M(bytes[kind]))
+	}
+	return kinds
+}
+
+// kindOf returns the group, version and kind of the object, as set on it
+// or as known to the Kubernetes scheme, or else its Go type.
+func kindOf(obj interface{}) string {
+	if ro, ok := obj.(runtime.Object); ok {
+		if gvk := ro.GetObjectKind().GroupVersionKind(); !gvk.Empty() {
+			return gvk.String()
+		}
+		if gvks, _, err := scheme.Scheme.ObjectKinds(ro); err == nil && len(gvks) != 0 {
+			return gvks[0].String()
+		}
+	}
+	return fmt.Sprintf("%T", obj)
+}
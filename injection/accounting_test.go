@@ -0,0 +1,100 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opencensus.io/stats/view"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+
+	"knative.dev/pkg/controller"
+)
+
+func informerWith(objs ...interface{}) cache.SharedIndexInformer {
+	inf := cache.NewSharedIndexInformer(&cache.ListWatch{}, nil, time.Hour, cache.Indexers{})
+	for _, obj := range objs {
+		inf.GetStore().Add(obj)
+	}
+	return inf
+}
+
+func lastValue(t *testing.T, name, kind string) (float64, bool) {
+	t.Helper()
+	rows, err := view.RetrieveData(name)
+	if err != nil {
+		t.Fatalf("RetrieveData(%s) = %v", name, err)
+	}
+	for _, row := range rows {
+		for _, tag := range row.Tags {
+			if tag.Key == kindTagKey && tag.Value == kind {
+				return row.Data.(*view.LastValueData).Value, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func TestReportCaches(t *testing.T) {
+	pod := func(name string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name}}
+	}
+	foo := &unstructured.Unstructured{}
+	foo.SetAPIVersion("test.knative.dev/v1")
+	foo.SetKind("Foo")
+	foo.SetNamespace("ns")
+	foo.SetName("foo")
+
+	if _, err := view.RetrieveData("informer_cache_objects"); err == nil {
+		t.Error("The cache accounting views are registered without cache accounting")
+	}
+	if err := view.Register(accountingViews...); err != nil {
+		t.Fatalf("Register() = %v", err)
+	}
+	defer view.Unregister(accountingViews...)
+
+	kinds := reportCaches(context.Background(), []controller.Informer{
+		informerWith(pod("a"), pod("b")),
+		informerWith(pod("c"), foo),
+		&runInformer{},
+	}, nil)
+
+	if got, ok := lastValue(t, "informer_cache_objects", "/v1, Kind=Pod"); !ok || got != 3 {
+		t.Errorf("Pod objects = %v, %v, wanted 3", got, ok)
+	}
+	if got, ok := lastValue(t, "informer_cache_objects", "test.knative.dev/v1, Kind=Foo"); !ok || got != 1 {
+		t.Errorf("Foo objects = %v, %v, wanted 1", got, ok)
+	}
+	if got, ok := lastValue(t, "informer_cache_bytes", "/v1, Kind=Pod"); !ok || got <= 0 {
+		t.Errorf("Pod bytes = %v, %v, wanted a positive size", got, ok)
+	}
+
+	// The pods are gone from the caches, so their rows should be too.
+	reportCaches(context.Background(), []controller.Informer{informerWith(foo)}, kinds)
+
+	if got, ok := lastValue(t, "informer_cache_objects", "/v1, Kind=Pod"); ok {
+		t.Errorf("Pod objects = %v, wanted no row", got)
+	}
+	if got, ok := lastValue(t, "informer_cache_objects", "test.knative.dev/v1, Kind=Foo"); !ok || got != 1 {
+		t.Errorf("Foo objects = %v, %v, wanted 1", got, ok)
+	}
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injection
+
+import (
+	"context"
+
+	"k8s.io/client-go/rest"
+)
+
+// clusterConfigsKey is the key that the configs of named clusters are
+// associated with on contexts returned by WithClusterConfig.
+type clusterConfigsKey struct{}
+
+// WithClusterConfig associates the rest.Config of a named cluster, such
+// as a management cluster besides the workload cluster the informers
+// are set up for, with the provided context.
+func WithClusterConfig(ctx context.Context, name string, cfg *rest.Config) context.Context {
+	old, _ := ctx.Value(clusterConfigsKey{}).(map[string]*rest.Config)
+	configs := make(map[string]*rest.Config, len(old)+1)
+	for k, v := range old {
+		configs[k] = v
+	}
+	configs[name] = cfg
+	return context.WithValue(ctx, clusterConfigsKey{}, configs)
+}
+
+// GetClusterConfig accesses the rest.Config of the named cluster
+// associated with the provided context, or nil if there is none.
+// Controllers build the clients of the cluster from it, e.g. with
+// ClientConfig to apply the options set up for their clients.
+func GetClusterConfig(ctx context.Context, name string) *rest.Config {
+	configs, _ := ctx.Value(clusterConfigsKey{}).(map[string]*rest.Config)
+	return configs[name]
+}
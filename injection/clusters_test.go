@@ -0,0 +1,47 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injection
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestClusterConfig(t *testing.T) {
+	ctx := context.Background()
+	if got := GetClusterConfig(ctx, "management"); got != nil {
+		t.Errorf("GetClusterConfig() = %v, wanted nil", got)
+	}
+
+	management := &rest.Config{Host: "https://management"}
+	workload := &rest.Config{Host: "https://workload"}
+	ctx1 := WithClusterConfig(ctx, "management", management)
+	ctx2 := WithClusterConfig(ctx1, "workload", workload)
+
+	if got := GetClusterConfig(ctx2, "management"); got != management {
+		t.Errorf("GetClusterConfig(management) = %v, wanted %v", got, management)
+	}
+	if got := GetClusterConfig(ctx2, "workload"); got != workload {
+		t.Errorf("GetClusterConfig(workload) = %v, wanted %v", got, workload)
+	}
+	// The parent context is unaffected.
+	if got := GetClusterConfig(ctx1, "workload"); got != nil {
+		t.Errorf("GetClusterConfig(workload) = %v on the parent context, wanted nil", got)
+	}
+}
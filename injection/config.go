@@ -0,0 +1,114 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injection
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+	"k8s.io/client-go/util/flowcontrol"
+
+	"knative.dev/pkg/changeset"
+)
+
+// RateLimits are the client-side rate limits of an injected client.
+type RateLimits struct {
+	// QPS and Burst configure the default token bucket rate limiter.
+	QPS   float32
+	Burst int
+
+	// RateLimiter, when set, replaces the token bucket rate limiter
+	// (e.g. with one shared by several clients), and QPS and Burst are
+	// ignored.
+	RateLimiter flowcontrol.RateLimiter
+}
+
+// rateLimitsKey is used as the key for associating the RateLimits of the
+// client whose context key is client with a context.Context.
+type rateLimitsKey struct {
+	client interface{}
+}
+
+// WithClientRateLimits overrides the rate limits of the rest.Config passed
+// to SetupInformers for the injected client whose context key is client
+// (e.g. kubeclient.Key{}), so that a chatty client doesn't eat into the
+// budget of the others.
+func WithClientRateLimits(ctx context.Context, client interface{}, rl RateLimits) context.Context {
+	return context.WithValue(ctx, rateLimitsKey{client: client}, rl)
+}
+
+// userAgentKey and wrapTransportKey are used as the keys for associating
+// the user agent and the transport wrappers of every injected client with
+// a context.Context.
+type userAgentKey struct{}
+type wrapTransportKey struct{}
+
+// UserAgent returns the standard user agent of the given version of the
+// component: "<component>/<version> (<os>/<arch>) <changeset>", where the
+// changeset is that of changeset.Get, or "unknown".
+func UserAgent(component, version string) string {
+	commit, err := changeset.Get()
+	if err != nil {
+		commit = "unknown"
+	}
+	return fmt.Sprintf("%s/%s (%s/%s) %s", component, version, runtime.GOOS, runtime.GOARCH, commit)
+}
+
+// WithUserAgent sets the user agent of every injected client created with
+// the context (e.g. to that of UserAgent), so that their requests can be
+// told apart in the audit logs and metrics of the API server.
+func WithUserAgent(ctx context.Context, userAgent string) context.Context {
+	return context.WithValue(ctx, userAgentKey{}, userAgent)
+}
+
+// WithWrapTransport adds a wrapper of the transport of every injected
+// client created with the context, e.g. for tracing, auditing or egress
+// proxies.  Wrappers wrap those added before them.
+func WithWrapTransport(ctx context.Context, wt transport.WrapperFunc) context.Context {
+	wts, _ := ctx.Value(wrapTransportKey{}).([]transport.WrapperFunc)
+	return context.WithValue(ctx, wrapTransportKey{}, append(wts[:len(wts):len(wts)], wt))
+}
+
+// ClientConfig returns the rest.Config with which to create the injected
+// client whose context key is client: cfg itself, or a copy of it with the
+// rate limits of WithClientRateLimits, the user agent of WithUserAgent and
+// the transport wrappers of WithWrapTransport.
+func ClientConfig(ctx context.Context, client interface{}, cfg *rest.Config) *rest.Config {
+	rl, hasRateLimits := ctx.Value(rateLimitsKey{client: client}).(RateLimits)
+	userAgent, hasUserAgent := ctx.Value(userAgentKey{}).(string)
+	wts, _ := ctx.Value(wrapTransportKey{}).([]transport.WrapperFunc)
+	if !hasRateLimits && !hasUserAgent && len(wts) == 0 {
+		return cfg
+	}
+
+	cfg = rest.CopyConfig(cfg)
+	if hasRateLimits {
+		cfg.QPS = rl.QPS
+		cfg.Burst = rl.Burst
+		cfg.RateLimiter = rl.RateLimiter
+	}
+	if hasUserAgent {
+		cfg.UserAgent = userAgent
+	}
+	for _, wt := range wts {
+		cfg.Wrap(wt)
+	}
+	return cfg
+}
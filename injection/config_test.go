@@ -0,0 +1,109 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injection
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+type fooKey struct{}
+
+type barKey struct{}
+
+func TestClientConfig(t *testing.T) {
+	cfg := &rest.Config{Host: "foo", QPS: 5, Burst: 10}
+	limiter := flowcontrol.NewFakeAlwaysRateLimiter()
+	ctx := WithClientRateLimits(context.Background(), fooKey{}, RateLimits{QPS: 50, Burst: 100})
+	ctx = WithClientRateLimits(ctx, barKey{}, RateLimits{RateLimiter: limiter})
+
+	if got := ClientConfig(ctx, struct{}{}, cfg); got != cfg {
+		t.Errorf("ClientConfig(other) = %v, wanted %v", got, cfg)
+	}
+
+	foo := ClientConfig(ctx, fooKey{}, cfg)
+	if foo.Host != "foo" || foo.QPS != 50 || foo.Burst != 100 || foo.RateLimiter != nil {
+		t.Errorf("ClientConfig(foo) = %v, wanted QPS 50 and Burst 100", foo)
+	}
+	if bar := ClientConfig(ctx, barKey{}, cfg); bar.RateLimiter != limiter {
+		t.Errorf("ClientConfig(bar).RateLimiter = %v, wanted %v", bar.RateLimiter, limiter)
+	}
+	if cfg.QPS != 5 || cfg.Burst != 10 {
+		t.Errorf("ClientConfig modified the passed config: %v", cfg)
+	}
+}
+
+// headerTransport sets a header to the name of the transport.
+type headerTransport struct {
+	name string
+	next http.RoundTripper
+}
+
+func (ht *headerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r.Header.Add("X-Wrapped-By", ht.name)
+	return ht.next.RoundTrip(r)
+}
+
+func wrapWith(name string) func(http.RoundTripper) http.RoundTripper {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return &headerTransport{name: name, next: rt}
+	}
+}
+
+func TestClientConfigUserAgentAndTransport(t *testing.T) {
+	cfg := &rest.Config{Host: "foo", UserAgent: "default"}
+	ctx := WithUserAgent(context.Background(), "controller/v1")
+	ctx = WithWrapTransport(ctx, wrapWith("inner"))
+	ctx = WithWrapTransport(ctx, wrapWith("outer"))
+
+	got := ClientConfig(ctx, fooKey{}, cfg)
+	if got.UserAgent != "controller/v1" {
+		t.Errorf("UserAgent = %q, wanted %q", got.UserAgent, "controller/v1")
+	}
+	if cfg.UserAgent != "default" || cfg.WrapTransport != nil {
+		t.Errorf("ClientConfig modified the passed config: %v", cfg)
+	}
+
+	// The outer wrapper sees the request first.
+	req, _ := http.NewRequest(http.MethodGet, "http://foo", nil)
+	rt := got.WrapTransport(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if got, want := strings.Join(r.Header["X-Wrapped-By"], ","), "outer,inner"; got != want {
+			t.Errorf("X-Wrapped-By = %q, wanted %q", got, want)
+		}
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	}))
+	if _, err := rt.RoundTrip(req); err != nil {
+		t.Errorf("RoundTrip() = %v", err)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestUserAgent(t *testing.T) {
+	if got, want := UserAgent("controller", "v1"), "controller/v1 ("; !strings.HasPrefix(got, want) {
+		t.Errorf("UserAgent() = %q, wanted prefix %q", got, want)
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injection
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// dependencyPollInterval is how often WaitForDependencies checks whether
+// the dependencies are ready.
+var dependencyPollInterval = time.Second
+
+// dependenciesKey is used as the key for associating the dependencies of
+// a controller with a context.Context.
+type dependenciesKey struct{}
+
+type dependency struct {
+	name    string
+	ready   HealthCheck
+	timeout time.Duration
+}
+
+type dependencies struct {
+	m    sync.Mutex
+	deps []dependency
+}
+
+// WithDependencies returns a context on which the controller constructed
+// with it can declare what it depends on with DependOn.
+func WithDependencies(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dependenciesKey{}, &dependencies{})
+}
+
+// DependOn declares that the controller constructed with the context must
+// not start until ready returns nil (e.g. once the certificates of a
+// webhook are ready, or a CRD is established), which it has timeout to
+// do.  It has no effect without WithDependencies.
+func DependOn(ctx context.Context, name string, ready HealthCheck, timeout time.Duration) {
+	if d, ok := ctx.Value(dependenciesKey{}).(*dependencies); ok {
+		d.m.Lock()
+		defer d.m.Unlock()
+		d.deps = append(d.deps, dependency{name: name, ready: ready, timeout: timeout})
+	}
+}
+
+// WaitForDependencies waits for the dependencies declared on the context,
+// in the order they were declared, and returns an error when one of them
+// is not ready within its timeout or the context is done.
+func WaitForDependencies(ctx context.Context) error {
+	d, ok := ctx.Value(dependenciesKey{}).(*dependencies)
+	if !ok {
+		return nil
+	}
+	d.m.Lock()
+	deps := append(d.deps[:0:0], d.deps...)
+	d.m.Unlock()
+
+	for _, dep := range deps {
+		if err := waitFor(ctx, dep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func waitFor(ctx context.Context, dep dependency) error {
+	ctx, cancel := context.WithTimeout(ctx, dep.timeout)
+	defer cancel()
+
+	var lastErr error
+	err := wait.PollImmediateUntil(dependencyPollInterval, func() (bool, error) {
+		lastErr = dep.ready()
+		return lastErr == nil, nil
+	}, ctx.Done())
+	if err != nil {
+		return fmt.Errorf("dependency %q not ready after %v: %v", dep.name, dep.timeout, lastErr)
+	}
+	return nil
+}
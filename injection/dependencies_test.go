@@ -0,0 +1,81 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injection
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitForDependencies(t *testing.T) {
+	defer func(d time.Duration) {
+		dependencyPollInterval = d
+	}(dependencyPollInterval)
+	dependencyPollInterval = time.Millisecond
+
+	// readyAfter returns a check that passes from its nth call on.
+	readyAfter := func(n int) HealthCheck {
+		return func() error {
+			if n--; n > 0 {
+				return errors.New("not yet")
+			}
+			return nil
+		}
+	}
+
+	tests := []struct {
+		name    string
+		deps    map[string]HealthCheck
+		wantErr bool
+	}{{
+		name: "no dependencies",
+	}, {
+		name: "ready",
+		deps: map[string]HealthCheck{
+			"certs": readyAfter(1),
+			"crd":   readyAfter(3),
+		},
+	}, {
+		name: "never ready",
+		deps: map[string]HealthCheck{
+			"crd": func() error { return errors.New("not established") },
+		},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := WithDependencies(context.Background())
+			for name, ready := range test.deps {
+				DependOn(ctx, name, ready, 100*time.Millisecond)
+			}
+			if err := WaitForDependencies(ctx); (err != nil) != test.wantErr {
+				t.Errorf("WaitForDependencies() = %v, wanted error: %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestWaitForDependenciesWithoutDependencies(t *testing.T) {
+	ctx := context.Background()
+	DependOn(ctx, "ignored", func() error { return errors.New("ignored") }, time.Millisecond)
+	if err := WaitForDependencies(ctx); err != nil {
+		t.Errorf("WaitForDependencies() = %v", err)
+	}
+}
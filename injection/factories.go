@@ -18,6 +18,8 @@ package injection
 
 import (
 	"context"
+	"fmt"
+	"reflect"
 )
 
 // InformerFactoryInjector holds the type of a callback that attaches a particular
@@ -38,3 +40,42 @@ func (i *impl) GetInformerFactories() []InformerFactoryInjector {
 	// Copy the slice before returning.
 	return append(i.factories[:0:0], i.factories...)
 }
+
+// SharedInformerFactory is the part of the factories of informers of
+// client-go (e.g. k8s.io/client-go/informers.SharedInformerFactory) used
+// to start their informers.
+type SharedInformerFactory interface {
+	Start(stopCh <-chan struct{})
+	WaitForCacheSync(stopCh <-chan struct{}) map[reflect.Type]bool
+}
+
+// startedFactoriesKey is used as the key for associating the factories
+// started by StartInformers with a context.Context.
+type startedFactoriesKey struct{}
+
+// WithStartedFactory returns a context on which StartInformers also starts
+// the informers of the factory and waits for them to sync.  It is for
+// factories whose informers are not injected themselves, e.g. the filtered
+// ones, and must be called by their InformerFactoryInjector.
+func WithStartedFactory(ctx context.Context, f SharedInformerFactory) context.Context {
+	factories, _ := ctx.Value(startedFactoriesKey{}).([]SharedInformerFactory)
+	return context.WithValue(ctx, startedFactoriesKey{},
+		append(factories[:len(factories):len(factories)], f))
+}
+
+// startFactories starts the informers of the factories of WithStartedFactory
+// and waits for them to sync until the context is done.
+func startFactories(ctx context.Context) error {
+	factories, _ := ctx.Value(startedFactoriesKey{}).([]SharedInformerFactory)
+	for _, f := range factories {
+		f.Start(ctx.Done())
+	}
+	for i, f := range factories {
+		for typ, ok := range f.WaitForCacheSync(ctx.Done()) {
+			if !ok {
+				return fmt.Errorf("failed to wait for cache of %v of factory at index %d to sync", typ, i)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injection
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+const (
+	// ReadinessPath is the path on which HealthHandler serves the
+	// readiness checks.
+	ReadinessPath = "/readyz"
+
+	// LivenessPath is the path on which HealthHandler serves the liveness
+	// checks.
+	LivenessPath = "/healthz"
+)
+
+// HealthCheck returns an error when the part of the process it checks
+// (e.g. the sync of its informers, a dependency, or the progress of its
+// queue) is unhealthy.
+type HealthCheck func() error
+
+// healthKey is used as the key for associating the health checks of the
+// process with a context.Context.
+type healthKey struct{}
+
+type healthChecks struct {
+	m         sync.RWMutex
+	readiness map[string]HealthCheck
+	liveness  map[string]HealthCheck
+}
+
+// WithHealthChecks returns a context to which the controllers constructed
+// with it can add their health checks, to be served by HealthHandler.
+func WithHealthChecks(ctx context.Context) context.Context {
+	return context.WithValue(ctx, healthKey{}, &healthChecks{
+		readiness: make(map[string]HealthCheck),
+		liveness:  make(map[string]HealthCheck),
+	})
+}
+
+// AddReadinessCheck adds the named check to the readiness checks of the
+// process, replacing the check of the same name.  Failing it takes the
+// process out of its Service.  It has no effect without WithHealthChecks.
+func AddReadinessCheck(ctx context.Context, name string, check HealthCheck) {
+	if hc, ok := ctx.Value(healthKey{}).(*healthChecks); ok {
+		hc.m.Lock()
+		defer hc.m.Unlock()
+		hc.readiness[name] = check
+	}
+}
+
+// AddLivenessCheck adds the named check to the liveness checks of the
+// process, replacing the check of the same name.  Failing it gets the
+// process restarted, so it should only fail when restarting would help.
+// It has no effect without WithHealthChecks.
+func AddLivenessCheck(ctx context.Context, name string, check HealthCheck) {
+	if hc, ok := ctx.Value(healthKey{}).(*healthChecks); ok {
+		hc.m.Lock()
+		defer hc.m.Unlock()
+		hc.liveness[name] = check
+	}
+}
+
+// HealthHandler serves the readiness checks of the context on
+// ReadinessPath and its liveness checks on LivenessPath.  Requests
+// succeed when every check passes, and otherwise fail with the errors of
+// the failing checks.
+func HealthHandler(ctx context.Context) http.Handler {
+	hc, ok := ctx.Value(healthKey{}).(*healthChecks)
+	if !ok {
+		hc = &healthChecks{}
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(ReadinessPath, func(w http.ResponseWriter, r *http.Request) {
+		hc.serve(w, hc.readiness)
+	})
+	mux.HandleFunc(LivenessPath, func(w http.ResponseWriter, r *http.Request) {
+		hc.serve(w, hc.liveness)
+	})
+	return mux
+}
+
+func (hc *healthChecks) serve(w http.ResponseWriter, checks map[string]HealthCheck) {
+	hc.m.RLock()
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	failures := make([]string, 0, len(names))
+	for _, name := range names {
+		if err := checks[name](); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	hc.m.RUnlock()
+
+	if len(failures) != 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		for _, f := range failures {
+			fmt.Fprintln(w, f)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
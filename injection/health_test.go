@@ -0,0 +1,96 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injection
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHealthHandler(t *testing.T) {
+	passing := func() error { return nil }
+	failing := func() error { return errors.New("no progress") }
+
+	tests := []struct {
+		name     string
+		setup    func(context.Context)
+		path     string
+		wantCode int
+		wantBody string
+	}{{
+		name:     "no checks",
+		path:     ReadinessPath,
+		wantCode: http.StatusOK,
+	}, {
+		name: "ready",
+		setup: func(ctx context.Context) {
+			AddReadinessCheck(ctx, "informers", passing)
+			AddLivenessCheck(ctx, "queue", failing)
+		},
+		path:     ReadinessPath,
+		wantCode: http.StatusOK,
+	}, {
+		name: "not alive",
+		setup: func(ctx context.Context) {
+			AddReadinessCheck(ctx, "informers", passing)
+			AddLivenessCheck(ctx, "queue", failing)
+			AddLivenessCheck(ctx, "dependency", failing)
+		},
+		path:     LivenessPath,
+		wantCode: http.StatusServiceUnavailable,
+		wantBody: "dependency: no progress\nqueue: no progress\n",
+	}, {
+		name: "replaced check",
+		setup: func(ctx context.Context) {
+			AddReadinessCheck(ctx, "informers", failing)
+			AddReadinessCheck(ctx, "informers", passing)
+		},
+		path:     ReadinessPath,
+		wantCode: http.StatusOK,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := WithHealthChecks(context.Background())
+			if test.setup != nil {
+				test.setup(ctx)
+			}
+
+			rec := httptest.NewRecorder()
+			HealthHandler(ctx).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.path, nil))
+			if rec.Code != test.wantCode {
+				t.Errorf("Code = %d, wanted %d", rec.Code, test.wantCode)
+			}
+			if got := rec.Body.String(); got != test.wantBody {
+				t.Errorf("Body = %q, wanted %q", got, test.wantBody)
+			}
+		})
+	}
+}
+
+func TestHealthHandlerWithoutChecks(t *testing.T) {
+	AddReadinessCheck(context.Background(), "ignored", func() error { return errors.New("ignored") })
+
+	rec := httptest.NewRecorder()
+	HealthHandler(context.Background()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, ReadinessPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Code = %d, wanted %d", rec.Code, http.StatusOK)
+	}
+}
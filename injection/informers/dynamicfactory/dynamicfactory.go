@@ -0,0 +1,196 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dynamicfactory injects a factory of informers of unstructured
+// objects over the injected dynamic client, which can be limited to a
+// list of namespaces so that installations watching a few namespaces
+// don't need the RBAC to list and watch resources cluster-wide.
+package dynamicfactory
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/injection/clients/dynamicclient"
+	"knative.dev/pkg/logging"
+)
+
+// NamespacesEnvKey is the environment variable holding the comma-separated
+// list of namespaces the factory is limited to, when WithNamespaces isn't
+// used.
+const NamespacesEnvKey = "WATCH_NAMESPACES"
+
+func init() {
+	injection.Default.RegisterInformerFactory(withInformerFactory)
+}
+
+// Key is used as the key for associating information with a context.Context.
+type Key struct{}
+
+// namespacesKey is used as the key for associating the namespaces of the
+// factory with a context.Context.
+type namespacesKey struct{}
+
+// WithNamespaces limits the informers of the factory injected with the
+// context to the namespaces (e.g. from a flag), overriding the
+// environment variable.
+func WithNamespaces(ctx context.Context, namespaces ...string) context.Context {
+	return context.WithValue(ctx, namespacesKey{}, namespaces)
+}
+
+// Namespaces returns the namespaces the informers of the factory injected
+// with the context are limited to: those of WithNamespaces, of the
+// NamespacesEnvKey environment variable, or of the injection namespace
+// scope, in that order.  It returns metav1.NamespaceAll when they aren't
+// limited.
+func Namespaces(ctx context.Context) []string {
+	if namespaces, ok := ctx.Value(namespacesKey{}).([]string); ok && len(namespaces) != 0 {
+		return namespaces
+	}
+	if env := os.Getenv(NamespacesEnvKey); env != "" {
+		var namespaces []string
+		for _, ns := range strings.Split(env, ",") {
+			if ns = strings.TrimSpace(ns); ns != "" {
+				namespaces = append(namespaces, ns)
+			}
+		}
+		if len(namespaces) != 0 {
+			return namespaces
+		}
+	}
+	if injection.HasNamespaceScope(ctx) {
+		return []string{injection.GetNamespaceScope(ctx)}
+	}
+	return []string{metav1.NamespaceAll}
+}
+
+func withInformerFactory(ctx context.Context) context.Context {
+	return WithInformerFactory(ctx, dynamicclient.Get(ctx))
+}
+
+// WithInformerFactory attaches a factory of informers over the client to
+// the context, limited to its Namespaces.  It is used by the fake package,
+// with the fake client.
+func WithInformerFactory(ctx context.Context, client dynamic.Interface) context.Context {
+	return context.WithValue(ctx, Key{},
+		NewFactory(client, controller.GetResyncPeriod(ctx), Namespaces(ctx)...))
+}
+
+// Get extracts the Factory from the context.
+func Get(ctx context.Context) *Factory {
+	untyped := ctx.Value(Key{})
+	if untyped == nil {
+		logging.FromContext(ctx).Panicf(
+			"Unable to fetch %T from context.", (*Factory)(nil))
+	}
+	return untyped.(*Factory)
+}
+
+// Factory creates and shares informers of unstructured objects, an
+// informer per resource and namespace of the factory.
+type Factory struct {
+	client     dynamic.Interface
+	resync     time.Duration
+	namespaces []string
+
+	m         sync.Mutex
+	informers map[informerKey]cache.SharedIndexInformer
+	started   map[informerKey]bool
+}
+
+type informerKey struct {
+	gvr       schema.GroupVersionResource
+	namespace string
+}
+
+// NewFactory returns a Factory of informers over the client, limited to
+// the namespaces, or cluster-wide without namespaces.
+func NewFactory(client dynamic.Interface, resync time.Duration, namespaces ...string) *Factory {
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+	return &Factory{
+		client:     client,
+		resync:     resync,
+		namespaces: namespaces,
+		informers:  make(map[informerKey]cache.SharedIndexInformer),
+		started:    make(map[informerKey]bool),
+	}
+}
+
+// Namespaces returns the namespaces the informers are limited to, or
+// metav1.NamespaceAll.
+func (f *Factory) Namespaces() []string {
+	return append([]string(nil), f.namespaces...)
+}
+
+// ForResource returns the informers of the resource, one per namespace of
+// the factory, in the order of the namespaces.
+func (f *Factory) ForResource(gvr schema.GroupVersionResource) []cache.SharedIndexInformer {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	informers := make([]cache.SharedIndexInformer, 0, len(f.namespaces))
+	for _, ns := range f.namespaces {
+		key := informerKey{gvr: gvr, namespace: ns}
+		inf, ok := f.informers[key]
+		if !ok {
+			inf = f.newInformer(gvr, ns)
+			f.informers[key] = inf
+		}
+		informers = append(informers, inf)
+	}
+	return informers
+}
+
+func (f *Factory) newInformer(gvr schema.GroupVersionResource, namespace string) cache.SharedIndexInformer {
+	resource := f.client.Resource(gvr).Namespace(namespace)
+	lw := &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return resource.List(opts)
+		},
+		WatchFunc: resource.Watch,
+	}
+	return cache.NewSharedIndexInformer(lw, &unstructured.Unstructured{}, f.resync, cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+	})
+}
+
+// Start runs the informers created since the last call, until stopCh is
+// closed.
+func (f *Factory) Start(stopCh <-chan struct{}) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	for key, inf := range f.informers {
+		if !f.started[key] {
+			go inf.Run(stopCh)
+			f.started[key] = true
+		}
+	}
+}
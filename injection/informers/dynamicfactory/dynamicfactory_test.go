@@ -0,0 +1,120 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamicfactory
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/cache"
+
+	"knative.dev/pkg/injection"
+)
+
+func TestNamespaces(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  func(context.Context) context.Context
+		env  string
+		want []string
+	}{{
+		name: "cluster-wide",
+		want: []string{metav1.NamespaceAll},
+	}, {
+		name: "namespace scope",
+		ctx: func(ctx context.Context) context.Context {
+			return injection.WithNamespaceScope(ctx, "scoped")
+		},
+		want: []string{"scoped"},
+	}, {
+		name: "environment",
+		ctx: func(ctx context.Context) context.Context {
+			return injection.WithNamespaceScope(ctx, "scoped")
+		},
+		env:  "foo, bar,",
+		want: []string{"foo", "bar"},
+	}, {
+		name: "explicit",
+		ctx: func(ctx context.Context) context.Context {
+			return WithNamespaces(ctx, "baz")
+		},
+		env:  "foo,bar",
+		want: []string{"baz"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer os.Unsetenv(NamespacesEnvKey)
+			os.Setenv(NamespacesEnvKey, test.env)
+
+			ctx := context.Background()
+			if test.ctx != nil {
+				ctx = test.ctx(ctx)
+			}
+			if diff := cmp.Diff(test.want, Namespaces(ctx)); diff != "" {
+				t.Errorf("Namespaces (-want, +got) = %s", diff)
+			}
+		})
+	}
+}
+
+func newUnstructured(namespace, name string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("test.knative.dev/v1")
+	u.SetKind("Foo")
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	return u
+}
+
+func TestFactory(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.knative.dev", Version: "v1", Resource: "foos"}
+	client := fake.NewSimpleDynamicClient(runtime.NewScheme(),
+		newUnstructured("foo", "in-foo"),
+		newUnstructured("bar", "in-bar"),
+		newUnstructured("baz", "in-baz"))
+
+	f := NewFactory(client, time.Hour, "foo", "bar")
+	informers := f.ForResource(gvr)
+	if got, want := len(informers), 2; got != want {
+		t.Fatalf("|ForResource()| = %d, wanted %d", got, want)
+	}
+	if again := f.ForResource(gvr); again[0] != informers[0] || again[1] != informers[1] {
+		t.Error("ForResource() did not share the informers of the resource")
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	f.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, informers[0].HasSynced, informers[1].HasSynced) {
+		t.Fatal("Informers did not sync")
+	}
+
+	for i, want := range []string{"foo/in-foo", "bar/in-bar"} {
+		if got := informers[i].GetIndexer().ListKeys(); !cmp.Equal(got, []string{want}) {
+			t.Errorf("Keys of informer %d = %v, wanted [%s]", i, got, want)
+		}
+	}
+}
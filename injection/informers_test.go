@@ -68,3 +68,36 @@ func TestRegisterInformersAndSetup(t *testing.T) {
 		t.Errorf("SetupInformers() = %d, wanted %d", want, got)
 	}
 }
+
+func TestCopy(t *testing.T) {
+	i := &impl{}
+	i.RegisterClient(injectFoo)
+	i.RegisterInformerFactory(injectFooFactory)
+	i.RegisterInformer(injectFooInformer)
+
+	c := Copy(i)
+	c.RegisterClient(injectBar)
+	c.RegisterInformerFactory(injectBarFactory)
+	c.RegisterInformer(injectBarInformer)
+
+	if want, got := 2, len(c.GetClients()); got != want {
+		t.Errorf("GetClients() = %d, wanted %d", got, want)
+	}
+	if want, got := 2, len(c.GetInformerFactories()); got != want {
+		t.Errorf("GetInformerFactories() = %d, wanted %d", got, want)
+	}
+	if want, got := 2, len(c.GetInformers()); got != want {
+		t.Errorf("GetInformers() = %d, wanted %d", got, want)
+	}
+
+	// The registrations of the copy don't leak into the original.
+	if want, got := 1, len(i.GetClients()); got != want {
+		t.Errorf("GetClients() = %d, wanted %d", got, want)
+	}
+	if want, got := 1, len(i.GetInformerFactories()); got != want {
+		t.Errorf("GetInformerFactories() = %d, wanted %d", got, want)
+	}
+	if want, got := 1, len(i.GetInformers()); got != want {
+		t.Errorf("GetInformers() = %d, wanted %d", got, want)
+	}
+}
@@ -75,6 +75,17 @@ var (
 	Fake Interface = &impl{}
 )
 
+// Copy returns a new injection interface with the injectors registered
+// with from, e.g. Fake, with which a test can register injectors of its
+// own without affecting the other tests.
+func Copy(from Interface) Interface {
+	return &impl{
+		clients:   from.GetClients(),
+		factories: from.GetInformerFactories(),
+		informers: from.GetInformers(),
+	}
+}
+
 type impl struct {
 	m sync.RWMutex
 
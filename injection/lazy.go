@@ -0,0 +1,122 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injection
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+)
+
+// demandKey is used as the key for associating the informers demanded
+// by the controllers with a context.Context.
+type demandKey struct{}
+
+// demand tracks the informers fetched from a context with lazy informers.
+type demand struct {
+	m         sync.Mutex
+	seen      map[controller.Informer]struct{}
+	informers []controller.Informer
+
+	// stopCh is set once StartInformers was called, after which demanded
+	// informers are started right away.
+	stopCh <-chan struct{}
+}
+
+// WithLazyInformers returns a context in which StartInformers only starts
+// the injected informers that were fetched with their Get function, e.g.
+// by the constructors of the controllers, rather than every informer
+// linked into the binary.  Informers fetched after StartInformers are
+// started by their Get, which waits for them to sync.
+func WithLazyInformers(ctx context.Context) context.Context {
+	return context.WithValue(ctx, demandKey{}, &demand{
+		seen: make(map[controller.Informer]struct{}),
+	})
+}
+
+// Demand records that the injected informer was fetched from the context.
+// It is called by the Get functions of injected informers, and has no
+// effect without WithLazyInformers.
+func Demand(ctx context.Context, inf controller.Informer) {
+	d, ok := ctx.Value(demandKey{}).(*demand)
+	if !ok {
+		return
+	}
+
+	d.m.Lock()
+	if _, ok := d.seen[inf]; ok {
+		d.m.Unlock()
+		return
+	}
+	d.seen[inf] = struct{}{}
+	stopCh := d.stopCh
+	if stopCh == nil {
+		d.informers = append(d.informers, inf)
+	}
+	d.m.Unlock()
+
+	if stopCh != nil {
+		if err := controller.StartInformers(stopCh, inf); err != nil {
+			logging.FromContext(ctx).Errorw("Failed to start informer", zap.Error(err))
+		}
+	}
+}
+
+// StartInformers starts the informers and waits for them to sync until
+// the context is done, like controller.StartInformers.  With
+// WithLazyInformers, it starts the informers demanded so far instead.
+// It also starts the factories of WithStartedFactory.  With
+// WithCacheAccounting, it then accounts for the caches of the informers.
+func StartInformers(ctx context.Context, informers ...controller.Informer) error {
+	started := func() []controller.Informer { return informers }
+	d, ok := ctx.Value(demandKey{}).(*demand)
+	if ok {
+		d.m.Lock()
+		d.stopCh = ctx.Done()
+		informers = d.informers
+		d.informers = nil
+		d.m.Unlock()
+		started = d.demanded
+	}
+
+	if err := controller.StartInformers(ctx.Done(), informers...); err != nil {
+		return err
+	}
+	if err := startFactories(ctx); err != nil {
+		return err
+	}
+	if period, ok := ctx.Value(accountingKey{}).(time.Duration); ok {
+		go accountCaches(ctx, period, started)
+	}
+	return nil
+}
+
+// demanded returns the informers demanded so far.
+func (d *demand) demanded() []controller.Informer {
+	d.m.Lock()
+	defer d.m.Unlock()
+	informers := make([]controller.Informer, 0, len(d.seen))
+	for inf := range d.seen {
+		informers = append(informers, inf)
+	}
+	return informers
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injection
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// runInformer records whether it was run.
+type runInformer struct {
+	m   sync.Mutex
+	ran bool
+}
+
+func (fi *runInformer) Run(<-chan struct{}) {
+	fi.m.Lock()
+	defer fi.m.Unlock()
+	fi.ran = true
+}
+
+func (fi *runInformer) HasSynced() bool {
+	fi.m.Lock()
+	defer fi.m.Unlock()
+	return fi.ran
+}
+
+func TestStartInformers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	used, unused := &runInformer{}, &runInformer{}
+	Demand(ctx, used)
+	if err := StartInformers(ctx, used, unused); err != nil {
+		t.Fatalf("StartInformers() = %v", err)
+	}
+	if !used.HasSynced() || !unused.HasSynced() {
+		t.Error("StartInformers() did not start every informer")
+	}
+}
+
+func TestStartLazyInformers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx = WithLazyInformers(ctx)
+
+	used, unused, late := &runInformer{}, &runInformer{}, &runInformer{}
+	Demand(ctx, used)
+	Demand(ctx, used)
+	if err := StartInformers(ctx, used, unused, late); err != nil {
+		t.Fatalf("StartInformers() = %v", err)
+	}
+	if !used.HasSynced() {
+		t.Error("StartInformers() did not start the demanded informer")
+	}
+	if unused.HasSynced() || late.HasSynced() {
+		t.Error("StartInformers() started informers that were not demanded")
+	}
+
+	// Informers demanded once started are synced on demand.
+	Demand(ctx, late)
+	if !late.HasSynced() {
+		t.Error("Demand() did not start the informer demanded late")
+	}
+}
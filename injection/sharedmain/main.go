@@ -25,6 +25,9 @@ import (
 	"os"
 	"os/user"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"go.opencensus.io/stats/view"
@@ -32,8 +35,10 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 
 	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	"knative.dev/pkg/configmap"
@@ -46,6 +51,12 @@ import (
 	"knative.dev/pkg/system"
 )
 
+// HealthPortEnvKey is the environment variable holding the port on which
+// the readiness and liveness checks (see injection.AddReadinessCheck) are
+// served, on injection.ReadinessPath and injection.LivenessPath.  They are
+// not served when it is unset.
+const HealthPortEnvKey = "HEALTH_PORT"
+
 // GetConfig returns a rest.Config to be used for kubernetes client creation.
 // It does so in the following order:
 //   1. Use the passed kubeconfig/masterURL.
@@ -74,6 +85,43 @@ func GetConfig(masterURL, kubeconfig string) (*rest.Config, error) {
 	return nil, fmt.Errorf("could not create a valid kubeconfig")
 }
 
+// GetConfigForContext is like GetConfig, but uses the named context of
+// the kubeconfig rather than its current context, when not empty.  The
+// exec credential plugins of the context's user are supported, and
+// their credentials are cached by client-go across the configs that
+// share them.
+func GetConfigForContext(masterURL, kubeconfig, kubeContext string) (*rest.Config, error) {
+	if kubeContext == "" {
+		return GetConfig(masterURL, kubeconfig)
+	}
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		rules.ExplicitPath = kubeconfig
+	}
+	overrides := &clientcmd.ConfigOverrides{
+		CurrentContext: kubeContext,
+		ClusterInfo:    clientcmdapi.Cluster{Server: masterURL},
+	}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+}
+
+// parseClusters parses the comma-separated name=context pairs of the
+// --clusters flag.
+func parseClusters(flag string) (map[string]string, error) {
+	clusters := make(map[string]string)
+	for _, pair := range strings.Split(flag, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid cluster %q, want name=context", pair)
+		}
+		clusters[parts[0]] = parts[1]
+	}
+	return clusters, nil
+}
+
 // GetLoggingConfig gets the logging config from either the file system if present
 // or via reading a configMap from the API.
 // The context is expected to be initialized with injection.
@@ -99,6 +147,7 @@ func MainWithContext(ctx context.Context, component string, ctors ...injection.C
 	var (
 		masterURL  = flag.String("master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
 		kubeconfig = flag.String("kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
+		clusters   = flag.String("clusters", "", "Comma-separated name=context pairs of kubeconfig contexts of other clusters, made available to controllers through injection.GetClusterConfig.")
 	)
 	flag.Parse()
 
@@ -106,6 +155,18 @@ func MainWithContext(ctx context.Context, component string, ctors ...injection.C
 	if err != nil {
 		log.Fatal("Error building kubeconfig", err)
 	}
+
+	contexts, err := parseClusters(*clusters)
+	if err != nil {
+		log.Fatal("Error parsing clusters", err)
+	}
+	for name, kubeContext := range contexts {
+		clusterCfg, err := GetConfigForContext("", *kubeconfig, kubeContext)
+		if err != nil {
+			log.Fatalf("Error building kubeconfig for cluster %q: %v", name, err)
+		}
+		ctx = injection.WithClusterConfig(ctx, name, clusterCfg)
+	}
 	MainWithConfig(ctx, component, cfg, ctors...)
 }
 
@@ -124,6 +185,7 @@ func MainWithConfig(ctx context.Context, component string, cfg *rest.Config, cto
 	}
 
 	// Adjust our client's rate limits based on the number of controller's we are running.
+	// Clients with rate limits of their own (see injection.WithClientRateLimits) keep them.
 	cfg.QPS = float32(len(ctors)) * rest.DefaultQPS
 	cfg.Burst = len(ctors) * rest.DefaultBurst
 
@@ -137,20 +199,50 @@ func MainWithConfig(ctx context.Context, component string, cfg *rest.Config, cto
 	logger, atomicLevel := logging.NewLoggerFromConfig(loggingConfig, component)
 	defer flush(logger)
 	ctx = logging.WithLogger(ctx, logger)
+	// Let the controllers log with levels per scope (see logging.ScopedFromContext).
+	scopedLoggers := logging.NewScopedLoggers(loggingConfig, component)
+	ctx = logging.WithScopedLoggers(ctx, scopedLoggers)
 
 	// TODO(mattmoor): This should itself take a context and be injection-based.
 	cmw := configmap.NewInformedWatcher(kubeclient.Get(ctx), system.Namespace())
 
+	// Let the controllers add their health checks, and be ready once the informers synced.
+	ctx = injection.WithHealthChecks(ctx)
+	var informersSynced int32
+	injection.AddReadinessCheck(ctx, "informers", func() error {
+		if atomic.LoadInt32(&informersSynced) == 0 {
+			return fmt.Errorf("informers have not synced")
+		}
+		return nil
+	})
+
 	// Based on the reconcilers we have linked, build up the set of controllers to run.
+	// Each gets its own context on which to declare its dependencies (see injection.DependOn).
 	controllers := make([]*controller.Impl, 0, len(ctors))
+	ctrlCtxs := make([]context.Context, 0, len(ctors))
 	for _, cf := range ctors {
-		controllers = append(controllers, cf(ctx, cmw))
+		ctrlCtx := injection.WithDependencies(ctx)
+		controllers = append(controllers, cf(ctrlCtx, cmw))
+		ctrlCtxs = append(ctrlCtxs, ctrlCtx)
 	}
+	for i, c := range controllers {
+		injection.AddLivenessCheck(ctx, fmt.Sprintf("controller-%d", i), c.CheckInformers)
+	}
+
+	// Let operators disable controllers at runtime.
+	cmw.WatchWithDefault(corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: controller.ControllersConfigName}},
+		func(configMap *corev1.ConfigMap) {
+			for _, c := range controllers {
+				c.UpdateEnabledFromConfigMap(configMap)
+			}
+		})
 
 	profilingHandler := profiling.NewHandler(logger, false)
 
 	// Watch the logging config map and dynamically update logging levels.
-	cmw.Watch(logging.ConfigMapName(), logging.UpdateLevelFromConfigMap(logger, atomicLevel, component))
+	cmw.Watch(logging.ConfigMapName(),
+		logging.UpdateLevelFromConfigMap(logger, atomicLevel, component),
+		scopedLoggers.UpdateFromConfigMap(logger))
 
 	// Watch the observability config map
 	cmw.Watch(metrics.ConfigMapName(),
@@ -161,19 +253,42 @@ func MainWithConfig(ctx context.Context, component string, cfg *rest.Config, cto
 		logger.Fatalw("failed to start configuration manager", zap.Error(err))
 	}
 
+	// Serve the health checks while the informers sync, so that slow syncs don't fail liveness.
+	eg, egCtx := errgroup.WithContext(ctx)
+	var healthServer *http.Server
+	if port := os.Getenv(HealthPortEnvKey); port != "" {
+		if _, err := strconv.Atoi(port); err != nil {
+			logger.Fatalw("Invalid "+HealthPortEnvKey, zap.Error(err))
+		}
+		healthServer = &http.Server{
+			Addr:    ":" + port,
+			Handler: injection.HealthHandler(ctx),
+		}
+		eg.Go(healthServer.ListenAndServe)
+	}
+
 	// Start all of the informers and wait for them to sync.
 	logger.Info("Starting informers.")
-	if err := controller.StartInformers(ctx.Done(), informers...); err != nil {
+	if err := injection.StartInformers(ctx, informers...); err != nil {
 		logger.Fatalw("Failed to start informers", err)
 	}
+	atomic.StoreInt32(&informersSynced, 1)
 
-	// Start all of the controllers.
+	// Start all of the controllers, once their dependencies are ready.
 	logger.Info("Starting controllers...")
-	go controller.StartAll(ctx.Done(), controllers...)
+	for i, c := range controllers {
+		go func(ctrlCtx context.Context, c *controller.Impl) {
+			if err := injection.WaitForDependencies(ctrlCtx); err != nil {
+				if ctx.Err() != nil {
+					return // We're shutting down.
+				}
+				logger.Fatalw("Failed to wait for controller dependencies", zap.Error(err))
+			}
+			c.Run(controller.DefaultThreadsPerController, ctx.Done())
+		}(ctrlCtxs[i], c)
+	}
 
 	profilingServer := profiling.NewServer(profilingHandler)
-
-	eg, egCtx := errgroup.WithContext(ctx)
 	eg.Go(profilingServer.ListenAndServe)
 
 	// This will block until either a signal arrives or one of the grouped functions
@@ -181,6 +296,9 @@ func MainWithConfig(ctx context.Context, component string, cfg *rest.Config, cto
 	<-egCtx.Done()
 
 	profilingServer.Shutdown(context.Background())
+	if healthServer != nil {
+		healthServer.Shutdown(context.Background())
+	}
 	// Don't forward ErrServerClosed as that indicates we're already shutting down.
 	if err := eg.Wait(); err != nil && err != http.ErrServerClosed {
 		logger.Errorw("Error while running server", zap.Error(err))
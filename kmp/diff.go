@@ -26,16 +26,29 @@ import (
 // Commonly used Comparers and other Options go here.
 var defaultOpts []cmp.Option
 
+// volatile is implemented by types whose values are expected to change on
+// every write (e.g. knative.dev/pkg/apis.VolatileTime) and which should
+// therefore never produce differences.
+type volatile interface {
+	IsVolatile() bool
+}
+
 func init() {
 	defaultOpts = []cmp.Option{
 		cmp.Comparer(func(x, y resource.Quantity) bool {
 			return x.Cmp(y) == 0
 		}),
+		// Only the type is inspected, so that nil pointers to volatile
+		// types are handled without invoking their methods.
+		cmp.FilterValues(func(x, y volatile) bool {
+			return true
+		}, cmp.Ignore()),
 	}
 }
 
 // SafeDiff wraps cmp.Diff but recovers from panics and uses custom Comparers for:
 // * k8s.io/apimachinery/pkg/api/resource.Quantity
+// * types implementing IsVolatile (e.g. apis.VolatileTime), which are ignored
 // SafeDiff should be used instead of cmp.Diff in non-test code to protect the running
 // process from crashing.
 func SafeDiff(x, y interface{}, opts ...cmp.Option) (diff string, err error) {
@@ -54,6 +67,7 @@ func SafeDiff(x, y interface{}, opts ...cmp.Option) (diff string, err error) {
 
 // SafeEqual wraps cmp.Equal but recovers from panics and uses custom Comparers for:
 // * k8s.io/apimachinery/pkg/api/resource.Quantity
+// * types implementing IsVolatile (e.g. apis.VolatileTime), which are ignored
 // SafeEqual should be used instead of cmp.Equal in non-test code to protect the running
 // process from crashing.
 func SafeEqual(x, y interface{}, opts ...cmp.Option) (equal bool, err error) {
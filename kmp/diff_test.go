@@ -43,6 +43,44 @@ func TestCompareKcmpDefault(t *testing.T) {
 	}
 }
 
+type volatileThing struct {
+	Value int
+}
+
+func (volatileThing) IsVolatile() bool {
+	return true
+}
+
+func TestVolatileIgnored(t *testing.T) {
+	type foo struct {
+		Name  string
+		Thing volatileThing
+		Ptr   *volatileThing
+	}
+
+	a := foo{Name: "a", Thing: volatileThing{1}}
+	b := foo{Name: "a", Thing: volatileThing{2}, Ptr: &volatileThing{3}}
+
+	if got, err := SafeEqual(a, b); err != nil {
+		t.Fatalf("unexpected SafeEqual err: %v", err)
+	} else if !got {
+		t.Error("SafeEqual() = false, wanted true")
+	}
+
+	if got, err := SafeDiff(a, b); err != nil {
+		t.Fatalf("unexpected SafeDiff err: %v", err)
+	} else if got != "" {
+		t.Errorf("SafeDiff() = %s, wanted no diff", got)
+	}
+
+	b.Name = "b"
+	if got, err := SafeEqual(a, b); err != nil {
+		t.Fatalf("unexpected SafeEqual err: %v", err)
+	} else if got {
+		t.Error("SafeEqual() = true, wanted false")
+	}
+}
+
 func TestRecovery(t *testing.T) {
 	type foo struct {
 		bar string
@@ -19,7 +19,10 @@ package logging
 import (
 	"context"
 
+	"go.opencensus.io/trace"
 	"go.uber.org/zap"
+
+	"knative.dev/pkg/logging/logkey"
 )
 
 type loggerKey struct{}
@@ -46,9 +49,23 @@ func WithLogger(ctx context.Context, logger *zap.SugaredLogger) context.Context
 	return context.WithValue(ctx, loggerKey{}, logger)
 }
 
+// WithTrace returns a copy of parent context in which the logger is
+// annotated with the trace and span IDs of the context's trace span, if
+// any.  It is meant to be called once, where the span is started.
+func WithTrace(ctx context.Context) context.Context {
+	span := trace.FromContext(ctx)
+	if span == nil {
+		return ctx
+	}
+	sc := span.SpanContext()
+	return WithLogger(ctx, FromContext(ctx).With(
+		zap.String(logkey.TraceId, sc.TraceID.String()),
+		zap.String(logkey.SpanID, sc.SpanID.String())))
+}
+
 // FromContext returns the logger stored in context.
-// Returns nil if no logger is set in context, or if the stored value is
-// not of correct type.
+// Returns the fallback logger if no logger is set in context, or if the
+// stored value is not of correct type.
 func FromContext(ctx context.Context) *zap.SugaredLogger {
 	if logger, ok := ctx.Value(loggerKey{}).(*zap.SugaredLogger); ok {
 		return logger
@@ -17,10 +17,17 @@ limitations under the License.
 package logging
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"strings"
 	"testing"
 
+	"go.opencensus.io/trace"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"knative.dev/pkg/logging/logkey"
 )
 
 func TestContext(t *testing.T) {
@@ -51,3 +58,37 @@ func checkFromContext(ctx context.Context, want *zap.SugaredLogger, t *testing.T
 		t.Errorf("unexpected logger in context. want: %v, got: %v", want, got)
 	}
 }
+
+func TestContextWithoutTrace(t *testing.T) {
+	want := zap.NewNop().Sugar()
+	ctx := WithLogger(context.Background(), want)
+	checkFromContext(WithTrace(ctx), want, t)
+}
+
+func TestContextWithTrace(t *testing.T) {
+	var buf bytes.Buffer
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&buf), zapcore.InfoLevel)
+	ctx := WithLogger(context.Background(), zap.New(core).Sugar())
+
+	ctx, span := trace.StartSpan(ctx, "test")
+	defer span.End()
+	ctx = WithTrace(ctx)
+	// Storing the logger again doesn't repeat the IDs.
+	ctx = WithLogger(ctx, FromContext(ctx))
+	FromContext(ctx).Info("traced")
+
+	if n := strings.Count(buf.String(), logkey.TraceId); n != 1 {
+		t.Errorf("%s logged %d times, want once: %s", logkey.TraceId, n, buf.String())
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("json.Unmarshal(%q) = %v", buf.String(), err)
+	}
+	sc := span.SpanContext()
+	if want := sc.TraceID.String(); got[logkey.TraceId] != want {
+		t.Errorf("%s = %v, want %v", logkey.TraceId, got[logkey.TraceId], want)
+	}
+	if want := sc.SpanID.String(); got[logkey.SpanID] != want {
+		t.Errorf("%s = %v, want %v", logkey.SpanID, got[logkey.SpanID], want)
+	}
+}
@@ -47,6 +47,10 @@ const (
 	// SubResource is a generic key used to represent a sub-resource in logs
 	SubResource = "knative.dev/subresource"
 
+	// DesiredAPIVersion is the key used to represent the target version
+	// of a conversion in logs
+	DesiredAPIVersion = "knative.dev/desiredapiversion"
+
 	// UserInfo is the key used to represent a user information in logs
 	UserInfo = "knative.dev/userinfo"
 
@@ -59,6 +63,24 @@ const (
 	// KubernetesService is the key used to represent a Kubernetes service name in logs
 	KubernetesService = "knative.dev/k8sservice"
 
+	// SpanID is the key used to represent the ID of the trace span in logs
+	SpanID = "knative.dev/spanid"
+
+	// Method is the key used to represent the method of an HTTP request in logs
+	Method = "knative.dev/method"
+
+	// Path is the key used to represent the URL path of an HTTP request in logs
+	Path = "knative.dev/path"
+
+	// Status is the key used to represent the status code of an HTTP response in logs
+	Status = "knative.dev/status"
+
+	// ResponseSize is the key used to represent the size of an HTTP response body in logs
+	ResponseSize = "knative.dev/responsesize"
+
+	// Latency is the key used to represent the latency of an HTTP request in logs
+	Latency = "knative.dev/latency"
+
 	// GitHubCommitID is the key used to represent the GitHub Commit ID where the
 	// Knative component was built from in logs
 	GitHubCommitID = "commit"
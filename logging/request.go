@@ -0,0 +1,127 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"math/rand"
+	"net/http"
+	"time"
+
+	"go.opencensus.io/plugin/ochttp/propagation/b3"
+	"go.opencensus.io/trace"
+	"go.uber.org/zap"
+
+	"knative.dev/pkg/logging/logkey"
+)
+
+// RequestLogOptions configures the handlers of NewRequestLogHandler.
+type RequestLogOptions struct {
+	// SampleRate is the fraction, between 0 and 1, of the successful
+	// requests that are logged.  Requests answered with a 5xx status
+	// are always logged.
+	SampleRate float64
+}
+
+// NewRequestLogHandler returns an http.Handler logging the requests
+// served by h with their method, path, status, response size, latency
+// and, if any, trace and span IDs, to the logger.
+func NewRequestLogHandler(h http.Handler, logger *zap.SugaredLogger, opts RequestLogOptions) http.Handler {
+	return &requestLogHandler{
+		handler: h,
+		logger:  logger.Desugar(),
+		opts:    opts,
+	}
+}
+
+type requestLogHandler struct {
+	handler http.Handler
+	logger  *zap.Logger
+	opts    RequestLogOptions
+}
+
+// ServeHTTP implements http.Handler.
+func (h *requestLogHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	rw := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	h.handler.ServeHTTP(rw, r)
+	latency := time.Since(start)
+
+	failed := rw.status >= http.StatusInternalServerError
+	if !failed && rand.Float64() >= h.opts.SampleRate {
+		return
+	}
+
+	fields := []zap.Field{
+		zap.String(logkey.Method, r.Method),
+		zap.String(logkey.Path, r.URL.Path),
+		zap.Int(logkey.Status, rw.status),
+		zap.Int64(logkey.ResponseSize, rw.size),
+		zap.Duration(logkey.Latency, latency),
+	}
+	if sc, ok := spanContextOf(r); ok {
+		fields = append(fields,
+			zap.String(logkey.TraceId, sc.TraceID.String()),
+			zap.String(logkey.SpanID, sc.SpanID.String()))
+	}
+	if failed {
+		h.logger.Error("Served HTTP request", fields...)
+	} else {
+		h.logger.Info("Served HTTP request", fields...)
+	}
+}
+
+// spanContextOf returns the span context of the request, from its
+// context or else from its B3 headers.
+func spanContextOf(r *http.Request) (trace.SpanContext, bool) {
+	if span := trace.FromContext(r.Context()); span != nil {
+		return span.SpanContext(), true
+	}
+	return (&b3.HTTPFormat{}).SpanContextFromRequest(r)
+}
+
+// statusRecorder is an http.ResponseWriter recording the status and
+// body size of the response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	size        int64
+	wroteHeader bool
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter.
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.size += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher, when the wrapped writer does.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opencensus.io/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"knative.dev/pkg/logging/logkey"
+)
+
+func TestRequestLogHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		sampleRate float64
+		status     int
+		body       string
+		headers    map[string]string
+		wantLogged bool
+		wantLevel  string
+		wantTrace  string
+	}{{
+		name:       "sampled",
+		sampleRate: 1,
+		status:     http.StatusOK,
+		body:       "hello",
+		wantLogged: true,
+		wantLevel:  "info",
+	}, {
+		name:       "not sampled",
+		sampleRate: 0,
+		status:     http.StatusNotFound,
+		body:       "nope",
+	}, {
+		name:       "errors are always logged",
+		sampleRate: 0,
+		status:     http.StatusServiceUnavailable,
+		body:       "down",
+		wantLogged: true,
+		wantLevel:  "error",
+	}, {
+		name:       "b3 trace",
+		sampleRate: 1,
+		status:     http.StatusCreated,
+		headers: map[string]string{
+			"X-B3-TraceId": "463ac35c9f6413ad48485a3953bb6124",
+			"X-B3-SpanId":  "a2fb4a1d1a96d312",
+		},
+		wantLogged: true,
+		wantLevel:  "info",
+		wantTrace:  "463ac35c9f6413ad48485a3953bb6124",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			encoderConfig := zap.NewProductionEncoderConfig()
+			core := zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), zapcore.AddSync(&buf), zapcore.DebugLevel)
+			logger := zap.New(core).Sugar()
+
+			inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+				w.Write([]byte(test.body))
+			})
+			h := NewRequestLogHandler(inner, logger, RequestLogOptions{SampleRate: test.sampleRate})
+
+			req := httptest.NewRequest(http.MethodPost, "/foo", nil)
+			for k, v := range test.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			if w.Code != test.status {
+				t.Errorf("Status = %d, want %d", w.Code, test.status)
+			}
+			if !test.wantLogged {
+				if buf.Len() != 0 {
+					t.Errorf("Logged %q, want nothing", buf.String())
+				}
+				return
+			}
+
+			var got map[string]interface{}
+			if err := json.Unmarshal([]byte(strings.TrimSpace(buf.String())), &got); err != nil {
+				t.Fatalf("json.Unmarshal(%q) = %v", buf.String(), err)
+			}
+			want := map[string]interface{}{
+				"level":             test.wantLevel,
+				logkey.Method:       http.MethodPost,
+				logkey.Path:         "/foo",
+				logkey.Status:       float64(test.status),
+				logkey.ResponseSize: float64(len(test.body)),
+			}
+			for k, v := range want {
+				if got[k] != v {
+					t.Errorf("%s = %v, want %v", k, got[k], v)
+				}
+			}
+			if _, ok := got[logkey.Latency]; !ok {
+				t.Errorf("No %s in %v", logkey.Latency, got)
+			}
+			if test.wantTrace != "" && got[logkey.TraceId] != test.wantTrace {
+				t.Errorf("%s = %v, want %v", logkey.TraceId, got[logkey.TraceId], test.wantTrace)
+			}
+		})
+	}
+}
+
+func TestRequestLogHandlerSpanFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&buf), zapcore.DebugLevel)
+	h := NewRequestLogHandler(http.NotFoundHandler(), zap.New(core).Sugar(), RequestLogOptions{SampleRate: 1})
+
+	ctx, span := trace.StartSpan(httptest.NewRequest(http.MethodGet, "/", nil).Context(), "test")
+	defer span.End()
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("json.Unmarshal(%q) = %v", buf.String(), err)
+	}
+	if want := span.SpanContext().SpanID.String(); got[logkey.SpanID] != want {
+		t.Errorf("%s = %v, want %v", logkey.SpanID, got[logkey.SpanID], want)
+	}
+}
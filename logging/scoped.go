@@ -0,0 +1,132 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ScopedLoggers creates the loggers of the scopes of a component, such
+// as the reconcilers of a controller, each with its own atomic level.
+// The level of the scope "a.b" of the component "c" is that of the most
+// specific of the "loglevel.c.a.b", "loglevel.c.a" and "loglevel.c"
+// keys of the logging ConfigMap, and is updated by UpdateFromConfigMap.
+type ScopedLoggers struct {
+	component string
+	opts      []zap.Option
+
+	// m guards the fields below.
+	m       sync.Mutex
+	config  *Config
+	loggers map[string]*zap.SugaredLogger
+	levels  map[string]zap.AtomicLevel
+}
+
+// NewScopedLoggers creates the ScopedLoggers of the component, using
+// the provided Config until updated.
+func NewScopedLoggers(config *Config, component string, opts ...zap.Option) *ScopedLoggers {
+	return &ScopedLoggers{
+		component: component,
+		opts:      opts,
+		config:    config,
+		loggers:   make(map[string]*zap.SugaredLogger),
+		levels:    make(map[string]zap.AtomicLevel),
+	}
+}
+
+// Logger returns the logger of the dot-separated scope of the
+// component, named after both.
+func (s *ScopedLoggers) Logger(scope string) *zap.SugaredLogger {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if logger, ok := s.loggers[scope]; ok {
+		return logger
+	}
+	logger, level := NewLogger(s.config.LoggingConfig, ScopedLevel(s.config, s.component, scope).String(), s.opts...)
+	logger = logger.Named(s.component).Named(scope)
+	s.loggers[scope] = logger
+	s.levels[scope] = level
+	return logger
+}
+
+// UpdateFromConfigMap returns a helper func that can be used to update
+// the levels of the scopes when the logging ConfigMap is updated.
+func (s *ScopedLoggers) UpdateFromConfigMap(logger *zap.SugaredLogger) func(configMap *corev1.ConfigMap) {
+	return func(configMap *corev1.ConfigMap) {
+		config, err := NewConfigFromConfigMap(configMap)
+		if err != nil {
+			logger.Errorw("Failed to parse the logging configmap. Previous config map will be used.", zap.Error(err))
+			return
+		}
+
+		s.m.Lock()
+		defer s.m.Unlock()
+		s.config = config
+		for scope, atomicLevel := range s.levels {
+			level := ScopedLevel(config, s.component, scope)
+			if atomicLevel.Level() != level {
+				logger.Infof("Updating logging level for %v.%v from %v to %v.", s.component, scope, atomicLevel.Level(), level)
+				atomicLevel.SetLevel(level)
+			}
+		}
+	}
+}
+
+// ScopedLevel returns the level of the dot-separated scope of the
+// component: that of its most specific key in the Config.
+func ScopedLevel(config *Config, component, scope string) zapcore.Level {
+	key := component
+	if scope != "" {
+		key += "." + scope
+	}
+	for {
+		if level, ok := config.LoggingLevel[key]; ok {
+			return level
+		}
+		i := strings.LastIndex(key, ".")
+		if i < len(component) {
+			// Like NewLoggerFromConfig, default to the zero level.
+			return config.LoggingLevel[component]
+		}
+		key = key[:i]
+	}
+}
+
+type scopedLoggersKey struct{}
+
+// WithScopedLoggers returns a copy of parent context in which the
+// value associated with scoped loggers key is the supplied ScopedLoggers.
+func WithScopedLoggers(ctx context.Context, s *ScopedLoggers) context.Context {
+	return context.WithValue(ctx, scopedLoggersKey{}, s)
+}
+
+// ScopedFromContext returns the logger of the scope from the
+// ScopedLoggers stored in context, falling back on the logger stored in
+// context named after the scope.
+func ScopedFromContext(ctx context.Context, scope string) *zap.SugaredLogger {
+	if s, ok := ctx.Value(scopedLoggersKey{}).(*ScopedLoggers); ok {
+		return s.Logger(scope)
+	}
+	return FromContext(ctx).Named(scope)
+}
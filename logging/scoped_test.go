@@ -0,0 +1,132 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestScopedLevel(t *testing.T) {
+	config := &Config{
+		LoggingLevel: map[string]zapcore.Level{
+			"controller":          zapcore.WarnLevel,
+			"controller.kingress": zapcore.DebugLevel,
+			"webhook.defaulting":  zapcore.ErrorLevel,
+		},
+	}
+
+	tests := []struct {
+		component string
+		scope     string
+		want      zapcore.Level
+	}{{
+		component: "controller",
+		want:      zapcore.WarnLevel,
+	}, {
+		component: "controller",
+		scope:     "kingress",
+		want:      zapcore.DebugLevel,
+	}, {
+		component: "controller",
+		scope:     "kingress.status",
+		want:      zapcore.DebugLevel,
+	}, {
+		component: "controller",
+		scope:     "route",
+		want:      zapcore.WarnLevel,
+	}, {
+		component: "webhook",
+		scope:     "defaulting",
+		want:      zapcore.ErrorLevel,
+	}, {
+		component: "webhook",
+		scope:     "validation",
+		want:      zapcore.InfoLevel,
+	}}
+
+	for _, test := range tests {
+		if got := ScopedLevel(config, test.component, test.scope); got != test.want {
+			t.Errorf("ScopedLevel(%q, %q) = %v, want %v", test.component, test.scope, got, test.want)
+		}
+	}
+}
+
+func TestScopedLoggers(t *testing.T) {
+	config, err := NewConfigFromMap(map[string]string{
+		"loglevel.controller":          "info",
+		"loglevel.controller.kingress": "debug",
+	})
+	if err != nil {
+		t.Fatalf("NewConfigFromMap() = %v", err)
+	}
+	s := NewScopedLoggers(config, "controller")
+	ctx := WithScopedLoggers(context.Background(), s)
+
+	kingress := ScopedFromContext(ctx, "kingress")
+	route := ScopedFromContext(ctx, "route")
+	if kingress != ScopedFromContext(ctx, "kingress") {
+		t.Error("ScopedFromContext(kingress) returned different loggers")
+	}
+	if !kingress.Desugar().Core().Enabled(zapcore.DebugLevel) {
+		t.Error("kingress logger is not enabled at debug level")
+	}
+	if route.Desugar().Core().Enabled(zapcore.DebugLevel) {
+		t.Error("route logger is enabled at debug level")
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "knative-something",
+			Name:      "config-logging",
+		},
+		Data: map[string]string{
+			"loglevel.controller":       "debug",
+			"loglevel.controller.route": "error",
+		},
+	}
+	s.UpdateFromConfigMap(kingress)(cm)
+
+	if got, want := s.levels["kingress"].Level(), zapcore.DebugLevel; got != want {
+		t.Errorf("kingress level = %v, want %v", got, want)
+	}
+	if got, want := s.levels["route"].Level(), zapcore.ErrorLevel; got != want {
+		t.Errorf("route level = %v, want %v", got, want)
+	}
+	if route.Desugar().Core().Enabled(zapcore.WarnLevel) {
+		t.Error("route logger is enabled at warn level")
+	}
+
+	// Invalid updates keep the previous levels.
+	cm.Data["loglevel.controller.route"] = "invalid"
+	s.UpdateFromConfigMap(kingress)(cm)
+	if got, want := s.levels["route"].Level(), zapcore.ErrorLevel; got != want {
+		t.Errorf("route level = %v, want %v", got, want)
+	}
+}
+
+func TestScopedFromContextFallback(t *testing.T) {
+	logger, _ := NewLogger("", "info")
+	ctx := WithLogger(context.Background(), logger)
+	if ScopedFromContext(ctx, "kingress") == nil {
+		t.Error("ScopedFromContext() = nil")
+	}
+}
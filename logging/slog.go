@@ -0,0 +1,140 @@
+//go:build go1.21
+// +build go1.21
+
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// NewSlogHandler returns a slog.Handler writing to the given logger, so
+// that the code using log/slog shares its configuration: its level,
+// including the updates of the atomic levels from config-logging, its
+// encoding, its sinks and its fields.
+func NewSlogHandler(logger *zap.SugaredLogger) slog.Handler {
+	return &slogHandler{logger: logger.Desugar()}
+}
+
+// SlogFromContext returns a slog.Logger writing to the logger stored in
+// the context, see FromContext.
+func SlogFromContext(ctx context.Context) *slog.Logger {
+	return slog.New(NewSlogHandler(FromContext(ctx)))
+}
+
+// slogHandler implements slog.Handler on top of a zap.Logger.
+type slogHandler struct {
+	logger *zap.Logger
+}
+
+var _ slog.Handler = (*slogHandler)(nil)
+
+// Enabled implements slog.Handler.
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.Core().Enabled(zapLevel(level))
+}
+
+// Handle implements slog.Handler.
+func (h *slogHandler) Handle(_ context.Context, r slog.Record) error {
+	ce := h.logger.Check(zapLevel(r.Level), r.Message)
+	if ce == nil {
+		return nil
+	}
+	// Report the time and caller of the record rather than ours.
+	if !r.Time.IsZero() {
+		ce.Time = r.Time
+	}
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		ce.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+	}
+
+	fields := make([]zap.Field, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttr(fields, a)
+		return true
+	})
+	ce.Write(fields...)
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make([]zap.Field, 0, len(attrs))
+	for _, a := range attrs {
+		fields = appendAttr(fields, a)
+	}
+	return &slogHandler{logger: h.logger.With(fields...)}
+}
+
+// WithGroup implements slog.Handler.
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{logger: h.logger.With(zap.Namespace(name))}
+}
+
+// appendAttr appends the zap fields of the slog attribute.
+func appendAttr(fields []zap.Field, a slog.Attr) []zap.Field {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() != slog.KindGroup {
+		if a.Equal(slog.Attr{}) {
+			return fields
+		}
+		return append(fields, zap.Any(a.Key, a.Value.Any()))
+	}
+
+	attrs := a.Value.Group()
+	if len(attrs) == 0 {
+		return fields
+	}
+	if a.Key == "" {
+		// Groups without a key are inlined.
+		for _, ga := range attrs {
+			fields = appendAttr(fields, ga)
+		}
+		return fields
+	}
+	return append(fields, zap.Object(a.Key, zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		for _, f := range appendAttr(nil, slog.Attr{Value: slog.GroupValue(attrs...)}) {
+			f.AddTo(enc)
+		}
+		return nil
+	})))
+}
+
+// zapLevel returns the zap level of the slog level, rounding down
+// the levels in between.
+func zapLevel(level slog.Level) zapcore.Level {
+	switch {
+	case level >= slog.LevelError:
+		return zapcore.ErrorLevel
+	case level >= slog.LevelWarn:
+		return zapcore.WarnLevel
+	case level >= slog.LevelInfo:
+		return zapcore.InfoLevel
+	default:
+		return zapcore.DebugLevel
+	}
+}
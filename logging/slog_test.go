@@ -0,0 +1,138 @@
+//go:build go1.21
+// +build go1.21
+
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func newBufferedLogger(level zap.AtomicLevel) (*zap.SugaredLogger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = ""
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), zapcore.AddSync(&buf), level)
+	return zap.New(core).Named("test").Sugar(), &buf
+}
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("json.Unmarshal(%q) = %v", line, err)
+		}
+		lines = append(lines, m)
+	}
+	return lines
+}
+
+func TestSlogHandler(t *testing.T) {
+	level := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	logger, buf := newBufferedLogger(level)
+	ctx := WithLogger(context.Background(), logger)
+
+	sl := SlogFromContext(ctx).With("component", "test")
+	sl.Debug("not logged")
+	sl.WithGroup("req").Info("hello", "path", "/foo", slog.Group("user", "name", "bob"))
+	sl.Warn("careful", slog.Group("", "inlined", 1))
+
+	// Dynamic level updates apply to the slog loggers.
+	level.SetLevel(zapcore.DebugLevel)
+	sl.Debug("now logged")
+
+	want := []map[string]interface{}{{
+		"level":     "info",
+		"logger":    "test",
+		"msg":       "hello",
+		"component": "test",
+		"req": map[string]interface{}{
+			"path": "/foo",
+			"user": map[string]interface{}{"name": "bob"},
+		},
+	}, {
+		"level":     "warn",
+		"logger":    "test",
+		"msg":       "careful",
+		"component": "test",
+		"inlined":   float64(1),
+	}, {
+		"level":     "debug",
+		"logger":    "test",
+		"msg":       "now logged",
+		"component": "test",
+	}}
+	got := decodeLines(t, buf)
+	for _, line := range got {
+		if _, ok := line["caller"]; !ok {
+			t.Errorf("No caller in %v", line)
+		}
+		delete(line, "caller")
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Logged lines (-want, +got) = %s", diff)
+	}
+}
+
+func TestZapLevel(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  zapcore.Level
+	}{{
+		level: slog.LevelDebug - 1,
+		want:  zapcore.DebugLevel,
+	}, {
+		level: slog.LevelDebug,
+		want:  zapcore.DebugLevel,
+	}, {
+		level: slog.LevelInfo,
+		want:  zapcore.InfoLevel,
+	}, {
+		level: slog.LevelInfo + 2,
+		want:  zapcore.InfoLevel,
+	}, {
+		level: slog.LevelWarn,
+		want:  zapcore.WarnLevel,
+	}, {
+		level: slog.LevelError,
+		want:  zapcore.ErrorLevel,
+	}, {
+		level: slog.LevelError + 4,
+		want:  zapcore.ErrorLevel,
+	}}
+
+	for _, test := range tests {
+		if got := zapLevel(test.level); got != test.want {
+			t.Errorf("zapLevel(%v) = %v, want %v", test.level, got, test.want)
+		}
+	}
+}
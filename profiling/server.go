@@ -41,7 +41,7 @@ const (
 type Handler struct {
 	enabled    bool
 	enabledMux sync.Mutex
-	handler    http.Handler
+	handler    *http.ServeMux
 	log        *zap.SugaredLogger
 }
 
@@ -66,6 +66,13 @@ func NewHandler(logger *zap.SugaredLogger, enableProfiling bool) *Handler {
 	}
 }
 
+// Handle registers the handler for the given pattern, alongside the
+// profiling data, e.g. to expose additional debugging information.  Like
+// the profiling data, it is only served while profiling is enabled.
+func (h *Handler) Handle(pattern string, handler http.Handler) {
+	h.handler.Handle(pattern, handler)
+}
+
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.enabledMux.Lock()
 	defer h.enabledMux.Unlock()
@@ -19,6 +19,7 @@ package profiling
 import (
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 
 	"go.uber.org/zap"
@@ -101,3 +102,33 @@ func TestUpdateFromConfigMap(t *testing.T) {
 		})
 	}
 }
+
+func TestHandle(t *testing.T) {
+	handler := NewHandler(zap.NewNop().Sugar(), false)
+	handler.Handle("/debug/extra", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	for _, enabled := range []bool{false, true} {
+		handler.UpdateFromConfigMap(&corev1.ConfigMap{
+			Data: map[string]string{
+				"profiling.enable": strconv.FormatBool(enabled),
+			},
+		})
+
+		req, err := http.NewRequest(http.MethodGet, "/debug/extra", nil)
+		if err != nil {
+			t.Fatal("Error creating request:", err)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		want := http.StatusNotFound
+		if enabled {
+			want = http.StatusTeapot
+		}
+		if rr.Code != want {
+			t.Errorf("StatusCode with profiling enabled %v: %v, want: %v", enabled, rr.Code, want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// PassiveClock is the part of clock.Clock that reconcilers use to tell
+// the time, e.g. to compute how long to requeue after or whether a TTL
+// has expired.
+type PassiveClock interface {
+	Now() time.Time
+	Since(time.Time) time.Duration
+}
+
+// clockKey is used as the key for associating a PassiveClock with a
+// context.Context.
+type clockKey struct{}
+
+// WithClock attaches the PassiveClock to the context, so that tests can
+// control the time seen by the reconcilers they drive.
+func WithClock(ctx context.Context, c PassiveClock) context.Context {
+	return context.WithValue(ctx, clockKey{}, c)
+}
+
+// GetClock returns the PassiveClock attached to the context, or the real
+// clock if there is none.
+func GetClock(ctx context.Context) PassiveClock {
+	if c, ok := ctx.Value(clockKey{}).(PassiveClock); ok {
+		return c
+	}
+	return clock.RealClock{}
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestGetClock(t *testing.T) {
+	if _, ok := GetClock(context.Background()).(clock.RealClock); !ok {
+		t.Errorf("GetClock() = %T, wanted the real clock", GetClock(context.Background()))
+	}
+
+	now := time.Date(2019, time.November, 5, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFakeClock(now)
+	ctx := WithClock(context.Background(), fake)
+	if got := GetClock(ctx).Now(); !got.Equal(now) {
+		t.Errorf("Now() = %v, wanted %v", got, now)
+	}
+
+	fake.Step(time.Minute)
+	if got, want := GetClock(ctx).Since(now), time.Minute; got != want {
+		t.Errorf("Since() = %v, wanted %v", got, want)
+	}
+}
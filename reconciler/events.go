@@ -0,0 +1,108 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+// NewDedupingRecorder returns a record.EventRecorder that passes the events
+// on to er, except for those identical to an event recorded for the same
+// object within the window before.  At the end of the window, if any were
+// dropped, the event is recorded once more with the number of repeats, so
+// that reconcile-heavy controllers do not flood the events API.
+//
+// Past and annotated events are passed on as they are.
+func NewDedupingRecorder(er record.EventRecorder, window time.Duration) record.EventRecorder {
+	return &dedupingRecorder{
+		EventRecorder: er,
+		window:        window,
+		seen:          make(map[eventKey]*int),
+	}
+}
+
+type dedupingRecorder struct {
+	record.EventRecorder
+	window time.Duration
+
+	m sync.Mutex
+	// seen holds the number of repeats of the events recorded within
+	// the window.
+	seen map[eventKey]*int
+}
+
+// eventKey identifies identical events of an object.
+type eventKey struct {
+	objectType, namespace, name string
+	uid                         types.UID
+
+	eventtype, reason, message string
+}
+
+var _ record.EventRecorder = (*dedupingRecorder)(nil)
+
+// Event implements record.EventRecorder
+func (dr *dedupingRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		dr.EventRecorder.Event(object, eventtype, reason, message)
+		return
+	}
+	key := eventKey{
+		objectType: fmt.Sprintf("%T", object),
+		namespace:  accessor.GetNamespace(),
+		name:       accessor.GetName(),
+		uid:        accessor.GetUID(),
+		eventtype:  eventtype,
+		reason:     reason,
+		message:    message,
+	}
+
+	dr.m.Lock()
+	if repeats, ok := dr.seen[key]; ok {
+		*repeats++
+		dr.m.Unlock()
+		return
+	}
+	repeats := new(int)
+	dr.seen[key] = repeats
+	dr.m.Unlock()
+
+	time.AfterFunc(dr.window, func() {
+		dr.m.Lock()
+		delete(dr.seen, key)
+		n := *repeats
+		dr.m.Unlock()
+		if n > 0 {
+			dr.EventRecorder.Event(object, eventtype, reason,
+				fmt.Sprintf("%s (repeated %d times)", message, n))
+		}
+	})
+	dr.EventRecorder.Event(object, eventtype, reason, message)
+}
+
+// Eventf implements record.EventRecorder
+func (dr *dedupingRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	dr.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
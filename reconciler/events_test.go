@@ -0,0 +1,85 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func drain(events chan string) []string {
+	var got []string
+	for {
+		select {
+		case e := <-events:
+			got = append(got, e)
+		default:
+			return got
+		}
+	}
+}
+
+func TestDedupingRecorder(t *testing.T) {
+	fake := record.NewFakeRecorder(100)
+	dr := NewDedupingRecorder(fake, 100*time.Millisecond)
+
+	foo := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "foo"}}
+	bar := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "bar"}}
+
+	for i := 0; i < 3; i++ {
+		dr.Eventf(foo, corev1.EventTypeWarning, "Failed", "failed to %s", "reconcile")
+	}
+	dr.Event(foo, corev1.EventTypeNormal, "Updated", "updated")
+	dr.Event(bar, corev1.EventTypeWarning, "Failed", "failed to reconcile")
+	// Events of things that are not objects are passed on.
+	dr.Event(nil, corev1.EventTypeNormal, "Nil", "nil")
+	dr.Event(nil, corev1.EventTypeNormal, "Nil", "nil")
+
+	want := []string{
+		"Warning Failed failed to reconcile",
+		"Normal Updated updated",
+		"Warning Failed failed to reconcile",
+		"Normal Nil nil",
+		"Normal Nil nil",
+	}
+	if diff := cmp.Diff(want, drain(fake.Events)); diff != "" {
+		t.Errorf("Events within the window (-want, +got) = %s", diff)
+	}
+
+	// At the end of the window, the repeats are summarized.
+	time.Sleep(300 * time.Millisecond)
+	want = []string{
+		"Warning Failed failed to reconcile (repeated 2 times)",
+	}
+	if diff := cmp.Diff(want, drain(fake.Events)); diff != "" {
+		t.Errorf("Events after the window (-want, +got) = %s", diff)
+	}
+
+	// And the next event is recorded again.
+	dr.Eventf(foo, corev1.EventTypeWarning, "Failed", "failed to %s", "reconcile")
+	want = []string{
+		"Warning Failed failed to reconcile",
+	}
+	if diff := cmp.Diff(want, drain(fake.Events)); diff != "" {
+		t.Errorf("Events in the next window (-want, +got) = %s", diff)
+	}
+}
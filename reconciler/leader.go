@@ -0,0 +1,41 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import "context"
+
+// leaderKey is used as the key for associating the leadership of the
+// replica with a context.Context.
+type leaderKey struct{}
+
+// WithLeadership attaches the function telling whether this replica is
+// currently the leader to the context.  Leadership may change while a
+// reconcile runs, so it is checked each time IsLeader is called.
+func WithLeadership(ctx context.Context, isLeader func() bool) context.Context {
+	return context.WithValue(ctx, leaderKey{}, isLeader)
+}
+
+// IsLeader returns whether this replica is currently the leader, so that
+// followers can keep their informers warm and serve reads (e.g. for
+// webhooks or status) while skipping writes.  Without leadership attached
+// to the context, every replica is the leader.
+func IsLeader(ctx context.Context) bool {
+	if isLeader, ok := ctx.Value(leaderKey{}).(func() bool); ok {
+		return isLeader()
+	}
+	return true
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+	"testing"
+)
+
+func TestIsLeader(t *testing.T) {
+	if !IsLeader(context.Background()) {
+		t.Error("IsLeader() = false without leadership, wanted true")
+	}
+
+	leader := false
+	ctx := WithLeadership(context.Background(), func() bool { return leader })
+	if IsLeader(ctx) {
+		t.Error("IsLeader() = true for a follower, wanted false")
+	}
+	leader = true
+	if !IsLeader(ctx) {
+		t.Error("IsLeader() = false once promoted, wanted true")
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"fmt"
+	"time"
+)
+
+// NewRequeueImmediately returns an error that asks the controller to
+// requeue the key right away.  It is not treated as a failure: the key
+// is not rate limited and the reconcile is not logged as an error.
+func NewRequeueImmediately() error {
+	return requeueKeyError{}
+}
+
+// NewRequeueAfter is like NewRequeueImmediately, but asks the controller
+// to requeue the key after the delay, e.g. to check again on something
+// that is progressing outside of the cluster.
+func NewRequeueAfter(delay time.Duration) error {
+	return requeueKeyError{delay: delay}
+}
+
+// requeueKeyError is returned by reconcilers that want their key
+// requeued, rather than to report a failure.
+type requeueKeyError struct {
+	delay time.Duration
+}
+
+var _ error = requeueKeyError{}
+
+// Error implements the error interface
+func (err requeueKeyError) Error() string {
+	return fmt.Sprintf("requeue after: %v", err.delay)
+}
+
+// IsRequeueKey returns whether the error was returned by NewRequeueAfter
+// or NewRequeueImmediately, and the delay it asked for if so.
+func IsRequeueKey(err error) (bool, time.Duration) {
+	switch e := err.(type) {
+	case requeueKeyError:
+		return true, e.delay
+	default:
+		return false, 0
+	}
+}
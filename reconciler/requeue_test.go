@@ -0,0 +1,55 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIsRequeueKey(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantOK    bool
+		wantDelay time.Duration
+	}{{
+		name: "nil",
+	}, {
+		name: "other error",
+		err:  errors.New("requeue after: 1s"),
+	}, {
+		name:   "immediately",
+		err:    NewRequeueImmediately(),
+		wantOK: true,
+	}, {
+		name:      "after",
+		err:       NewRequeueAfter(time.Minute),
+		wantOK:    true,
+		wantDelay: time.Minute,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ok, delay := IsRequeueKey(test.err)
+			if ok != test.wantOK || delay != test.wantDelay {
+				t.Errorf("IsRequeueKey() = (%v, %v), wanted (%v, %v)", ok, delay, test.wantOK, test.wantDelay)
+			}
+		})
+	}
+}
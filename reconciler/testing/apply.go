@@ -0,0 +1,58 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/types"
+	clientgotesting "k8s.io/client-go/testing"
+)
+
+// NewApplyPatch returns the expectation, for WantPatches, of a server-side
+// apply patch of the named resource with the given apply configuration,
+// as YAML or JSON.  Apply patches are compared semantically, so the
+// formatting and key order of the configuration do not matter.
+//
+// The patch actions recorded by the fake clients do not carry the patch
+// options, so the field manager and forcing of conflicts are not checked.
+func NewApplyPatch(namespace, name, config string) clientgotesting.PatchActionImpl {
+	return clientgotesting.PatchActionImpl{
+		ActionImpl: clientgotesting.ActionImpl{
+			Namespace: namespace,
+			Verb:      "patch",
+		},
+		Name:      name,
+		PatchType: types.ApplyPatchType,
+		Patch:     []byte(config),
+	}
+}
+
+// ApplyPatchDiff returns the semantic difference between two apply
+// configurations, as YAML or JSON, in the (-want, +got) form of cmp.Diff.
+func ApplyPatchDiff(want, got []byte) (string, error) {
+	var wantConfig, gotConfig interface{}
+	if err := yaml.Unmarshal(want, &wantConfig); err != nil {
+		return "", fmt.Errorf("failed to parse wanted apply configuration: %v", err)
+	}
+	if err := yaml.Unmarshal(got, &gotConfig); err != nil {
+		return "", fmt.Errorf("failed to parse apply configuration: %v", err)
+	}
+	return cmp.Diff(wantConfig, gotConfig), nil
+}
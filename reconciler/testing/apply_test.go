@@ -0,0 +1,83 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestApplyPatchDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		want     string
+		got      string
+		wantDiff bool
+		wantErr  bool
+	}{{
+		name: "identical",
+		want: `{"spec":{"replicas":3}}`,
+		got:  `{"spec":{"replicas":3}}`,
+	}, {
+		name: "yaml and json",
+		want: "spec:\n  replicas: 3\n  paused: false\n",
+		got:  `{"spec":{"paused":false,"replicas":3}}`,
+	}, {
+		name:     "different",
+		want:     "spec:\n  replicas: 3\n",
+		got:      `{"spec":{"replicas":2}}`,
+		wantDiff: true,
+	}, {
+		name:     "extra field",
+		want:     "spec:\n  replicas: 3\n",
+		got:      `{"spec":{"replicas":3,"paused":true}}`,
+		wantDiff: true,
+	}, {
+		name:    "bad want",
+		want:    "spec: [",
+		got:     `{}`,
+		wantErr: true,
+	}, {
+		name:    "bad got",
+		want:    `{}`,
+		got:     "spec: [",
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diff, err := ApplyPatchDiff([]byte(test.want), []byte(test.got))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ApplyPatchDiff() = %v, wanted error: %v", err, test.wantErr)
+			}
+			if (diff != "") != test.wantDiff {
+				t.Errorf("ApplyPatchDiff() = %q, wanted a diff: %v", diff, test.wantDiff)
+			}
+		})
+	}
+}
+
+func TestNewApplyPatch(t *testing.T) {
+	p := NewApplyPatch("ns", "name", "spec: {}")
+	if got, want := p.GetPatchType(), types.ApplyPatchType; got != want {
+		t.Errorf("GetPatchType() = %v, wanted %v", got, want)
+	}
+	if p.GetNamespace() != "ns" || p.GetName() != "name" || p.GetVerb() != "patch" {
+		t.Errorf("NewApplyPatch() = %#v", p)
+	}
+}
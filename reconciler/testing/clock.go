@@ -20,10 +20,17 @@ import (
 	"time"
 )
 
+// FakeClock is a reconciler.PassiveClock stopped at Time.
 type FakeClock struct {
 	Time time.Time
 }
 
+// Now implements reconciler.PassiveClock
 func (c FakeClock) Now() time.Time {
 	return c.Time
 }
+
+// Since implements reconciler.PassiveClock
+func (c FakeClock) Since(t time.Time) time.Duration {
+	return c.Time.Sub(t)
+}
@@ -42,3 +42,14 @@ func SetupFakeContextWithCancel(t *testing.T) (context.Context, context.CancelFu
 	ctx, is := injection.Fake.SetupInformers(ctx, &rest.Config{})
 	return ctx, c, is
 }
+
+// SetupFakeContextWithInjection is like SetupFakeContextWithCancel, but sets up the
+// injectors registered with inj (see injection.Copy) rather than with injection.Fake.
+// Each call sets up its own fake clients, factories and informers, so tests running
+// in parallel don't share any of their state.
+func SetupFakeContextWithInjection(t *testing.T, inj injection.Interface) (context.Context, context.CancelFunc, []controller.Informer) {
+	ctx, c := context.WithCancel(logtesting.TestContextWithLogger(t))
+	ctx = controller.WithEventRecorder(ctx, record.NewFakeRecorder(1000))
+	ctx, is := inj.SetupInformers(ctx, &rest.Config{})
+	return ctx, c, is
+}
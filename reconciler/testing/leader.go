@@ -0,0 +1,41 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import "sync"
+
+// LeadershipSequence returns a function, for reconciler.WithLeadership,
+// that tells whether the replica is the leader with the successive
+// answers, repeating the last one once they run out.  For example
+// LeadershipSequence(true, false) simulates the replica losing its
+// leadership after the first check, in the middle of a reconcile.
+// Without answers the replica is always the leader.
+func LeadershipSequence(answers ...bool) func() bool {
+	var m sync.Mutex
+	return func() bool {
+		m.Lock()
+		defer m.Unlock()
+		if len(answers) == 0 {
+			return true
+		}
+		answer := answers[0]
+		if len(answers) > 1 {
+			answers = answers[1:]
+		}
+		return answer
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/client-go/tools/record"
+
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/reconciler"
+)
+
+func TestLeadershipSequence(t *testing.T) {
+	tests := []struct {
+		name    string
+		answers []bool
+		want    []bool
+	}{{
+		name: "no answers",
+		want: []bool{true, true},
+	}, {
+		name:    "demoted",
+		answers: []bool{true, false},
+		want:    []bool{true, false, false},
+	}, {
+		name:    "promoted",
+		answers: []bool{false, true},
+		want:    []bool{false, true, true},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			isLeader := LeadershipSequence(test.answers...)
+			got := make([]bool, 0, len(test.want))
+			for range test.want {
+				got = append(got, isLeader())
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("LeadershipSequence (-want, +got) = %s", diff)
+			}
+		})
+	}
+}
+
+// leaderOnlyReconciler checks its leadership before and after its work,
+// and fails when it lost it in between.
+type leaderOnlyReconciler struct{}
+
+func (*leaderOnlyReconciler) Reconcile(ctx context.Context, key string) error {
+	if !reconciler.IsLeader(ctx) {
+		return nil
+	}
+	if !reconciler.IsLeader(ctx) {
+		return errors.New("lost leadership")
+	}
+	return nil
+}
+
+func TestTableLeadership(t *testing.T) {
+	factory := func(*testing.T, *TableRow) (controller.Reconciler, ActionRecorderList, EventList, *FakeStatsReporter) {
+		return &leaderOnlyReconciler{}, ActionRecorderList{},
+			EventList{Recorder: record.NewFakeRecorder(10)}, &FakeStatsReporter{}
+	}
+
+	TableTest{{
+		Name: "leader by default",
+		Key:  "foo/bar",
+	}, {
+		Name:       "follower",
+		Key:        "foo/bar",
+		Leadership: []bool{false},
+	}, {
+		Name:       "demoted mid-reconcile",
+		Key:        "foo/bar",
+		Leadership: []bool{true, false},
+		WantErr:    true,
+	}}.Test(t, factory)
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	util_runtime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Listers sorts the objects of a table test row into an indexer per type,
+// to back the listers of the reconciler under test and seed its fake
+// clientsets, for the types of any schemes.  Projects embed it and add an
+// accessor per lister they need, e.g.
+//
+//	func (l *Listers) GetFooLister() foolisters.FooLister {
+//		return foolisters.NewFooLister(l.IndexerFor(&v1alpha1.Foo{}))
+//	}
+//
+// The lister types are generated in each project's own packages, so those
+// accessors cannot be provided here.
+type Listers struct {
+	sorter ObjectSorter
+}
+
+// NewListers returns Listers for the types registered by the adders (e.g.
+// the AddToScheme functions of clientsets), holding the objects.  It panics
+// when an object is of a type the adders do not register.
+func NewListers(adders []func(*runtime.Scheme) error, objs []runtime.Object) Listers {
+	scheme := runtime.NewScheme()
+	for _, addTo := range adders {
+		util_runtime.Must(addTo(scheme))
+	}
+	l := Listers{sorter: NewObjectSorter(scheme)}
+	l.sorter.AddObjects(objs...)
+	return l
+}
+
+// IndexerFor returns the indexer holding the objects of the type of obj.
+func (l Listers) IndexerFor(obj runtime.Object) cache.Indexer {
+	return l.sorter.IndexerForObjectType(obj)
+}
+
+// ObjectsFor returns the objects of the types registered by the adders,
+// e.g. to seed the fake clientset of those types.
+func (l Listers) ObjectsFor(adders ...func(*runtime.Scheme) error) []runtime.Object {
+	return l.sorter.ObjectsForSchemeFunc(adders...)
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func testSchemeSubset1Func(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(schema.GroupVersion{Group: "test.group", Version: "v1"}, &testObject1{})
+	return nil
+}
+
+func TestListers(t *testing.T) {
+	l := NewListers([]func(*runtime.Scheme) error{testSchemeSubset1Func, testSchemeSubset2Func}, []runtime.Object{
+		newTestObject1("first"),
+		newTestObject2("second"),
+		newTestObject2("third"),
+	})
+
+	if got, want := len(l.IndexerFor(&testObject1{}).List()), 1; got != want {
+		t.Errorf("|IndexerFor(testObject1)| = %d, wanted %d", got, want)
+	}
+	if _, exists, err := l.IndexerFor(&testObject2{}).GetByKey("third"); err != nil || !exists {
+		t.Errorf("GetByKey(third) = %v, %v, wanted it to exist", exists, err)
+	}
+	if got, want := len(l.ObjectsFor(testSchemeSubset2Func)), 2; got != want {
+		t.Errorf("|ObjectsFor(subset2)| = %d, wanted %d", got, want)
+	}
+}
+
+func TestListersUnrecognizedType(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewListers did not panic when receiving an unrecognized type")
+		}
+	}()
+
+	NewListers([]func(*runtime.Scheme) error{testSchemeSubset1Func}, []runtime.Object{&testObject2{}})
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/trace"
+)
+
+// Metric is a row of an OpenCensus view that we expect to be recorded
+// during reconciliation, for WantMetrics.
+type Metric struct {
+	// Name is the name of the view.
+	Name string
+
+	// Tags are the tags of the row.
+	Tags map[string]string
+
+	// Value is what was recorded into the row during reconciliation: the
+	// number of measurements for Count and Distribution views, their sum
+	// for Sum views, and the last of them for LastValue views.
+	Value float64
+}
+
+// resetViews registers the views named by the metrics anew, which drops
+// the rows recorded into them so far.
+func resetViews(metrics []Metric) error {
+	seen := make(map[string]struct{}, len(metrics))
+	for _, m := range metrics {
+		if _, ok := seen[m.Name]; ok {
+			continue
+		}
+		seen[m.Name] = struct{}{}
+		v := view.Find(m.Name)
+		if v == nil {
+			continue
+		}
+		view.Unregister(v)
+		if err := view.Register(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordedMetrics returns the rows of the views named by the metrics,
+// sorted by view name and then tags.
+func recordedMetrics(metrics []Metric) []Metric {
+	var recorded []Metric
+	seen := make(map[string]struct{}, len(metrics))
+	for _, m := range metrics {
+		if _, ok := seen[m.Name]; ok {
+			continue
+		}
+		seen[m.Name] = struct{}{}
+		data, _ := view.RetrieveData(m.Name)
+		for _, row := range data {
+			recorded = append(recorded, rowMetric(m.Name, row))
+		}
+	}
+	sort.Slice(recorded, func(i, j int) bool {
+		if recorded[i].Name != recorded[j].Name {
+			return recorded[i].Name < recorded[j].Name
+		}
+		return tagsKey(recorded[i].Tags) < tagsKey(recorded[j].Tags)
+	})
+	return recorded
+}
+
+func rowMetric(name string, row *view.Row) Metric {
+	m := Metric{Name: name, Tags: make(map[string]string, len(row.Tags))}
+	for _, t := range row.Tags {
+		m.Tags[t.Key.Name()] = t.Value
+	}
+	switch d := row.Data.(type) {
+	case *view.CountData:
+		m.Value = float64(d.Value)
+	case *view.DistributionData:
+		m.Value = float64(d.Count)
+	case *view.SumData:
+		m.Value = d.Value
+	case *view.LastValueData:
+		m.Value = d.Value
+	}
+	return m
+}
+
+func tagsKey(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for k, v := range tags {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// spanRecorder is a trace.Exporter that records the names of the spans
+// ended while it is registered.
+type spanRecorder struct {
+	m     sync.Mutex
+	spans []string
+}
+
+var _ trace.Exporter = (*spanRecorder)(nil)
+
+// recordSpans records the spans ended until stop is called.  It returns a
+// context with a sampled span, so that the spans started from it are
+// sampled without changing the sampler of the process.
+func recordSpans(ctx context.Context) (_ context.Context, sr *spanRecorder, stop func()) {
+	sr = &spanRecorder{}
+	trace.RegisterExporter(sr)
+	ctx, span := trace.StartSpan(ctx, "test", trace.WithSampler(trace.AlwaysSample()))
+	return ctx, sr, func() {
+		// Don't record the span of the test itself.
+		trace.UnregisterExporter(sr)
+		span.End()
+	}
+}
+
+// ExportSpan implements trace.Exporter
+func (sr *spanRecorder) ExportSpan(s *trace.SpanData) {
+	sr.m.Lock()
+	defer sr.m.Unlock()
+	sr.spans = append(sr.spans, s.Name)
+}
+
+// names returns the names of the spans, in the order they ended.
+func (sr *spanRecorder) names() []string {
+	sr.m.Lock()
+	defer sr.m.Unlock()
+	return append([]string(nil), sr.spans...)
+}
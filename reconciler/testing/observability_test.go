@@ -0,0 +1,122 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opencensus.io/trace"
+	"k8s.io/client-go/tools/record"
+
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/reconciler"
+)
+
+var (
+	observedCountStat = stats.Int64("observed_count", "Number of observed reconciles", stats.UnitNone)
+	observedLastStat  = stats.Int64("observed_last", "Last observed value", stats.UnitNone)
+	resultTagKey      = tag.MustNewKey("result")
+)
+
+func init() {
+	if err := view.Register(&view.View{
+		Measure:     observedCountStat,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{resultTagKey},
+	}, &view.View{
+		Measure:     observedLastStat,
+		Aggregation: view.LastValue(),
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// observedReconciler records metrics and spans, and returns err.
+type observedReconciler struct {
+	err error
+}
+
+func (or *observedReconciler) Reconcile(ctx context.Context, key string) error {
+	ctx, span := trace.StartSpan(ctx, "reconcile")
+	defer span.End()
+	_, child := trace.StartSpan(ctx, "lookup")
+	child.End()
+
+	ctx, _ = tag.New(ctx, tag.Insert(resultTagKey, key))
+	stats.Record(ctx, observedCountStat.M(1))
+	stats.Record(ctx, observedCountStat.M(1))
+	stats.Record(ctx, observedLastStat.M(42))
+	return or.err
+}
+
+func observedFactory(err error) Factory {
+	return func(*testing.T, *TableRow) (controller.Reconciler, ActionRecorderList, EventList, *FakeStatsReporter) {
+		return &observedReconciler{err: err}, ActionRecorderList{},
+			EventList{Recorder: record.NewFakeRecorder(10)}, &FakeStatsReporter{}
+	}
+}
+
+func TestTableObservability(t *testing.T) {
+	// The first reconcile of a key creates its row.
+	row := TableRow{
+		Name: "new rows",
+		Key:  "foo/bar",
+		WantMetrics: []Metric{{
+			Name:  "observed_count",
+			Tags:  map[string]string{"result": "foo/bar"},
+			Value: 2,
+		}, {
+			Name:  "observed_last",
+			Tags:  map[string]string{},
+			Value: 42,
+		}},
+		WantSpans: []string{"lookup", "reconcile"},
+	}
+	row.Test(t, observedFactory(nil))
+
+	// Subsequent reconciles only report what they recorded, including the
+	// values LastValue rows already had.
+	row = TableRow{
+		Name: "existing rows",
+		Key:  "foo/bar",
+		WantMetrics: []Metric{{
+			Name:  "observed_count",
+			Tags:  map[string]string{"result": "foo/bar"},
+			Value: 2,
+		}, {
+			Name:  "observed_last",
+			Tags:  map[string]string{},
+			Value: 42,
+		}},
+		WantSpans: []string{"lookup", "reconcile"},
+	}
+	row.Test(t, observedFactory(nil))
+}
+
+func TestTableRequeue(t *testing.T) {
+	TableTest{{
+		Name:             "requeue after",
+		Key:              "foo/bar",
+		WantRequeue:      true,
+		WantRequeueAfter: time.Minute,
+	}}.Test(t, observedFactory(reconciler.NewRequeueAfter(time.Minute)))
+}
@@ -18,9 +18,15 @@ package testing
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
 	clientgotesting "k8s.io/client-go/testing"
 
 	"knative.dev/pkg/apis"
@@ -41,6 +47,75 @@ func InduceFailure(verb, resource string) clientgotesting.ReactionFunc {
 	}
 }
 
+// InduceFailureOnAttempts is like InduceFailure, but only makes the given
+// attempts (counting from 1) of the verb on the subresource of the resource
+// fail, with the error returned by newErr for the name of the object.  An
+// empty subresource matches the resource itself.  Tests that want to check
+// the retry paths of a reconciler would add:
+//   WithReactors: []clientgotesting.ReactionFunc{
+//      // Makes the first status update of revisions fail with a conflict.
+//      InduceStatusConflict("revisions", 1),
+//   },
+func InduceFailureOnAttempts(verb, resource, subresource string, newErr func(schema.GroupResource, string) error, attempts ...int) clientgotesting.ReactionFunc {
+	var (
+		m       sync.Mutex
+		attempt int
+	)
+	failing := sets.NewInt(attempts...)
+	return func(action clientgotesting.Action) (handled bool, ret runtime.Object, err error) {
+		if !action.Matches(verb, resource) || action.GetSubresource() != subresource {
+			return false, nil, nil
+		}
+		m.Lock()
+		attempt++
+		fail := failing.Has(attempt)
+		m.Unlock()
+		if !fail {
+			return false, nil, nil
+		}
+		return true, nil, newErr(action.GetResource().GroupResource(), actionName(action))
+	}
+}
+
+// InduceStatusConflict makes the given attempts (counting from 1) to update
+// the status of the resource fail with a conflict, as if the object had
+// changed since it was read.
+func InduceStatusConflict(resource string, attempts ...int) clientgotesting.ReactionFunc {
+	return InduceFailureOnAttempts("update", resource, "status", func(gr schema.GroupResource, name string) error {
+		return apierrs.NewConflict(gr, name, errors.New("inducing conflict"))
+	}, attempts...)
+}
+
+// InduceStatusNotFound makes the given attempts (counting from 1) to update
+// the status of the resource fail with not found, as if the object had been
+// deleted since it was read.
+func InduceStatusNotFound(resource string, attempts ...int) clientgotesting.ReactionFunc {
+	return InduceFailureOnAttempts("update", resource, "status", func(gr schema.GroupResource, name string) error {
+		return apierrs.NewNotFound(gr, name)
+	}, attempts...)
+}
+
+// actionName returns the name of the object the action is about.
+func actionName(action clientgotesting.Action) string {
+	switch a := action.(type) {
+	case clientgotesting.UpdateAction:
+		if obj, err := meta.Accessor(a.GetObject()); err == nil {
+			return obj.GetName()
+		}
+	case clientgotesting.CreateAction:
+		if obj, err := meta.Accessor(a.GetObject()); err == nil {
+			return obj.GetName()
+		}
+	case clientgotesting.GetAction:
+		return a.GetName()
+	case clientgotesting.DeleteAction:
+		return a.GetName()
+	case clientgotesting.PatchAction:
+		return a.GetName()
+	}
+	return ""
+}
+
 func ValidateCreates(ctx context.Context, action clientgotesting.Action) (handled bool, ret runtime.Object, err error) {
 	got := action.(clientgotesting.CreateAction).GetObject()
 	obj, ok := got.(apis.Validatable)
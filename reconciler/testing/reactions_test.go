@@ -0,0 +1,90 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgotesting "k8s.io/client-go/testing"
+)
+
+func statusUpdate(subresource string) clientgotesting.UpdateActionImpl {
+	action := clientgotesting.NewUpdateAction(
+		schema.GroupVersionResource{Version: "v1", Resource: "pods"}, "ns",
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "name"}})
+	action.Subresource = subresource
+	return action
+}
+
+func TestInduceStatusFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		reactor  clientgotesting.ReactionFunc
+		actions  []clientgotesting.Action
+		wantErrs []func(error) bool
+	}{{
+		name:    "conflict on the second attempt",
+		reactor: InduceStatusConflict("pods", 2),
+		actions: []clientgotesting.Action{
+			statusUpdate("status"), statusUpdate("status"), statusUpdate("status"),
+		},
+		wantErrs: []func(error) bool{nil, apierrs.IsConflict, nil},
+	}, {
+		name:    "not found on the first attempts",
+		reactor: InduceStatusNotFound("pods", 1, 2),
+		actions: []clientgotesting.Action{
+			statusUpdate("status"), statusUpdate("status"), statusUpdate("status"),
+		},
+		wantErrs: []func(error) bool{apierrs.IsNotFound, apierrs.IsNotFound, nil},
+	}, {
+		name:    "other updates do not count",
+		reactor: InduceStatusConflict("pods", 1),
+		actions: []clientgotesting.Action{
+			statusUpdate(""),
+			clientgotesting.NewUpdateAction(schema.GroupVersionResource{Resource: "services"}, "ns", &corev1.Service{}),
+			statusUpdate("status"),
+		},
+		wantErrs: []func(error) bool{nil, nil, apierrs.IsConflict},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for i, action := range test.actions {
+				handled, _, err := test.reactor(action)
+				want := test.wantErrs[i]
+				if handled != (want != nil) {
+					t.Errorf("action[%d] handled = %v, wanted %v", i, handled, want != nil)
+				}
+				if want != nil && !want(err) {
+					t.Errorf("action[%d] error = %v, not of the wanted kind", i, err)
+				}
+			}
+		})
+	}
+}
+
+func TestInduceFailureOnAttemptsName(t *testing.T) {
+	reactor := InduceStatusNotFound("pods", 1)
+	_, _, err := reactor(statusUpdate("status"))
+	if got, want := err.Error(), `pods "name" not found`; got != want {
+		t.Errorf("Error() = %q, wanted %q", got, want)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -29,6 +30,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	clientgotesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/cache"
 
@@ -36,6 +38,7 @@ import (
 	"knative.dev/pkg/kmeta"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/logging/logkey"
+	"knative.dev/pkg/reconciler"
 	_ "knative.dev/pkg/system/testing" // Setup system.Namespace()
 )
 
@@ -50,6 +53,16 @@ type TableRow struct {
 	// Objects holds the state of the world at the onset of reconciliation.
 	Objects []runtime.Object
 
+	// Now, when set, is the time told by the clock attached to the context passed to
+	// Reconcile (see reconciler.GetClock).  Tests that need to advance time during the
+	// reconciliation can attach a clock.FakeClock to Ctx with reconciler.WithClock instead.
+	Now time.Time
+
+	// Leadership, when not nil, holds the successive answers of reconciler.IsLeader for
+	// the context passed to Reconcile (see LeadershipSequence), so that rows can exercise
+	// the reconciler gaining or losing leadership mid-reconcile.
+	Leadership []bool
+
 	// Key is the parameter to reconciliation.
 	// This has the form "namespace/name".
 	Key string
@@ -57,6 +70,12 @@ type TableRow struct {
 	// WantErr holds whether we should expect the reconciliation to result in an error.
 	WantErr bool
 
+	// WantRequeue holds whether we should expect the reconciliation to ask for the key
+	// to be requeued, with reconciler.NewRequeueAfter or reconciler.NewRequeueImmediately,
+	// and WantRequeueAfter the delay it should ask for.  Requeues are not errors.
+	WantRequeue      bool
+	WantRequeueAfter time.Duration
+
 	// WantCreates holds the ordered list of Create calls we expect during reconciliation.
 	WantCreates []runtime.Object
 
@@ -74,6 +93,7 @@ type TableRow struct {
 	WantDeleteCollections []clientgotesting.DeleteCollectionActionImpl
 
 	// WantPatches holds the ordered list of Patch calls we expect during reconciliation.
+	// Server-side apply patches (see NewApplyPatch) are compared semantically.
 	WantPatches []clientgotesting.PatchActionImpl
 
 	// WantEvents holds the ordered list of events we expect during reconciliation.
@@ -82,6 +102,16 @@ type TableRow struct {
 	// WantServiceReadyStats holds the ServiceReady stats we exepect during reconciliation.
 	WantServiceReadyStats map[string]int
 
+	// WantMetrics holds the rows of OpenCensus views we expect to be recorded into during
+	// reconciliation, sorted by view name and then tags.  Only the views named here are
+	// checked, and only when this is not nil.  Their rows are reset before reconciling.
+	WantMetrics []Metric
+
+	// WantSpans holds the names of the spans we expect to end during reconciliation, in
+	// the order they end.  Spans are only checked, and sampled, when this is not nil.
+	// Only the spans started from the context passed to Reconcile are sampled.
+	WantSpans []string
+
 	// WithReactors is a set of functions that are installed as Reactors for the execution
 	// of this row of the table-driven-test.
 	WithReactors []clientgotesting.ReactionFunc
@@ -120,8 +150,35 @@ func (r *TableRow) Test(t *testing.T, factory Factory) {
 		ctx = logging.WithLogger(ctx, l)
 	}
 
+	if !r.Now.IsZero() {
+		ctx = reconciler.WithClock(ctx, FakeClock{Time: r.Now})
+	}
+	if r.Leadership != nil {
+		ctx = reconciler.WithLeadership(ctx, LeadershipSequence(r.Leadership...))
+	}
+
+	// Record the observability output of the Reconcile.
+	if err := resetViews(r.WantMetrics); err != nil {
+		t.Fatalf("Failed to reset the views: %v", err)
+	}
+	var spans *spanRecorder
+	if r.WantSpans != nil {
+		var stop func()
+		ctx, spans, stop = recordSpans(ctx)
+		defer stop()
+	}
+
 	// Run the Reconcile we're testing.
-	if err := c.Reconcile(ctx, r.Key); (err != nil) != r.WantErr {
+	err := c.Reconcile(ctx, r.Key)
+	if requeue, delay := reconciler.IsRequeueKey(err); requeue != r.WantRequeue {
+		t.Errorf("Reconcile() error = %v, WantRequeue %v", err, r.WantRequeue)
+	} else if requeue {
+		if delay != r.WantRequeueAfter {
+			t.Errorf("Reconcile() requeued after %v, WantRequeueAfter %v", delay, r.WantRequeueAfter)
+		}
+		err = nil
+	}
+	if (err != nil) != r.WantErr {
 		t.Errorf("Reconcile() error = %v, WantErr %v", err, r.WantErr)
 	}
 
@@ -300,6 +357,17 @@ func (r *TableRow) Test(t *testing.T, factory Factory) {
 		if !r.SkipNamespaceValidation && got.GetNamespace() != expectedNamespace {
 			t.Errorf("Unexpected patch[%d]: %#v", i, got)
 		}
+		// Apply patches are compared semantically, other patches as written.
+		if want.GetPatchType() == types.ApplyPatchType || got.GetPatchType() == types.ApplyPatchType {
+			if got.GetPatchType() != want.GetPatchType() {
+				t.Errorf("Unexpected patch[%d] type = %v, wanted %v", i, got.GetPatchType(), want.GetPatchType())
+			} else if diff, err := ApplyPatchDiff(want.GetPatch(), got.GetPatch()); err != nil {
+				t.Errorf("Unable to compare apply patch[%d]: %v", i, err)
+			} else if diff != "" {
+				t.Errorf("Unexpected apply patch(-want, +got): %s", diff)
+			}
+			continue
+		}
 		if diff := cmp.Diff(string(want.GetPatch()), string(got.GetPatch())); diff != "" {
 			t.Errorf("Unexpected patch(-want, +got): %s", diff)
 		}
@@ -331,6 +399,18 @@ func (r *TableRow) Test(t *testing.T, factory Factory) {
 	if diff := cmp.Diff(r.WantServiceReadyStats, gotStats); diff != "" {
 		t.Errorf("Unexpected service ready stats (-want, +got): %s", diff)
 	}
+
+	if r.WantMetrics != nil {
+		if diff := cmp.Diff(r.WantMetrics, recordedMetrics(r.WantMetrics), cmpopts.EquateEmpty()); diff != "" {
+			t.Errorf("Unexpected metrics (-want, +got): %s", diff)
+		}
+	}
+
+	if r.WantSpans != nil {
+		if diff := cmp.Diff(r.WantSpans, spans.names(), cmpopts.EquateEmpty()); diff != "" {
+			t.Errorf("Unexpected spans (-want, +got): %s", diff)
+		}
+	}
 }
 
 func filterUpdatesWithSubresource(
@@ -0,0 +1,62 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/tools/record"
+
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/reconciler"
+)
+
+// ttlReconciler requeues keys until their TTL, counted from created, has
+// expired.
+type ttlReconciler struct {
+	created time.Time
+	ttl     time.Duration
+}
+
+func (tr *ttlReconciler) Reconcile(ctx context.Context, key string) error {
+	if left := tr.ttl - reconciler.GetClock(ctx).Since(tr.created); left > 0 {
+		return reconciler.NewRequeueAfter(left)
+	}
+	return nil
+}
+
+func TestTableNow(t *testing.T) {
+	created := time.Date(2019, time.November, 5, 12, 0, 0, 0, time.UTC)
+	factory := func(*testing.T, *TableRow) (controller.Reconciler, ActionRecorderList, EventList, *FakeStatsReporter) {
+		return &ttlReconciler{created: created, ttl: time.Hour}, ActionRecorderList{},
+			EventList{Recorder: record.NewFakeRecorder(10)}, &FakeStatsReporter{}
+	}
+
+	TableTest{{
+		Name:             "before the TTL",
+		Key:              "foo/bar",
+		Now:              created.Add(20 * time.Minute),
+		WantRequeue:      true,
+		WantRequeueAfter: 40 * time.Minute,
+	}, {
+		Name: "after the TTL",
+		Key:  "foo/bar",
+		Now:  created.Add(2 * time.Hour),
+	}}.Test(t, factory)
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"errors"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	informers "k8s.io/client-go/informers"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// NewInformedWatcherFromFactory watches a Kubernetes namespace for secret changes.
+func NewInformedWatcherFromFactory(sif informers.SharedInformerFactory, namespace string) *InformedWatcher {
+	return &InformedWatcher{
+		sif:      sif,
+		informer: sif.Core().V1().Secrets(),
+		ManualWatcher: ManualWatcher{
+			Namespace: namespace,
+		},
+		defaults: make(map[string]*corev1.Secret),
+	}
+}
+
+// NewInformedWatcher watches a Kubernetes namespace for secret changes.
+func NewInformedWatcher(kc kubernetes.Interface, namespace string) *InformedWatcher {
+	return NewInformedWatcherFromFactory(informers.NewSharedInformerFactoryWithOptions(
+		kc,
+		0,
+		informers.WithNamespace(namespace),
+	), namespace)
+}
+
+// InformedWatcher provides an informer-based implementation of Watcher.
+type InformedWatcher struct {
+	sif      informers.SharedInformerFactory
+	informer corev1informers.SecretInformer
+	started  bool
+
+	// defaults are the default Secrets to use if the real ones do not exist or are deleted.
+	defaults map[string]*corev1.Secret
+
+	// Embedding this struct allows us to reuse the logic
+	// of registering and notifying observers. This simplifies the
+	// InformedWatcher to just setting up the Kubernetes informer.
+	ManualWatcher
+}
+
+// Asserts that InformedWatcher implements Watcher.
+var _ Watcher = (*InformedWatcher)(nil)
+
+// Asserts that InformedWatcher implements DefaultingWatcher.
+var _ DefaultingWatcher = (*InformedWatcher)(nil)
+
+// WatchWithDefault implements DefaultingWatcher.
+func (i *InformedWatcher) WatchWithDefault(s corev1.Secret, o ...Observer) {
+	i.defaults[s.Name] = &s
+
+	i.m.Lock()
+	started := i.started
+	i.m.Unlock()
+	if started {
+		panic("cannot WatchWithDefault after the InformedWatcher has started")
+	}
+
+	i.Watch(s.Name, o...)
+}
+
+// Start implements Watcher.
+func (i *InformedWatcher) Start(stopCh <-chan struct{}) error {
+	// Pretend that all the defaulted Secrets were just created. This is done before we start
+	// the informer to ensure that if a defaulted Secret does exist, then the real value is
+	// processed after the default one.
+	for k := range i.observers {
+		if def, ok := i.defaults[k]; ok {
+			i.addSecretEvent(def)
+		}
+	}
+
+	if err := i.registerCallbackAndStartInformer(stopCh); err != nil {
+		return err
+	}
+
+	// Wait until it has been synced (WITHOUT holing the mutex, so callbacks happen)
+	if ok := cache.WaitForCacheSync(stopCh, i.informer.Informer().HasSynced); !ok {
+		return errors.New("error waiting for Secret informer to sync")
+	}
+
+	return i.checkObservedResourcesExist()
+}
+
+func (i *InformedWatcher) registerCallbackAndStartInformer(stopCh <-chan struct{}) error {
+	i.m.Lock()
+	defer i.m.Unlock()
+	if i.started {
+		return errors.New("watcher already started")
+	}
+	i.started = true
+
+	i.informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    i.addSecretEvent,
+		UpdateFunc: i.updateSecretEvent,
+		DeleteFunc: i.deleteSecretEvent,
+	})
+
+	// Start the shared informer factory (non-blocking).
+	i.sif.Start(stopCh)
+	return nil
+}
+
+func (i *InformedWatcher) checkObservedResourcesExist() error {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	// Check that all objects with Observers exist in our informers.
+	for k := range i.observers {
+		if _, err := i.informer.Lister().Secrets(i.Namespace).Get(k); err != nil {
+			if _, ok := i.defaults[k]; ok && k8serrors.IsNotFound(err) {
+				// It is defaulted, so it is OK that it doesn't exist.
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *InformedWatcher) addSecretEvent(obj interface{}) {
+	secret := obj.(*corev1.Secret)
+	i.OnChange(secret)
+}
+
+func (i *InformedWatcher) updateSecretEvent(o, n interface{}) {
+	// Ignore updates that are idempotent, as seen on resyncs.
+	if equality.Semantic.DeepEqual(o, n) {
+		return
+	}
+	secret := n.(*corev1.Secret)
+	i.OnChange(secret)
+}
+
+func (i *InformedWatcher) deleteSecretEvent(obj interface{}) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return
+		}
+		if secret, ok = tombstone.Obj.(*corev1.Secret); !ok {
+			return
+		}
+	}
+	if def, ok := i.defaults[secret.Name]; ok {
+		i.OnChange(def)
+	}
+	// If there is no default value, then don't do anything.
+}
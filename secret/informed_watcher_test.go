@@ -0,0 +1,145 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"sync"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+type counter struct {
+	mu      sync.RWMutex
+	secrets []*corev1.Secret
+}
+
+func (c *counter) callback(s *corev1.Secret) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.secrets = append(c.secrets, s)
+}
+
+func (c *counter) count() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.secrets)
+}
+
+func (c *counter) last() *corev1.Secret {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.secrets[len(c.secrets)-1]
+}
+
+func newSecret(namespace, name, value string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Data: map[string][]byte{"key": []byte(value)},
+	}
+}
+
+func TestInformedWatcher(t *testing.T) {
+	foo := newSecret("default", "foo", "val")
+	bar := newSecret("default", "bar", "val2")
+	kc := fakekubeclientset.NewSimpleClientset(foo, bar)
+	w := NewInformedWatcher(kc, "default")
+
+	foo1, foo2, barCounter := &counter{}, &counter{}, &counter{}
+	w.Watch("foo", foo1.callback, foo2.callback)
+	w.Watch("bar", barCounter.callback)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	if err := w.Start(stopCh); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+
+	// When Start returns the callbacks should have been called with the
+	// version of the objects that is available.
+	for _, c := range []*counter{foo1, foo2, barCounter} {
+		if got, want := c.count(), 1; got != want {
+			t.Errorf("count = %d, wanted %d", got, want)
+		}
+	}
+
+	// Only the observers of a changed Secret are notified.
+	w.updateSecretEvent(foo, newSecret("default", "foo", "new"))
+	for c, want := range map[*counter]int{foo1: 2, foo2: 2, barCounter: 1} {
+		if got := c.count(); got != want {
+			t.Errorf("count = %d, wanted %d", got, want)
+		}
+	}
+
+	// Idempotent updates and Secrets of other namespaces are ignored.
+	w.updateSecretEvent(foo, foo)
+	w.updateSecretEvent(nil, newSecret("other", "foo", "val"))
+	if got, want := foo1.count(), 2; got != want {
+		t.Errorf("count = %d, wanted %d", got, want)
+	}
+}
+
+func TestInformedWatcherMissing(t *testing.T) {
+	w := NewInformedWatcher(fakekubeclientset.NewSimpleClientset(), "default")
+	w.Watch("foo", (&counter{}).callback)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	if err := w.Start(stopCh); err == nil {
+		t.Error("Start() = nil, wanted an error for the missing Secret")
+	}
+}
+
+func TestInformedWatcherWithDefault(t *testing.T) {
+	def := *newSecret("default", "foo", "default")
+	w := NewInformedWatcher(fakekubeclientset.NewSimpleClientset(), "default")
+	foo := &counter{}
+	w.WatchWithDefault(def, foo.callback)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	if err := w.Start(stopCh); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	if got, want := string(foo.last().Data["key"]), "default"; got != want {
+		t.Errorf("Observed value = %q, wanted %q", got, want)
+	}
+
+	// The real Secret takes over from the default until it is deleted.
+	real := newSecret("default", "foo", "real")
+	w.addSecretEvent(real)
+	if got, want := string(foo.last().Data["key"]), "real"; got != want {
+		t.Errorf("Observed value = %q, wanted %q", got, want)
+	}
+	w.deleteSecretEvent(cache.DeletedFinalStateUnknown{Key: "default/foo", Obj: real})
+	if got, want := string(foo.last().Data["key"]), "default"; got != want {
+		t.Errorf("Observed value = %q, wanted %q", got, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("WatchWithDefault() did not panic once started")
+		}
+	}()
+	w.WatchWithDefault(def, foo.callback)
+}
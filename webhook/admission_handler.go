@@ -0,0 +1,53 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// AdmissionHandlerFunc is an adapter that allows a plain function to
+// serve as an AdmissionController for one-off policy webhooks.  The
+// function is handed the decoded request along with a context carrying
+// the request's logger and trace span.  Since it has no webhook
+// configuration of its own, the corresponding
+// (Validating|Mutating)WebhookConfiguration must be managed separately.
+type AdmissionHandlerFunc func(context.Context, *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse
+
+var _ AdmissionController = AdmissionHandlerFunc(nil)
+
+// Admit implements AdmissionController
+func (f AdmissionHandlerFunc) Admit(ctx context.Context, req *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
+	return f(ctx, req)
+}
+
+// Register implements AdmissionController
+func (f AdmissionHandlerFunc) Register(context.Context, kubernetes.Interface, []byte) error {
+	return nil
+}
+
+// HandleAdmissionFunc serves the given function at path alongside the
+// webhook's other admission controllers.  It must be called before Run.
+func (ac *Webhook) HandleAdmissionFunc(path string, f AdmissionHandlerFunc) {
+	if ac.admissionControllers == nil {
+		ac.admissionControllers = make(map[string]AdmissionController, 1)
+	}
+	ac.admissionControllers[path] = f
+}
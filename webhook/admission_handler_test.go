@@ -0,0 +1,158 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opencensus.io/trace"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/logging"
+	. "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/metrics/metricstest"
+)
+
+func TestHandleAdmissionFunc(t *testing.T) {
+	_, ac := newNonRunningTestWebhook(t, newDefaultOptions())
+
+	var gotSpan, gotLogger bool
+	ac.HandleAdmissionFunc("/policy", func(ctx context.Context, req *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
+		gotSpan = trace.FromContext(ctx) != nil
+		gotLogger = logging.FromContext(ctx) != nil
+		if req.Namespace == "forbidden" {
+			return makeErrorStatus("namespace %q is forbidden", req.Namespace)
+		}
+		return &admissionv1beta1.AdmissionResponse{Allowed: true}
+	})
+
+	if err := ac.admissionControllers["/policy"].Register(TestContextWithLogger(t), nil, nil); err != nil {
+		t.Errorf("Register() = %v", err)
+	}
+
+	tests := []struct {
+		namespace   string
+		wantAllowed bool
+	}{{
+		namespace:   "allowed",
+		wantAllowed: true,
+	}, {
+		namespace: "forbidden",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.namespace, func(t *testing.T) {
+			body, err := json.Marshal(admissionv1beta1.AdmissionReview{
+				Request: &admissionv1beta1.AdmissionRequest{
+					UID:       "some-uid",
+					Namespace: test.namespace,
+					Operation: admissionv1beta1.Create,
+					Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+				},
+			})
+			if err != nil {
+				t.Fatalf("Failed to marshal review: %v", err)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/policy", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			ac.ServeHTTP(rec, req)
+
+			var got admissionv1beta1.AdmissionReview
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if got.Response.Allowed != test.wantAllowed {
+				t.Errorf("Allowed = %v, wanted %v", got.Response.Allowed, test.wantAllowed)
+			}
+			if got.Response.UID != "some-uid" {
+				t.Errorf("UID = %q, wanted some-uid", got.Response.UID)
+			}
+			if !gotSpan || !gotLogger {
+				t.Errorf("Handler context had span: %v, logger: %v, wanted both", gotSpan, gotLogger)
+			}
+		})
+	}
+}
+
+func TestMaxRequestBodyBytes(t *testing.T) {
+	resetMetrics()
+
+	opts := newDefaultOptions()
+	opts.MaxRequestBodyBytes = 512
+	_, ac := newNonRunningTestWebhook(t, opts)
+	ac.HandleAdmissionFunc("/policy", func(context.Context, *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
+		return &admissionv1beta1.AdmissionResponse{Allowed: true}
+	})
+
+	review := func(name string) []byte {
+		body, err := json.Marshal(admissionv1beta1.AdmissionReview{
+			Request: &admissionv1beta1.AdmissionRequest{Name: name},
+		})
+		if err != nil {
+			t.Fatalf("Failed to marshal review: %v", err)
+		}
+		return body
+	}
+
+	tests := []struct {
+		name          string
+		body          []byte
+		contentLength bool
+		want          int
+	}{{
+		name:          "small",
+		body:          review("small"),
+		contentLength: true,
+		want:          http.StatusOK,
+	}, {
+		name:          "large",
+		body:          review(strings.Repeat("x", 1024)),
+		contentLength: true,
+		want:          http.StatusRequestEntityTooLarge,
+	}, {
+		name: "large without content length",
+		body: review(strings.Repeat("x", 1024)),
+		want: http.StatusRequestEntityTooLarge,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/policy", bytes.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+			if !test.contentLength {
+				req.ContentLength = -1
+			}
+			rec := httptest.NewRecorder()
+			ac.ServeHTTP(rec, req)
+			if rec.Code != test.want {
+				t.Errorf("Code = %d, wanted %d: %s", rec.Code, test.want, rec.Body.String())
+			}
+		})
+	}
+
+	metricstest.CheckCountData(t, "request_body_too_large_count", map[string]string{
+		admissionPathKey.Name(): "/policy",
+	}, 2)
+}
@@ -0,0 +1,325 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"knative.dev/pkg/logging"
+)
+
+const (
+	// The keys under which cert-manager stores issued certificates.
+	certManagerServerKey  = "tls.key"
+	certManagerServerCert = "tls.crt"
+	certManagerCACert     = "ca.crt"
+)
+
+// certRefreshInterval is how long a certificate is cached before its
+// source is consulted again to pick up rotations.
+var certRefreshInterval = time.Minute
+
+// CertificateSource provides the certificate that the webhook serves and the
+// CA bundle with which it is registered with the API server.
+// Implementations are expected to pick up certificate rotations, and must be
+// safe for concurrent use.
+type CertificateSource interface {
+	// Certificate returns the current server certificate.
+	Certificate() (*tls.Certificate, error)
+
+	// CABundle returns the PEM encoded CA certificate(s) that the API
+	// server should use to verify the certificate we serve.
+	CABundle() ([]byte, error)
+}
+
+// secretCertificateSource reads certificates from a Kubernetes secret,
+// re-reading it periodically to pick up rotations.
+type secretCertificateSource struct {
+	client                 kubernetes.Interface
+	namespace, name        string
+	keyKey, certKey, caKey string
+	generate               func() (*corev1.Secret, error)
+
+	mu              sync.Mutex
+	cert            *tls.Certificate
+	caCert          []byte
+	resourceVersion string
+	fetched         time.Time
+	// refreshing is set while the secret is re-read in the background.
+	refreshing bool
+}
+
+var _ CertificateSource = (*secretCertificateSource)(nil)
+
+// NewSecretCertificateSource returns a CertificateSource backed by the
+// webhook's self-signed certificates in the named secret.  When the secret
+// does not exist it is generated with a certificate for serviceName, which
+// is the historical behavior of the webhook.
+func NewSecretCertificateSource(ctx context.Context, client kubernetes.Interface, namespace, name, serviceName string) CertificateSource {
+	s := newSecretCertificateSource(client, namespace, name, secretServerKey, secretServerCert, secretCACert)
+	s.generate = func() (*corev1.Secret, error) {
+		logging.FromContext(ctx).Info("Did not find existing secret, creating one")
+		return generateSecret(ctx, &ControllerOptions{
+			Namespace:   namespace,
+			SecretName:  name,
+			ServiceName: serviceName,
+		})
+	}
+	return s
+}
+
+// NewCertManagerCertificateSource returns a CertificateSource backed by a
+// secret issued by cert-manager, which stores the key pair and CA under
+// the "tls.key", "tls.crt" and "ca.crt" keys.
+func NewCertManagerCertificateSource(client kubernetes.Interface, namespace, name string) CertificateSource {
+	return newSecretCertificateSource(client, namespace, name, certManagerServerKey, certManagerServerCert, certManagerCACert)
+}
+
+func newSecretCertificateSource(client kubernetes.Interface, namespace, name, keyKey, certKey, caKey string) *secretCertificateSource {
+	return &secretCertificateSource{
+		client:    client,
+		namespace: namespace,
+		name:      name,
+		keyKey:    keyKey,
+		certKey:   certKey,
+		caKey:     caKey,
+	}
+}
+
+// Certificate implements CertificateSource
+func (s *secretCertificateSource) Certificate() (*tls.Certificate, error) {
+	cert, _, err := s.current()
+	return cert, err
+}
+
+// CABundle implements CertificateSource
+func (s *secretCertificateSource) CABundle() ([]byte, error) {
+	_, caCert, err := s.current()
+	return caCert, err
+}
+
+// current returns the cached certificate, reading the secret until one
+// has been read successfully.  Once cached, the certificate is served
+// while the secret is re-read in the background, so that TLS handshakes
+// don't wait for the API server.  Failures to refresh it keep the last good
+// certificate in place until the next refresh interval.
+func (s *secretCertificateSource) current() (*tls.Certificate, []byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cert == nil {
+		secret, err := s.getOrGenerate(true)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := s.update(secret); err != nil {
+			return nil, nil, err
+		}
+		s.fetched = time.Now()
+	} else if time.Since(s.fetched) >= certRefreshInterval && !s.refreshing {
+		s.refreshing = true
+		go s.refresh()
+	}
+	return s.cert, s.caCert, nil
+}
+
+// refresh re-reads the secret without holding the lock.
+func (s *secretCertificateSource) refresh() {
+	// Only generate certificates on startup, since the CA bundle has
+	// already been registered with the API server afterwards.
+	secret, err := s.getOrGenerate(false)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshing = false
+	s.fetched = time.Now()
+	if err == nil && s.resourceVersion != secret.ResourceVersion {
+		// On failure, keep the last good certificate.
+		s.update(secret)
+	}
+}
+
+// update caches the certificate of the secret.
+func (s *secretCertificateSource) update(secret *corev1.Secret) error {
+	serverKey, ok := secret.Data[s.keyKey]
+	if !ok {
+		return fmt.Errorf("server key missing from secret %s/%s", s.namespace, s.name)
+	}
+	serverCert, ok := secret.Data[s.certKey]
+	if !ok {
+		return fmt.Errorf("server cert missing from secret %s/%s", s.namespace, s.name)
+	}
+	caCert, ok := secret.Data[s.caKey]
+	if !ok {
+		return fmt.Errorf("ca cert missing from secret %s/%s", s.namespace, s.name)
+	}
+	cert, err := tls.X509KeyPair(serverCert, serverKey)
+	if err != nil {
+		return fmt.Errorf("invalid certificate in secret %s/%s: %v", s.namespace, s.name, err)
+	}
+
+	s.cert = &cert
+	s.caCert = caCert
+	s.resourceVersion = secret.ResourceVersion
+	return nil
+}
+
+// getOrGenerate reads the secret, generating it when it is missing, if
+// allowed to.
+func (s *secretCertificateSource) getOrGenerate(generate bool) (*corev1.Secret, error) {
+	secret, err := s.client.CoreV1().Secrets(s.namespace).Get(s.name, metav1.GetOptions{})
+	if err == nil {
+		return secret, nil
+	}
+	if !apierrors.IsNotFound(err) || s.generate == nil || !generate {
+		return nil, fmt.Errorf("failed to fetch certificate secret %s/%s: %v", s.namespace, s.name, err)
+	}
+
+	newSecret, err := s.generate()
+	if err != nil {
+		return nil, err
+	}
+	secret, err = s.client.CoreV1().Secrets(s.namespace).Create(newSecret)
+	if err == nil {
+		return secret, nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return nil, err
+	}
+	// OK, so something else might have created, try fetching it instead.
+	return s.client.CoreV1().Secrets(s.namespace).Get(s.name, metav1.GetOptions{})
+}
+
+// fileCertificateSource reads certificates from files, e.g. those mounted
+// from a secret volume, reloading them when they change on disk.
+type fileCertificateSource struct {
+	keyFile, certFile, caFile string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	caCert  []byte
+	modTime time.Time
+	checked time.Time
+}
+
+var _ CertificateSource = (*fileCertificateSource)(nil)
+
+// fileCheckInterval is how often the files backing a file certificate
+// source are checked for modifications.
+var fileCheckInterval = time.Second
+
+// NewFileCertificateSource returns a CertificateSource that reads the PEM
+// encoded key pair and CA certificate from the given files.  Rather than
+// watching them with fsnotify, the files are polled with os.Stat at most
+// once a second, on access, and hot reloaded when modified, which handles
+// the atomic symlink swap Kubernetes performs when updating mounted secrets.
+func NewFileCertificateSource(keyFile, certFile, caFile string) CertificateSource {
+	return &fileCertificateSource{
+		keyFile:  keyFile,
+		certFile: certFile,
+		caFile:   caFile,
+	}
+}
+
+// Certificate implements CertificateSource
+func (f *fileCertificateSource) Certificate() (*tls.Certificate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.reload(); err != nil {
+		return nil, err
+	}
+	return f.cert, nil
+}
+
+// CABundle implements CertificateSource
+func (f *fileCertificateSource) CABundle() ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.reload(); err != nil {
+		return nil, err
+	}
+	return f.caCert, nil
+}
+
+func (f *fileCertificateSource) reload() error {
+	if f.cert != nil && time.Since(f.checked) < fileCheckInterval {
+		return nil
+	}
+	f.checked = time.Now()
+
+	// os.Stat follows symlinks, so this sees through the symlink swap.
+	var latest time.Time
+	for _, name := range []string{f.keyFile, f.certFile, f.caFile} {
+		fi, err := os.Stat(name)
+		if err != nil {
+			if f.cert != nil {
+				return nil
+			}
+			return err
+		}
+		if fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+	}
+	if f.cert != nil && latest.Equal(f.modTime) {
+		return nil
+	}
+
+	serverKey, err := ioutil.ReadFile(f.keyFile)
+	if err != nil {
+		return f.keepOr(err)
+	}
+	serverCert, err := ioutil.ReadFile(f.certFile)
+	if err != nil {
+		return f.keepOr(err)
+	}
+	caCert, err := ioutil.ReadFile(f.caFile)
+	if err != nil {
+		return f.keepOr(err)
+	}
+	cert, err := tls.X509KeyPair(serverCert, serverKey)
+	if err != nil {
+		// This may be a partially written update, so retry on next access.
+		f.modTime = time.Time{}
+		return f.keepOr(fmt.Errorf("invalid certificate in %s: %v", f.certFile, err))
+	}
+
+	if f.cert == nil || !bytes.Equal(f.cert.Certificate[0], cert.Certificate[0]) || !bytes.Equal(f.caCert, caCert) {
+		f.cert = &cert
+		f.caCert = caCert
+	}
+	f.modTime = latest
+	return nil
+}
+
+func (f *fileCertificateSource) keepOr(err error) error {
+	if f.cert != nil {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+func expectCertificate(t *testing.T, source CertificateSource, key, cert, ca []byte) {
+	t.Helper()
+	got, err := source.Certificate()
+	if err != nil {
+		t.Fatalf("Certificate() = %v", err)
+	}
+	want, err := tls.X509KeyPair(cert, key)
+	if err != nil {
+		t.Fatalf("X509KeyPair() = %v", err)
+	}
+	if diff := cmp.Diff(want.Certificate, got.Certificate); diff != "" {
+		t.Errorf("Certificate (-want, +got) = %v", diff)
+	}
+	gotCA, err := source.CABundle()
+	if err != nil {
+		t.Fatalf("CABundle() = %v", err)
+	}
+	if diff := cmp.Diff(ca, gotCA); diff != "" {
+		t.Errorf("CABundle (-want, +got) = %v", diff)
+	}
+}
+
+func TestSecretCertificateSourceGenerates(t *testing.T) {
+	ctx := TestContextWithLogger(t)
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	source := NewSecretCertificateSource(ctx, kubeClient, "ns", "certs", "webhook")
+
+	if _, err := source.Certificate(); err != nil {
+		t.Fatalf("Certificate() = %v", err)
+	}
+	secret, err := kubeClient.CoreV1().Secrets("ns").Get("certs", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get generated secret: %v", err)
+	}
+	expectCertificate(t, source, secret.Data[secretServerKey], secret.Data[secretServerCert], secret.Data[secretCACert])
+}
+
+func TestSecretCertificateSourceRotation(t *testing.T) {
+	ctx := TestContextWithLogger(t)
+	opts := newDefaultOptions()
+	secret, err := generateSecret(ctx, &opts)
+	if err != nil {
+		t.Fatalf("Failed to generate secret: %v", err)
+	}
+	secret.ResourceVersion = "1"
+	kubeClient := fakekubeclientset.NewSimpleClientset(secret)
+	source := NewSecretCertificateSource(ctx, kubeClient, opts.Namespace, opts.SecretName, opts.ServiceName)
+	expectCertificate(t, source, secret.Data[secretServerKey], secret.Data[secretServerCert], secret.Data[secretCACert])
+
+	rotated, err := generateSecret(ctx, &opts)
+	if err != nil {
+		t.Fatalf("Failed to generate secret: %v", err)
+	}
+	rotated.ResourceVersion = "2"
+	if _, err := kubeClient.CoreV1().Secrets(opts.Namespace).Update(rotated); err != nil {
+		t.Fatalf("Failed to update secret: %v", err)
+	}
+
+	// Still cached.
+	expectCertificate(t, source, secret.Data[secretServerKey], secret.Data[secretServerCert], secret.Data[secretCACert])
+
+	// Once expired, the cached certificate is served while it's refreshed.
+	refreshSecretCertificate(t, source.(*secretCertificateSource))
+	expectCertificate(t, source, rotated.Data[secretServerKey], rotated.Data[secretServerCert], rotated.Data[secretCACert])
+
+	// Deleting the secret keeps the last good certificate around.
+	if err := kubeClient.CoreV1().Secrets(opts.Namespace).Delete(opts.SecretName, &metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("Failed to delete secret: %v", err)
+	}
+	kubeClient.ClearActions()
+	refreshSecretCertificate(t, source.(*secretCertificateSource))
+	expectCertificate(t, source, rotated.Data[secretServerKey], rotated.Data[secretServerCert], rotated.Data[secretCACert])
+
+	// The failed refresh is not retried before the next refresh interval.
+	if got := len(kubeClient.Actions()); got != 1 {
+		t.Errorf("Got %d API calls, wanted 1: %v", got, kubeClient.Actions())
+	}
+}
+
+// refreshSecretCertificate expires the certificate of the source, checks
+// that it is still served while it is refreshed, and waits for the refresh
+// to finish.
+func refreshSecretCertificate(t *testing.T, s *secretCertificateSource) {
+	t.Helper()
+	s.mu.Lock()
+	s.fetched = time.Now().Add(-2 * certRefreshInterval)
+	cached := s.cert
+	s.mu.Unlock()
+
+	if got, err := s.Certificate(); err != nil || got != cached {
+		t.Errorf("Certificate() = %p, %v, wanted the cached %p", got, err, cached)
+	}
+	if err := wait.PollImmediate(time.Millisecond, 5*time.Second, func() (bool, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return !s.refreshing, nil
+	}); err != nil {
+		t.Fatalf("Failed to wait for the refresh: %v", err)
+	}
+}
+
+func TestCertManagerCertificateSource(t *testing.T) {
+	ctx := TestContextWithLogger(t)
+	serverKey, serverCert, caCert, err := CreateCerts(ctx, "webhook", "ns")
+	if err != nil {
+		t.Fatalf("CreateCerts() = %v", err)
+	}
+
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	source := NewCertManagerCertificateSource(kubeClient, "ns", "issued")
+	if _, err := source.Certificate(); err == nil {
+		t.Error("Certificate() = nil, wanted error for missing secret")
+	}
+	if _, err := kubeClient.CoreV1().Secrets("ns").Get("issued", metav1.GetOptions{}); err == nil {
+		t.Error("Secret was generated, but cert-manager secrets should not be")
+	}
+
+	if _, err := kubeClient.CoreV1().Secrets("ns").Create(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "issued",
+		},
+		Data: map[string][]byte{
+			"tls.key": serverKey,
+			"tls.crt": serverCert,
+			"ca.crt":  caCert,
+		},
+	}); err != nil {
+		t.Fatalf("Failed to create secret: %v", err)
+	}
+	expectCertificate(t, source, serverKey, serverCert, caCert)
+}
+
+func TestFileCertificateSource(t *testing.T) {
+	ctx := TestContextWithLogger(t)
+	dir, err := ioutil.TempDir("", "certs")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(key, cert, ca []byte) {
+		t.Helper()
+		for name, data := range map[string][]byte{"tls.key": key, "tls.crt": cert, "ca.crt": ca} {
+			if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+				t.Fatalf("WriteFile() = %v", err)
+			}
+		}
+	}
+
+	key, cert, ca, err := CreateCerts(ctx, "webhook", "ns")
+	if err != nil {
+		t.Fatalf("CreateCerts() = %v", err)
+	}
+	write(key, cert, ca)
+
+	source := NewFileCertificateSource(filepath.Join(dir, "tls.key"), filepath.Join(dir, "tls.crt"), filepath.Join(dir, "ca.crt"))
+	expectCertificate(t, source, key, cert, ca)
+
+	newKey, newCert, newCA, err := CreateCerts(ctx, "webhook", "ns")
+	if err != nil {
+		t.Fatalf("CreateCerts() = %v", err)
+	}
+	write(newKey, newCert, newCA)
+	// Make sure the modification is visible regardless of timestamp granularity.
+	later := time.Now().Add(time.Minute)
+	for _, name := range []string{"tls.key", "tls.crt", "ca.crt"} {
+		if err := os.Chtimes(filepath.Join(dir, name), later, later); err != nil {
+			t.Fatalf("Chtimes() = %v", err)
+		}
+	}
+	source.(*fileCertificateSource).checked = time.Time{}
+	expectCertificate(t, source, newKey, newCert, newCA)
+
+	// A broken update keeps the last good certificate around.
+	if err := ioutil.WriteFile(filepath.Join(dir, "tls.crt"), []byte("garbage"), 0600); err != nil {
+		t.Fatalf("WriteFile() = %v", err)
+	}
+	even := later.Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(dir, "tls.crt"), even, even); err != nil {
+		t.Fatalf("Chtimes() = %v", err)
+	}
+	source.(*fileCertificateSource).checked = time.Time{}
+	expectCertificate(t, source, newKey, newCert, newCA)
+
+	if _, err := NewFileCertificateSource("/does/not/exist", "/does/not/exist", "/does/not/exist").Certificate(); err == nil {
+		t.Error("Certificate() = nil, wanted error")
+	}
+}
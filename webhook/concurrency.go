@@ -0,0 +1,115 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+
+	"knative.dev/pkg/metrics"
+)
+
+// ConcurrencyLimit bounds the number of admission requests that are
+// handled concurrently for a single admission controller path.
+type ConcurrencyLimit struct {
+	// MaxInFlight is the number of requests processed at once.
+	// Zero means unlimited.
+	MaxInFlight int
+
+	// MaxQueued is the number of requests that may wait for one of the
+	// MaxInFlight slots.  Requests beyond that are rejected with a 429.
+	MaxQueued int
+}
+
+var (
+	requestShedCountM = stats.Int64(
+		"request_shed_count",
+		"The number of requests rejected because of concurrency limits",
+		stats.UnitDimensionless)
+
+	requestBodyTooLargeCountM = stats.Int64(
+		"request_body_too_large_count",
+		"The number of requests rejected because their body was too large",
+		stats.UnitDimensionless)
+
+	admissionPathKey = tag.MustNewKey("admission_path")
+)
+
+// limiter implements a ConcurrencyLimit.
+type limiter struct {
+	slots     chan struct{}
+	queued    int32
+	maxQueued int32
+}
+
+// newLimiter returns the limiter for the given limit, or nil when the
+// limit does not restrict anything.
+func newLimiter(l ConcurrencyLimit) *limiter {
+	if l.MaxInFlight <= 0 {
+		return nil
+	}
+	return &limiter{
+		slots:     make(chan struct{}, l.MaxInFlight),
+		maxQueued: int32(l.MaxQueued),
+	}
+}
+
+// acquire blocks until a slot is available, returning false when the
+// queue is full or ctx is done before that happens.  Each successful
+// acquire must be paired with a release.
+func (l *limiter) acquire(ctx context.Context) bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	defer atomic.AddInt32(&l.queued, -1)
+	if atomic.AddInt32(&l.queued, 1) > l.maxQueued {
+		return false
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// release frees the slot taken by a successful acquire.
+func (l *limiter) release() {
+	<-l.slots
+}
+
+func recordShed(path string) {
+	ctx, err := tag.New(context.Background(), tag.Insert(admissionPathKey, path))
+	if err != nil {
+		return
+	}
+	metrics.Record(ctx, requestShedCountM.M(1))
+}
+
+func recordBodyTooLarge(path string) {
+	ctx, err := tag.New(context.Background(), tag.Insert(admissionPathKey, path))
+	if err != nil {
+		return
+	}
+	metrics.Record(ctx, requestBodyTooLargeCountM.M(1))
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/client-go/kubernetes"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+
+	. "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/metrics/metricstest"
+)
+
+func TestNewLimiter(t *testing.T) {
+	if l := newLimiter(ConcurrencyLimit{MaxQueued: 10}); l != nil {
+		t.Errorf("newLimiter() = %v, wanted nil without MaxInFlight", l)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	l := newLimiter(ConcurrencyLimit{MaxInFlight: 1, MaxQueued: 1})
+	ctx := context.Background()
+
+	if !l.acquire(ctx) {
+		t.Fatal("acquire() = false, wanted a free slot")
+	}
+
+	// The second request waits in the queue until we release.
+	acquired := make(chan bool)
+	go func() {
+		acquired <- l.acquire(ctx)
+	}()
+	for {
+		if atomic.LoadInt32(&l.queued) == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// The queue is full, so the third request is shed.
+	if l.acquire(ctx) {
+		t.Error("acquire() = true, wanted the request to be shed")
+	}
+
+	l.release()
+	if !<-acquired {
+		t.Error("acquire() = false, wanted the queued request to get the slot")
+	}
+
+	// Queued requests give up when their context is done.
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if l.acquire(cancelled) {
+		t.Error("acquire() = true, wanted a cancelled request to give up")
+	}
+	l.release()
+}
+
+// blockingController is an admission controller that blocks until
+// released.
+type blockingController struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (bc *blockingController) Admit(context.Context, *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
+	bc.started <- struct{}{}
+	<-bc.release
+	return &admissionv1beta1.AdmissionResponse{Allowed: true}
+}
+
+func (bc *blockingController) Register(context.Context, kubernetes.Interface, []byte) error {
+	return nil
+}
+
+func TestConcurrencyLimitShedsLoad(t *testing.T) {
+	resetMetrics()
+
+	opts := newDefaultOptions()
+	opts.ConcurrencyLimits = map[string]ConcurrencyLimit{
+		"/slow": {MaxInFlight: 1},
+	}
+	bc := &blockingController{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	ac, err := New(fakekubeclientset.NewSimpleClientset(), opts, map[string]AdmissionController{
+		"/slow": bc,
+	}, TestLogger(t), nil)
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+
+	body, err := json.Marshal(admissionv1beta1.AdmissionReview{
+		Request: &admissionv1beta1.AdmissionRequest{Operation: admissionv1beta1.Create},
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal review: %v", err)
+	}
+	serve := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/slow", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		ac.ServeHTTP(rec, req)
+		return rec
+	}
+
+	done := make(chan int)
+	go func() {
+		done <- serve().Code
+	}()
+	<-bc.started
+
+	if got := serve().Code; got != http.StatusTooManyRequests {
+		t.Errorf("Code = %d, wanted %d", got, http.StatusTooManyRequests)
+	}
+
+	close(bc.release)
+	if got := <-done; got != http.StatusOK {
+		t.Errorf("Code = %d, wanted %d", got, http.StatusOK)
+	}
+
+	metricstest.CheckCountData(t, "request_shed_count", map[string]string{
+		admissionPathKey.Name(): "/slow",
+	}, 1)
+}
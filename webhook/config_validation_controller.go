@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/markbates/inflect"
 	admissionv1beta1 "k8s.io/api/admission/v1beta1"
@@ -40,6 +41,7 @@ import (
 
 // ConfigValidationController implements the AdmissionController for ConfigMaps
 type ConfigValidationController struct {
+	m            sync.RWMutex
 	constructors map[string]reflect.Value
 	options      ControllerOptions
 }
@@ -101,6 +103,16 @@ func (ac *ConfigValidationController) Register(ctx context.Context, kubeClient k
 		},
 	})
 
+	namespaceSelector := ac.options.ConfigValidationNamespaceSelector
+	if namespaceSelector == nil {
+		namespaceSelector = &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      ac.options.ConfigValidationNamespaceLabel,
+				Operator: metav1.LabelSelectorOpExists,
+			}},
+		}
+	}
+
 	webhook := &admissionregistrationv1beta1.ValidatingWebhookConfiguration{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: ac.options.ConfigValidationWebhookName,
@@ -116,13 +128,9 @@ func (ac *ConfigValidationController) Register(ctx context.Context, kubeClient k
 				},
 				CABundle: caCert,
 			},
-			NamespaceSelector: &metav1.LabelSelector{
-				MatchExpressions: []metav1.LabelSelectorRequirement{{
-					Key:      ac.options.ConfigValidationNamespaceLabel,
-					Operator: metav1.LabelSelectorOpExists,
-				}},
-			},
-			FailurePolicy: &failurePolicy,
+			NamespaceSelector: namespaceSelector,
+			ObjectSelector:    ac.options.ConfigValidationObjectSelector,
+			FailurePolicy:     &failurePolicy,
 		}},
 	}
 
@@ -190,8 +198,16 @@ func (ac *ConfigValidationController) validate(ctx context.Context, req *admissi
 		}
 	}
 
+	for _, d := range configmap.ExampleDrift(&newObj) {
+		logger.Warnf("ConfigMap %s/%s drifted from its example: %s", newObj.Namespace, newObj.Name, d)
+	}
+
+	ac.m.RLock()
+	constructor, ok := ac.constructors[newObj.Name]
+	ac.m.RUnlock()
+
 	var err error
-	if constructor, ok := ac.constructors[newObj.Name]; ok {
+	if ok {
 
 		inputs := []reflect.Value{
 			reflect.ValueOf(&newObj),
@@ -208,10 +224,23 @@ func (ac *ConfigValidationController) validate(ctx context.Context, req *admissi
 	return err
 }
 
-func (ac *ConfigValidationController) registerConfig(name string, constructor interface{}) {
+// RegisterConstructor adds the constructor for the ConfigMap with the
+// given name, replacing any existing one.  The constructor must have the
+// same shape as those passed to configmap.NewUntypedStore, and edits to
+// the ConfigMap are rejected when it returns an error.
+func (ac *ConfigValidationController) RegisterConstructor(name string, constructor interface{}) error {
 	if err := configmap.ValidateConstructor(constructor); err != nil {
-		panic(err)
+		return err
 	}
 
+	ac.m.Lock()
+	defer ac.m.Unlock()
 	ac.constructors[name] = reflect.ValueOf(constructor)
+	return nil
+}
+
+func (ac *ConfigValidationController) registerConfig(name string, constructor interface{}) {
+	if err := ac.RegisterConstructor(name, constructor); err != nil {
+		panic(err)
+	}
 }
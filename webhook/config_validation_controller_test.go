@@ -24,6 +24,7 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	admissionv1beta1 "k8s.io/api/admission/v1beta1"
 	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
 	authenticationv1 "k8s.io/api/authentication/v1"
@@ -62,6 +63,59 @@ func TestValidConfigValidationController(t *testing.T) {
 	}
 }
 
+func TestConfigValidationControllerSelectors(t *testing.T) {
+	objectSelector := &metav1.LabelSelector{
+		MatchLabels: map[string]string{"app.kubernetes.io/part-of": "knative"},
+	}
+	namespaceSelector := &metav1.LabelSelector{
+		MatchLabels: map[string]string{"knative.dev/system": "true"},
+	}
+	tests := []struct {
+		name                  string
+		namespaceSelector     *metav1.LabelSelector
+		objectSelector        *metav1.LabelSelector
+		wantNamespaceSelector *metav1.LabelSelector
+	}{{
+		name: "default namespace selector",
+		wantNamespaceSelector: &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      "knative.dev/config",
+				Operator: metav1.LabelSelectorOpExists,
+			}},
+		},
+	}, {
+		name:                  "explicit selectors",
+		namespaceSelector:     namespaceSelector,
+		objectSelector:        objectSelector,
+		wantNamespaceSelector: namespaceSelector,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := newDefaultOptions()
+			opts.ConfigValidationNamespaceLabel = "knative.dev/config"
+			opts.ConfigValidationNamespaceSelector = test.namespaceSelector
+			opts.ConfigValidationObjectSelector = test.objectSelector
+			kubeClient, ac := newNonRunningTestConfigValidationController(t, opts)
+			createDeployment(kubeClient)
+			if err := ac.Register(TestContextWithLogger(t), kubeClient, []byte{}); err != nil {
+				t.Fatalf("Failed to create webhook: %s", err)
+			}
+
+			got, err := kubeClient.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations().Get(opts.ConfigValidationWebhookName, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Failed to get webhook: %v", err)
+			}
+			if diff := cmp.Diff(test.wantNamespaceSelector, got.Webhooks[0].NamespaceSelector); diff != "" {
+				t.Errorf("NamespaceSelector (-want, +got) = %v", diff)
+			}
+			if diff := cmp.Diff(test.objectSelector, got.Webhooks[0].ObjectSelector); diff != "" {
+				t.Errorf("ObjectSelector (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
 func TestUpdatingConfigValidationController(t *testing.T) {
 	kubeClient, c := newNonRunningTestConfigValidationController(t, newDefaultOptions())
 
@@ -208,6 +262,26 @@ func TestDenyInvalidUpdateConfigMapOutOfRange(t *testing.T) {
 	expectFailsWith(t, resp, "out of range")
 }
 
+func TestRegisterConstructor(t *testing.T) {
+	ac := NewConfigValidationController(configmap.Constructors{}, newDefaultOptions()).(*ConfigValidationController)
+	ctx := apis.WithinCreate(apis.WithUserInfo(
+		TestContextWithLogger(t),
+		&authenticationv1.UserInfo{Username: user1}))
+
+	// Without a constructor there is nothing to validate against.
+	expectAllowed(t, ac.Admit(ctx, createCreateConfigMapRequest(ctx, createWrongValueConfigMap())))
+
+	if err := ac.RegisterConstructor("test-config", newConfigFromConfigMap); err != nil {
+		t.Fatalf("RegisterConstructor() = %v", err)
+	}
+	expectAllowed(t, ac.Admit(ctx, createCreateConfigMapRequest(ctx, createValidConfigMap())))
+	expectFailsWith(t, ac.Admit(ctx, createCreateConfigMapRequest(ctx, createWrongValueConfigMap())), "out of range")
+
+	if err := ac.RegisterConstructor("test-config", func(string) error { return nil }); err == nil {
+		t.Error("RegisterConstructor() = nil, wanted an error for an invalid constructor")
+	}
+}
+
 func createConfigValidationWebhook(kubeClient kubernetes.Interface, webhook *admissionregistrationv1beta1.ValidatingWebhookConfiguration) {
 	client := kubeClient.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations()
 	_, err := client.Create(webhook)
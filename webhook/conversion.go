@@ -0,0 +1,208 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opencensus.io/trace"
+	apixv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/logging"
+)
+
+// conversionWorkers bounds the number of objects of a single
+// ConversionRequest that are converted concurrently.
+const conversionWorkers = 8
+
+// ConversionController provides the interface for controllers serving
+// CustomResourceDefinition conversion webhooks.
+type ConversionController interface {
+	Convert(context.Context, *apixv1beta1.ConversionRequest) *apixv1beta1.ConversionResponse
+}
+
+// ConvertibleObject is the interface definition for the versions of a
+// resource served by the conversion webhook.
+type ConvertibleObject interface {
+	apis.Convertible
+	runtime.Object
+}
+
+// GroupKindConversion describes the versions of a single GroupKind and
+// the hub version they are converted through.
+type GroupKindConversion struct {
+	// HubVersion is the version every other version converts to and from.
+	// It must be a key of Zygotes.
+	HubVersion string
+
+	// Zygotes holds an empty instance of each served version.  The hub's
+	// zygote is decoded into directly, every other version (spoke) is
+	// converted up into the hub with ConvertUp and down from it with
+	// ConvertDown.
+	Zygotes map[string]ConvertibleObject
+}
+
+// HubAndSpokeConversionController implements the ConversionController
+// by converting every object through the hub version of its kind.
+type HubAndSpokeConversionController struct {
+	kinds map[schema.GroupKind]GroupKindConversion
+}
+
+// NewConversionController constructs a ConversionController that
+// converts between any pair of versions listed for a kind by way of
+// that kind's hub version.
+func NewConversionController(kinds map[schema.GroupKind]GroupKindConversion) ConversionController {
+	return &HubAndSpokeConversionController{
+		kinds: kinds,
+	}
+}
+
+// Convert implements ConversionController
+func (cc *HubAndSpokeConversionController) Convert(ctx context.Context, req *apixv1beta1.ConversionRequest) *apixv1beta1.ConversionResponse {
+	logger := logging.FromContext(ctx)
+	res := &apixv1beta1.ConversionResponse{
+		UID: req.UID,
+		Result: metav1.Status{
+			Status: metav1.StatusSuccess,
+		},
+	}
+
+	to, err := schema.ParseGroupVersion(req.DesiredAPIVersion)
+	if err != nil {
+		return conversionFailure(res, "error parsing desired api version: %v", err)
+	}
+
+	// The API server batches objects (e.g. when listing), so convert them
+	// concurrently, keeping them in the order they were given.
+	converted := make([]runtime.RawExtension, len(req.Objects))
+	errs := make([]error, len(req.Objects))
+	sem := make(chan struct{}, conversionWorkers)
+	var wg sync.WaitGroup
+	for i := range req.Objects {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			converted[i], errs[i] = cc.convert(ctx, req.Objects[i], to)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			logger.Errorf("Conversion failed: %v", err)
+			return conversionFailure(res, "conversion failed: %v", err)
+		}
+	}
+	res.ConvertedObjects = converted
+	return res
+}
+
+func (cc *HubAndSpokeConversionController) convert(ctx context.Context, in runtime.RawExtension, to schema.GroupVersion) (runtime.RawExtension, error) {
+	var meta metav1.TypeMeta
+	if err := json.Unmarshal(in.Raw, &meta); err != nil {
+		return runtime.RawExtension{}, fmt.Errorf("error decoding type meta: %v", err)
+	}
+	from := meta.GroupVersionKind()
+
+	ctx, span := trace.StartSpan(ctx, "conversion/"+from.GroupKind().String())
+	defer span.End()
+	span.AddAttributes(
+		trace.StringAttribute("from_version", from.Version),
+		trace.StringAttribute("to_version", to.Version))
+
+	start := time.Now()
+	out, err := cc.convertObject(ctx, in, from, to)
+	if err != nil {
+		span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: err.Error()})
+	}
+	reportConversion(from.GroupKind(), from.Version, to.Version, err == nil, time.Since(start))
+	return out, err
+}
+
+func (cc *HubAndSpokeConversionController) convertObject(ctx context.Context, in runtime.RawExtension, from schema.GroupVersionKind, to schema.GroupVersion) (runtime.RawExtension, error) {
+	if from.Group != to.Group {
+		return runtime.RawExtension{}, fmt.Errorf("cannot convert %v to group %q", from, to.Group)
+	}
+
+	kind, ok := cc.kinds[from.GroupKind()]
+	if !ok {
+		return runtime.RawExtension{}, fmt.Errorf("unhandled kind: %v", from.GroupKind())
+	}
+	hubZygote, ok := kind.Zygotes[kind.HubVersion]
+	if !ok {
+		return runtime.RawExtension{}, fmt.Errorf("hub version %q of %v has no zygote", kind.HubVersion, from.GroupKind())
+	}
+	fromZygote, ok := kind.Zygotes[from.Version]
+	if !ok {
+		return runtime.RawExtension{}, fmt.Errorf("unhandled version %v", from)
+	}
+	toZygote, ok := kind.Zygotes[to.Version]
+	if !ok {
+		return runtime.RawExtension{}, fmt.Errorf("unhandled version %v", to.WithKind(from.Kind))
+	}
+
+	src := fromZygote.DeepCopyObject().(ConvertibleObject)
+	if err := json.Unmarshal(in.Raw, src); err != nil {
+		return runtime.RawExtension{}, fmt.Errorf("error decoding %v: %v", from, err)
+	}
+
+	// Spokes convert up into the hub ...
+	hub := src
+	if from.Version != kind.HubVersion {
+		hub = hubZygote.DeepCopyObject().(ConvertibleObject)
+		if err := src.ConvertUp(ctx, hub); err != nil {
+			return runtime.RawExtension{}, fmt.Errorf("error converting %v up to %q: %v", from, kind.HubVersion, err)
+		}
+	}
+
+	// ... and down out of it.
+	dst := hub
+	if to.Version != kind.HubVersion {
+		dst = toZygote.DeepCopyObject().(ConvertibleObject)
+		if err := dst.ConvertDown(ctx, hub); err != nil {
+			return runtime.RawExtension{}, fmt.Errorf("error converting %q down to %v: %v", kind.HubVersion, to, err)
+		}
+	}
+
+	dst.GetObjectKind().SetGroupVersionKind(to.WithKind(from.Kind))
+	raw, err := json.Marshal(dst)
+	if err != nil {
+		return runtime.RawExtension{}, fmt.Errorf("error encoding %v: %v", to, err)
+	}
+	return runtime.RawExtension{Raw: raw}, nil
+}
+
+func conversionFailure(res *apixv1beta1.ConversionResponse, reason string, args ...interface{}) *apixv1beta1.ConversionResponse {
+	res.ConvertedObjects = nil
+	res.Result = metav1.Status{
+		Status:  metav1.StatusFailure,
+		Message: fmt.Sprintf(reason, args...),
+	}
+	return res
+}
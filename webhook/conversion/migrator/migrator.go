@@ -0,0 +1,126 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package migrator rewrites the stored objects of a
+// CustomResourceDefinition at its current storage version, so that
+// older versions can be dropped from status.storedVersions (and
+// eventually from the CRD itself) after an upgrade.
+package migrator
+
+import (
+	"context"
+	"fmt"
+
+	apixv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apixclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"knative.dev/pkg/logging"
+)
+
+// pageSize is the number of objects listed at a time.
+const pageSize = 500
+
+// Migrator performs the storage version migration of
+// CustomResourceDefinitions.
+type Migrator struct {
+	dynamicClient dynamic.Interface
+	crdClient     apixclient.Interface
+}
+
+// New constructs a Migrator.
+func New(dynamicClient dynamic.Interface, crdClient apixclient.Interface) *Migrator {
+	return &Migrator{
+		dynamicClient: dynamicClient,
+		crdClient:     crdClient,
+	}
+}
+
+// Migrate issues a no-op update of every object of the given
+// resource, which makes the API server (by way of the conversion webhook)
+// rewrite them at the storage version of the CRD.  Once all objects are
+// rewritten, status.storedVersions is pruned to the storage version.
+func (m *Migrator) Migrate(ctx context.Context, gr schema.GroupResource) error {
+	logger := logging.FromContext(ctx).With("resource", gr.String())
+
+	crdClient := m.crdClient.ApiextensionsV1beta1().CustomResourceDefinitions()
+	crd, err := crdClient.Get(gr.String(), metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to fetch crd %s: %v", gr, err)
+	}
+	version := storageVersion(crd)
+	if version == "" {
+		return fmt.Errorf("unable to determine storage version for %s", gr)
+	}
+
+	if err := m.migrateResources(ctx, gr.WithVersion(version)); err != nil {
+		return err
+	}
+
+	crd.Status.StoredVersions = []string{version}
+	if _, err := crdClient.UpdateStatus(crd); err != nil {
+		return fmt.Errorf("unable to update stored versions for %s: %v", gr, err)
+	}
+	logger.Infof("Migrated resources to storage version %q", version)
+	return nil
+}
+
+func (m *Migrator) migrateResources(ctx context.Context, gvr schema.GroupVersionResource) error {
+	logger := logging.FromContext(ctx)
+	client := m.dynamicClient.Resource(gvr)
+
+	opts := metav1.ListOptions{Limit: pageSize}
+	for {
+		list, err := client.List(opts)
+		if err != nil {
+			return fmt.Errorf("unable to fetch resources for %s: %v", gvr, err)
+		}
+
+		for i := range list.Items {
+			item := &list.Items[i]
+			_, err := client.Namespace(item.GetNamespace()).Update(item, metav1.UpdateOptions{})
+			switch {
+			case apierrs.IsNotFound(err):
+				// Deleted since we listed it, nothing left to migrate.
+				logger.Infof("Ignoring resource %s/%s since it was deleted", item.GetNamespace(), item.GetName())
+			case apierrs.IsConflict(err):
+				// Somebody else wrote it since we listed it, which
+				// rewrote it at the storage version already.
+				logger.Infof("Ignoring resource %s/%s since it was updated", item.GetNamespace(), item.GetName())
+			case err != nil:
+				return fmt.Errorf("unable to migrate resource %s/%s: %v", item.GetNamespace(), item.GetName(), err)
+			}
+		}
+
+		if list.GetContinue() == "" {
+			return nil
+		}
+		opts.Continue = list.GetContinue()
+	}
+}
+
+// storageVersion returns the version the CRD is stored at.
+func storageVersion(crd *apixv1beta1.CustomResourceDefinition) string {
+	for _, v := range crd.Spec.Versions {
+		if v.Storage {
+			return v.Name
+		}
+	}
+	return crd.Spec.Version
+}
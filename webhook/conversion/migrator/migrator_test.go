@@ -0,0 +1,148 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	apixv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apixfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clientgotesting "k8s.io/client-go/testing"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+var fakeGR = schema.GroupResource{Group: "pkg.knative.dev", Resource: "fakes"}
+
+func fakeCRD() *apixv1beta1.CustomResourceDefinition {
+	return &apixv1beta1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fakeGR.String(),
+		},
+		Spec: apixv1beta1.CustomResourceDefinitionSpec{
+			Group: fakeGR.Group,
+			Versions: []apixv1beta1.CustomResourceDefinitionVersion{
+				{Name: "v1alpha1", Served: true},
+				{Name: "v1", Served: true, Storage: true},
+			},
+		},
+		Status: apixv1beta1.CustomResourceDefinitionStatus{
+			StoredVersions: []string{"v1alpha1", "v1"},
+		},
+	}
+}
+
+func fake(namespace, name string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("pkg.knative.dev/v1")
+	u.SetKind("Fake")
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	return u
+}
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name        string
+		crd         *apixv1beta1.CustomResourceDefinition
+		updateErr   error
+		wantUpdates []string
+		wantStored  []string
+		wantErr     bool
+	}{{
+		name:        "migrates and prunes stored versions",
+		crd:         fakeCRD(),
+		wantUpdates: []string{"ns-1/first", "ns-2/second"},
+		wantStored:  []string{"v1"},
+	}, {
+		name: "single version crd",
+		crd: func() *apixv1beta1.CustomResourceDefinition {
+			crd := fakeCRD()
+			crd.Spec.Versions = nil
+			crd.Spec.Version = "v1"
+			return crd
+		}(),
+		wantUpdates: []string{"ns-1/first", "ns-2/second"},
+		wantStored:  []string{"v1"},
+	}, {
+		name:        "ignores deleted resources",
+		crd:         fakeCRD(),
+		updateErr:   apierrs.NewNotFound(fakeGR, "first"),
+		wantUpdates: []string{"ns-1/first", "ns-2/second"},
+		wantStored:  []string{"v1"},
+	}, {
+		name:        "update failure",
+		crd:         fakeCRD(),
+		updateErr:   errors.New("boom"),
+		wantUpdates: []string{"ns-1/first"},
+		wantStored:  []string{"v1alpha1", "v1"},
+		wantErr:     true,
+	}, {
+		name:    "missing crd",
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(),
+				fake("ns-1", "first"), fake("ns-2", "second"))
+			var updates []string
+			dynamicClient.PrependReactor("update", "*", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+				obj := action.(clientgotesting.UpdateAction).GetObject().(*unstructured.Unstructured)
+				updates = append(updates, obj.GetNamespace()+"/"+obj.GetName())
+				if test.updateErr != nil {
+					return true, nil, test.updateErr
+				}
+				return false, nil, nil
+			})
+
+			var crdClient *apixfake.Clientset
+			if test.crd != nil {
+				crdClient = apixfake.NewSimpleClientset(test.crd)
+			} else {
+				crdClient = apixfake.NewSimpleClientset()
+			}
+
+			err := New(dynamicClient, crdClient).Migrate(TestContextWithLogger(t), fakeGR)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Migrate() = %v, wantErr %v", err, test.wantErr)
+			}
+			if diff := cmp.Diff(test.wantUpdates, updates); diff != "" {
+				t.Errorf("Updates (-want, +got) = %v", diff)
+			}
+			if test.crd == nil {
+				return
+			}
+
+			crd, err := crdClient.ApiextensionsV1beta1().CustomResourceDefinitions().Get(fakeGR.String(), metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Failed to get crd: %v", err)
+			}
+			if diff := cmp.Diff(test.wantStored, crd.Status.StoredVersions); diff != "" {
+				t.Errorf("StoredVersions (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apiextensionsinformers "k8s.io/apiextensions-apiserver/pkg/client/informers/externalversions"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+
+	"knative.dev/pkg/logging"
+)
+
+// PatchConversionCABundles sets spec.conversion.webhookClientConfig.caBundle
+// to caCert on the CustomResourceDefinitions matching the given selector.
+// CRDs that do not use the Webhook conversion strategy are left untouched, so
+// that a single binary can host conversion for a subset of the CRDs in a
+// shared cluster.
+func PatchConversionCABundles(ctx context.Context, client apiextensionsclientset.Interface, selector labels.Selector, caCert []byte) error {
+	crds, err := client.ApiextensionsV1beta1().CustomResourceDefinitions().List(metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return fmt.Errorf("failed to list CustomResourceDefinitions: %v", err)
+	}
+
+	for i := range crds.Items {
+		if err := patchConversionCABundle(ctx, client, &crds.Items[i], caCert); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// patchConversionCABundle sets spec.conversion.webhookClientConfig.caBundle
+// to caCert on the CustomResourceDefinition, if it uses webhook conversion.
+func patchConversionCABundle(ctx context.Context, client apiextensionsclientset.Interface, crd *apiextensionsv1beta1.CustomResourceDefinition, caCert []byte) error {
+	logger := logging.FromContext(ctx)
+	conversion := crd.Spec.Conversion
+	if conversion == nil || conversion.Strategy != apiextensionsv1beta1.WebhookConverter || conversion.WebhookClientConfig == nil {
+		logger.Infof("Skipping CustomResourceDefinition %s, which does not use webhook conversion", crd.Name)
+		return nil
+	}
+	if bytes.Equal(conversion.WebhookClientConfig.CABundle, caCert) {
+		return nil
+	}
+
+	updated := crd.DeepCopy()
+	updated.Spec.Conversion.WebhookClientConfig.CABundle = caCert
+	if _, err := client.ApiextensionsV1beta1().CustomResourceDefinitions().Update(updated); err != nil {
+		return fmt.Errorf("failed to update the caBundle of CustomResourceDefinition %s: %v", crd.Name, err)
+	}
+	logger.Infof("Updated the conversion caBundle of CustomResourceDefinition %s", crd.Name)
+	return nil
+}
+
+// conversionCRDResyncPeriod is the resync period of the informer of the
+// CustomResourceDefinitions selected by ConversionCRDSelector.
+const conversionCRDResyncPeriod = 10 * time.Hour
+
+// watchConversionCRDs patches the caBundle of the CustomResourceDefinitions
+// selected by ConversionCRDSelector as they are created or updated, e.g.
+// labeled to match it after startup, until stop is closed.
+func (ac *Webhook) watchConversionCRDs(ctx context.Context, stop <-chan struct{}) {
+	logger := logging.FromContext(ctx)
+	client, selector := ac.Options.ConversionCRDClient, ac.Options.ConversionCRDSelector
+	factory := apiextensionsinformers.NewSharedInformerFactoryWithOptions(client, conversionCRDResyncPeriod,
+		apiextensionsinformers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = selector.String()
+		}))
+
+	patch := func(obj interface{}) {
+		crd, ok := obj.(*apiextensionsv1beta1.CustomResourceDefinition)
+		if !ok || !selector.Matches(labels.Set(crd.Labels)) {
+			return
+		}
+		caCert, _ := ac.caBundle.Load().([]byte)
+		if err := patchConversionCABundle(ctx, client, crd, caCert); err != nil {
+			logger.Errorw("failed to patch conversion webhook caBundle", zap.Error(err))
+		}
+	}
+	factory.Apiextensions().V1beta1().CustomResourceDefinitions().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    patch,
+		UpdateFunc: func(_, obj interface{}) { patch(obj) },
+	})
+	factory.Start(stop)
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"crypto/tls"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	fakeapiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+func conversionCRD(name string, lbls map[string]string, strategy apiextensionsv1beta1.ConversionStrategyType, caBundle []byte) *apiextensionsv1beta1.CustomResourceDefinition {
+	crd := &apiextensionsv1beta1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: lbls,
+		},
+		Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{
+			Conversion: &apiextensionsv1beta1.CustomResourceConversion{
+				Strategy: strategy,
+			},
+		},
+	}
+	if strategy == apiextensionsv1beta1.WebhookConverter {
+		crd.Spec.Conversion.WebhookClientConfig = &apiextensionsv1beta1.WebhookClientConfig{
+			CABundle: caBundle,
+		}
+	}
+	return crd
+}
+
+func TestPatchConversionCABundles(t *testing.T) {
+	ours := map[string]string{"conversion.knative.dev/webhook": "ours"}
+	theirs := map[string]string{"conversion.knative.dev/webhook": "theirs"}
+	oldCA, newCA := []byte("old"), []byte("new")
+
+	client := fakeapiextensionsclientset.NewSimpleClientset(
+		conversionCRD("selected.knative.dev", ours, apiextensionsv1beta1.WebhookConverter, oldCA),
+		conversionCRD("uptodate.knative.dev", ours, apiextensionsv1beta1.WebhookConverter, newCA),
+		conversionCRD("noconversion.knative.dev", ours, apiextensionsv1beta1.NoneConverter, nil),
+		conversionCRD("other.knative.dev", theirs, apiextensionsv1beta1.WebhookConverter, oldCA),
+	)
+
+	ctx := TestContextWithLogger(t)
+	if err := PatchConversionCABundles(ctx, client, labels.SelectorFromSet(ours), newCA); err != nil {
+		t.Fatalf("PatchConversionCABundles() = %v", err)
+	}
+
+	want := map[string][]byte{
+		"selected.knative.dev":     newCA,
+		"uptodate.knative.dev":     newCA,
+		"noconversion.knative.dev": nil,
+		"other.knative.dev":        oldCA,
+	}
+	for name, ca := range want {
+		crd, err := client.ApiextensionsV1beta1().CustomResourceDefinitions().Get(name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Get(%s) = %v", name, err)
+		}
+		var got []byte
+		if cc := crd.Spec.Conversion.WebhookClientConfig; cc != nil {
+			got = cc.CABundle
+		}
+		if diff := cmp.Diff(ca, got); diff != "" {
+			t.Errorf("caBundle of %s (-want, +got) = %v", name, diff)
+		}
+	}
+
+	updates := 0
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "update" {
+			updates++
+		}
+	}
+	if updates != 1 {
+		t.Errorf("Got %d updates, wanted 1", updates)
+	}
+}
+
+// rotatingCertificateSource is a CertificateSource whose CA bundle may be
+// rotated.
+type rotatingCertificateSource struct {
+	m  sync.Mutex
+	ca []byte
+}
+
+func (s *rotatingCertificateSource) Certificate() (*tls.Certificate, error) {
+	return &tls.Certificate{}, nil
+}
+
+func (s *rotatingCertificateSource) CABundle() ([]byte, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.ca, nil
+}
+
+func (s *rotatingCertificateSource) rotate(ca []byte) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.ca = ca
+}
+
+func TestWatchCABundle(t *testing.T) {
+	defer func(interval time.Duration) {
+		caBundleCheckInterval = interval
+	}(caBundleCheckInterval)
+	caBundleCheckInterval = time.Millisecond
+
+	ours := map[string]string{"conversion.knative.dev/webhook": "ours"}
+	oldCA, newCA := []byte("old"), []byte("new")
+	client := fakeapiextensionsclientset.NewSimpleClientset(
+		conversionCRD("selected.knative.dev", ours, apiextensionsv1beta1.WebhookConverter, oldCA))
+	ac := &Webhook{
+		Options: ControllerOptions{
+			ConversionCRDSelector: labels.SelectorFromSet(ours),
+			ConversionCRDClient:   client,
+		},
+	}
+	source := &rotatingCertificateSource{ca: oldCA}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go ac.watchCABundle(TestContextWithLogger(t), source, oldCA, stop)
+
+	source.rotate(newCA)
+	var got []byte
+	if err := wait.PollImmediate(time.Millisecond, 5*time.Second, func() (bool, error) {
+		crd, err := client.ApiextensionsV1beta1().CustomResourceDefinitions().Get("selected.knative.dev", metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		got = crd.Spec.Conversion.WebhookClientConfig.CABundle
+		return cmp.Equal(newCA, got), nil
+	}); err != nil {
+		t.Errorf("caBundle = %q, wanted %q: %v", got, newCA, err)
+	}
+}
+
+func TestWatchConversionCRDs(t *testing.T) {
+	ours := map[string]string{"conversion.knative.dev/webhook": "ours"}
+	theirs := map[string]string{"conversion.knative.dev/webhook": "theirs"}
+	ca := []byte("ca")
+	client := fakeapiextensionsclientset.NewSimpleClientset()
+	ac := &Webhook{
+		Options: ControllerOptions{
+			ConversionCRDSelector: labels.SelectorFromSet(ours),
+			ConversionCRDClient:   client,
+		},
+	}
+	ac.caBundle.Store(ca)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	ac.watchConversionCRDs(TestContextWithLogger(t), stop)
+
+	// CRDs created after startup are patched too.
+	crds := client.ApiextensionsV1beta1().CustomResourceDefinitions()
+	for _, crd := range []*apiextensionsv1beta1.CustomResourceDefinition{
+		conversionCRD("selected.knative.dev", ours, apiextensionsv1beta1.WebhookConverter, nil),
+		conversionCRD("other.knative.dev", theirs, apiextensionsv1beta1.WebhookConverter, nil),
+	} {
+		if _, err := crds.Create(crd); err != nil {
+			t.Fatalf("Create(%s) = %v", crd.Name, err)
+		}
+	}
+
+	var got []byte
+	if err := wait.PollImmediate(time.Millisecond, 5*time.Second, func() (bool, error) {
+		crd, err := crds.Get("selected.knative.dev", metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		got = crd.Spec.Conversion.WebhookClientConfig.CABundle
+		return cmp.Equal(ca, got), nil
+	}); err != nil {
+		t.Errorf("caBundle = %q, wanted %q: %v", got, ca, err)
+	}
+
+	crd, err := crds.Get("other.knative.dev", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get(other.knative.dev) = %v", err)
+	}
+	if got := crd.Spec.Conversion.WebhookClientConfig.CABundle; got != nil {
+		t.Errorf("caBundle of other.knative.dev = %q, wanted none", got)
+	}
+}
+
+func TestNewConversionCRDSelectorWithoutClient(t *testing.T) {
+	opts := newDefaultOptions()
+	opts.ConversionCRDSelector = labels.Everything()
+	if _, err := NewTestWebhook(nil, opts, TestLogger(t)); err == nil {
+		t.Error("New() = nil, wanted an error for a selector without client")
+	}
+}
@@ -0,0 +1,386 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opencensus.io/trace"
+	apixv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"knative.dev/pkg/apis"
+	apistesting "knative.dev/pkg/apis/testing"
+	. "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/metrics/metricstest"
+)
+
+// hubResource is the hub version of the kind used to test conversion.
+type hubResource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec hubResourceSpec `json:"spec,omitempty"`
+}
+
+type hubResourceSpec struct {
+	Name  string `json:"name,omitempty"`
+	Count int    `json:"count,omitempty"`
+}
+
+func (r *hubResource) DeepCopyObject() runtime.Object {
+	c := *r
+	r.ObjectMeta.DeepCopyInto(&c.ObjectMeta)
+	return &c
+}
+
+func (r *hubResource) ConvertUp(context.Context, apis.Convertible) error {
+	return fmt.Errorf("%T is the highest known version", r)
+}
+
+func (r *hubResource) ConvertDown(context.Context, apis.Convertible) error {
+	return fmt.Errorf("%T is the highest known version", r)
+}
+
+// spokeResource is a spoke version of the kind used to test conversion.
+type spokeResource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec spokeResourceSpec `json:"spec,omitempty"`
+}
+
+type spokeResourceSpec struct {
+	Title string `json:"title,omitempty"`
+}
+
+func (r *spokeResource) DeepCopyObject() runtime.Object {
+	c := *r
+	r.ObjectMeta.DeepCopyInto(&c.ObjectMeta)
+	return &c
+}
+
+func (r *spokeResource) ConvertUp(_ context.Context, to apis.Convertible) error {
+	switch sink := to.(type) {
+	case *hubResource:
+		sink.ObjectMeta = r.ObjectMeta
+		sink.Spec.Name = r.Spec.Title
+		return nil
+	default:
+		return fmt.Errorf("unknown version, got: %T", sink)
+	}
+}
+
+func (r *spokeResource) ConvertDown(_ context.Context, from apis.Convertible) error {
+	switch source := from.(type) {
+	case *hubResource:
+		r.ObjectMeta = source.ObjectMeta
+		r.Spec.Title = source.Spec.Name
+		if strings.HasPrefix(source.Spec.Name, "bad") {
+			return fmt.Errorf("cannot convert %q", source.Spec.Name)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown version, got: %T", source)
+	}
+}
+
+var conversionGroupKind = schema.GroupKind{Group: "pkg.knative.dev", Kind: "Resource"}
+
+func newTestConversionController() ConversionController {
+	return NewConversionController(map[schema.GroupKind]GroupKindConversion{
+		conversionGroupKind: {
+			HubVersion: "v2",
+			Zygotes: map[string]ConvertibleObject{
+				"v1":      &spokeResource{},
+				"v1beta1": &spokeResource{},
+				"v2":      &hubResource{},
+			},
+		},
+	})
+}
+
+func rawObject(t *testing.T, obj interface{}) runtime.RawExtension {
+	t.Helper()
+	b, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatalf("Failed to marshal %#v: %v", obj, err)
+	}
+	return runtime.RawExtension{Raw: b}
+}
+
+func TestConversion(t *testing.T) {
+	meta := metav1.ObjectMeta{
+		Name:      testResourceName,
+		Namespace: testNamespace,
+	}
+	spoke := func(version, title string) *spokeResource {
+		return &spokeResource{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "pkg.knative.dev/" + version, Kind: "Resource"},
+			ObjectMeta: meta,
+			Spec:       spokeResourceSpec{Title: title},
+		}
+	}
+	hub := func(name string) *hubResource {
+		return &hubResource{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "pkg.knative.dev/v2", Kind: "Resource"},
+			ObjectMeta: meta,
+			Spec:       hubResourceSpec{Name: name},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		desired string
+		in      []interface{}
+		want    []interface{}
+		wantErr string
+	}{{
+		name:    "spoke to hub",
+		desired: "pkg.knative.dev/v2",
+		in:      []interface{}{spoke("v1", "foo"), spoke("v1beta1", "bar")},
+		want:    []interface{}{hub("foo"), hub("bar")},
+	}, {
+		name:    "hub to spoke",
+		desired: "pkg.knative.dev/v1",
+		in:      []interface{}{hub("foo")},
+		want:    []interface{}{spoke("v1", "foo")},
+	}, {
+		name:    "spoke to spoke",
+		desired: "pkg.knative.dev/v1beta1",
+		in:      []interface{}{spoke("v1", "foo")},
+		want:    []interface{}{spoke("v1beta1", "foo")},
+	}, {
+		name:    "hub to hub",
+		desired: "pkg.knative.dev/v2",
+		in:      []interface{}{hub("foo")},
+		want:    []interface{}{hub("foo")},
+	}, {
+		name:    "conversion error",
+		desired: "pkg.knative.dev/v1",
+		in:      []interface{}{hub("foo"), hub("bad")},
+		wantErr: `cannot convert "bad"`,
+	}, {
+		name:    "unknown version",
+		desired: "pkg.knative.dev/v3",
+		in:      []interface{}{hub("foo")},
+		wantErr: "unhandled version",
+	}, {
+		name:    "unknown kind",
+		desired: "pkg.knative.dev/v2",
+		in: []interface{}{&hubResource{
+			TypeMeta: metav1.TypeMeta{APIVersion: "pkg.knative.dev/v2", Kind: "Other"},
+		}},
+		wantErr: "unhandled kind",
+	}, {
+		name:    "different group",
+		desired: "other.knative.dev/v2",
+		in:      []interface{}{hub("foo")},
+		wantErr: "cannot convert",
+	}, {
+		name:    "bad desired version",
+		desired: "a/b/c",
+		in:      []interface{}{hub("foo")},
+		wantErr: "error parsing desired api version",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := &apixv1beta1.ConversionRequest{
+				UID:               "some-uid",
+				DesiredAPIVersion: test.desired,
+			}
+			for _, obj := range test.in {
+				req.Objects = append(req.Objects, rawObject(t, obj))
+			}
+
+			got := newTestConversionController().Convert(TestContextWithLogger(t), req)
+			if got.UID != req.UID {
+				t.Errorf("UID = %q, wanted %q", got.UID, req.UID)
+			}
+			if test.wantErr != "" {
+				if got.Result.Status != metav1.StatusFailure || !strings.Contains(got.Result.Message, test.wantErr) {
+					t.Errorf("Result = %+v, wanted failure containing %q", got.Result, test.wantErr)
+				}
+				if len(got.ConvertedObjects) != 0 {
+					t.Errorf("ConvertedObjects = %v, wanted none on failure", got.ConvertedObjects)
+				}
+				return
+			}
+
+			if got.Result.Status != metav1.StatusSuccess {
+				t.Fatalf("Result = %+v, wanted success", got.Result)
+			}
+			var want []runtime.RawExtension
+			for _, obj := range test.want {
+				want = append(want, rawObject(t, obj))
+			}
+			if diff := cmp.Diff(want, got.ConvertedObjects); diff != "" {
+				t.Errorf("ConvertedObjects (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestConversionPreservesOrder(t *testing.T) {
+	const n = 5 * conversionWorkers
+	req := &apixv1beta1.ConversionRequest{
+		DesiredAPIVersion: "pkg.knative.dev/v2",
+	}
+	var want []runtime.RawExtension
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("name-%d", i)
+		req.Objects = append(req.Objects, rawObject(t, &spokeResource{
+			TypeMeta: metav1.TypeMeta{APIVersion: "pkg.knative.dev/v1", Kind: "Resource"},
+			Spec:     spokeResourceSpec{Title: name},
+		}))
+		want = append(want, rawObject(t, &hubResource{
+			TypeMeta: metav1.TypeMeta{APIVersion: "pkg.knative.dev/v2", Kind: "Resource"},
+			Spec:     hubResourceSpec{Name: name},
+		}))
+	}
+
+	got := newTestConversionController().Convert(TestContextWithLogger(t), req)
+	if got.Result.Status != metav1.StatusSuccess {
+		t.Fatalf("Result = %+v, wanted success", got.Result)
+	}
+	if diff := cmp.Diff(want, got.ConvertedObjects); diff != "" {
+		t.Errorf("ConvertedObjects (-want, +got) = %v", diff)
+	}
+}
+
+func TestConversionRoundTrip(t *testing.T) {
+	apistesting.CheckRoundTrip(context.Background(), t, &spokeResource{}, &hubResource{})
+}
+
+func TestServeConversion(t *testing.T) {
+	_, ac := newNonRunningTestWebhook(t, newDefaultOptions())
+	ac.ConversionControllers = map[string]ConversionController{
+		"/convert": newTestConversionController(),
+	}
+
+	body, err := json.Marshal(apixv1beta1.ConversionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1beta1", Kind: "ConversionReview"},
+		Request: &apixv1beta1.ConversionRequest{
+			UID:               "some-uid",
+			DesiredAPIVersion: "pkg.knative.dev/v2",
+			Objects: []runtime.RawExtension{rawObject(t, &spokeResource{
+				TypeMeta: metav1.TypeMeta{APIVersion: "pkg.knative.dev/v1", Kind: "Resource"},
+				Spec:     spokeResourceSpec{Title: "foo"},
+			})},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal review: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/convert", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	ac.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Code = %d, wanted %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var got apixv1beta1.ConversionReview
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	want := []runtime.RawExtension{rawObject(t, &hubResource{
+		TypeMeta: metav1.TypeMeta{APIVersion: "pkg.knative.dev/v2", Kind: "Resource"},
+		Spec:     hubResourceSpec{Name: "foo"},
+	})}
+	if diff := cmp.Diff(want, got.Response.ConvertedObjects); diff != "" {
+		t.Errorf("ConvertedObjects (-want, +got) = %v", diff)
+	}
+	if got.Kind != "ConversionReview" {
+		t.Errorf("Kind = %q, wanted ConversionReview", got.Kind)
+	}
+}
+
+// spanRecorder is a trace.Exporter that remembers the exported spans.
+// Conversion workers end their spans concurrently.
+type spanRecorder struct {
+	m     sync.Mutex
+	spans []*trace.SpanData
+}
+
+func (sr *spanRecorder) ExportSpan(s *trace.SpanData) {
+	sr.m.Lock()
+	defer sr.m.Unlock()
+	sr.spans = append(sr.spans, s)
+}
+
+// recorded returns the spans exported so far.
+func (sr *spanRecorder) recorded() []*trace.SpanData {
+	sr.m.Lock()
+	defer sr.m.Unlock()
+	return append([]*trace.SpanData(nil), sr.spans...)
+}
+
+func TestConversionInstrumentation(t *testing.T) {
+	resetMetrics()
+	sr := &spanRecorder{}
+	trace.RegisterExporter(sr)
+	defer trace.UnregisterExporter(sr)
+
+	ctx, span := trace.StartSpan(TestContextWithLogger(t), "test", trace.WithSampler(trace.AlwaysSample()))
+	req := &apixv1beta1.ConversionRequest{
+		DesiredAPIVersion: "pkg.knative.dev/v2",
+	}
+	for i := 0; i < 2; i++ {
+		req.Objects = append(req.Objects, rawObject(t, &spokeResource{
+			TypeMeta: metav1.TypeMeta{APIVersion: "pkg.knative.dev/v1", Kind: "Resource"},
+		}))
+	}
+	if got := newTestConversionController().Convert(ctx, req); got.Result.Status != metav1.StatusSuccess {
+		t.Fatalf("Result = %+v, wanted success", got.Result)
+	}
+	span.End()
+
+	wantTags := map[string]string{
+		kindGroupKey.Name():         "pkg.knative.dev",
+		kindKindKey.Name():          "Resource",
+		fromVersionKey.Name():       "v1",
+		toVersionKey.Name():         "v2",
+		conversionSuccessKey.Name(): "true",
+	}
+	metricstest.CheckCountData(t, "conversion_count", wantTags, 2)
+
+	var got []string
+	for _, s := range sr.recorded() {
+		got = append(got, fmt.Sprintf("%s %v->%v", s.Name, s.Attributes["from_version"], s.Attributes["to_version"]))
+	}
+	want := []string{
+		"conversion/Resource.pkg.knative.dev v1->v2",
+		"conversion/Resource.pkg.knative.dev v1->v2",
+		"test <nil>-><nil>",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Spans (-want, +got) = %v", diff)
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// deprecatedTag is the struct tag used to mark fields as deprecated,
+	// e.g. `json:"foo,omitempty" deprecated:"true"`.
+	deprecatedTag = "deprecated"
+
+	// DeprecatedFieldsAnnotation is the audit annotation under which the
+	// ResourceAdmissionController reports the deprecated fields that a
+	// request sets.
+	DeprecatedFieldsAnnotation = "deprecated-fields"
+)
+
+// DeprecatedFields walks the provided object looking for fields whose struct
+// tag carries `deprecated:"true"`, and returns the (json) paths of those that
+// are set.  When original is non-nil, only deprecated fields whose value
+// differs from the original are reported, so that clients aren't nagged
+// about fields they did not touch.  The returned paths are sorted.
+func DeprecatedFields(obj, original interface{}) []string {
+	var paths []string
+	walkDeprecated(reflect.ValueOf(obj), reflect.ValueOf(original), "", &paths)
+	sort.Strings(paths)
+	return paths
+}
+
+func walkDeprecated(v, orig reflect.Value, path string, paths *[]string) {
+	v, orig = reflect.Indirect(v), reflect.Indirect(orig)
+	if !v.IsValid() {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		var ov reflect.Value
+		if orig.IsValid() && orig.Kind() == reflect.Interface {
+			ov = orig.Elem()
+		}
+		walkDeprecated(v.Elem(), ov, path, paths)
+
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			tf := t.Field(i)
+			if tf.PkgPath != "" && !tf.Anonymous {
+				// Unexported field.
+				continue
+			}
+			name, inlined := jsonName(tf)
+			if name == "-" {
+				continue
+			}
+			fieldPath := path
+			if !inlined {
+				fieldPath = joinPath(path, name)
+			}
+
+			fv := v.Field(i)
+			var ofv reflect.Value
+			if orig.IsValid() && orig.Type() == t {
+				ofv = orig.Field(i)
+			}
+
+			if tf.Tag.Get(deprecatedTag) == "true" {
+				if apis.NonZero(fv) && (!ofv.IsValid() || !reflect.DeepEqual(fv.Interface(), ofv.Interface())) {
+					*paths = append(*paths, fieldPath)
+				}
+				continue
+			}
+			walkDeprecated(fv, ofv, fieldPath, paths)
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			var ov reflect.Value
+			if orig.IsValid() && orig.Kind() == v.Kind() && i < orig.Len() {
+				ov = orig.Index(i)
+			}
+			walkDeprecated(v.Index(i), ov, fmt.Sprintf("%s[%d]", path, i), paths)
+		}
+
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			var ov reflect.Value
+			if orig.IsValid() && orig.Kind() == reflect.Map {
+				ov = orig.MapIndex(k)
+			}
+			walkDeprecated(v.MapIndex(k), ov, fmt.Sprintf("%s[%v]", path, k.Interface()), paths)
+		}
+	}
+}
+
+// jsonName returns the serialized name of the field and whether the field's
+// members are inlined into the enclosing object.
+func jsonName(tf reflect.StructField) (string, bool) {
+	tag := tf.Tag.Get("json")
+	name := strings.Split(tag, ",")[0]
+	if name == "" {
+		if tf.Anonymous || strings.Contains(tag, "inline") {
+			return "", true
+		}
+		return tf.Name, false
+	}
+	return name, false
+}
+
+func joinPath(base, name string) string {
+	if base == "" {
+		return name
+	}
+	return base + "." + name
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type deprecatedInner struct {
+	Current string `json:"current,omitempty"`
+	Old     string `json:"old,omitempty" deprecated:"true"`
+}
+
+type deprecatedSpec struct {
+	deprecatedInner `json:",inline"`
+
+	Name      string            `json:"name,omitempty"`
+	OldName   string            `json:"oldName,omitempty" deprecated:"true"`
+	OldPtr    *deprecatedInner  `json:"oldPtr,omitempty" deprecated:"true"`
+	NoJSONTag string            `deprecated:"true"`
+	Nested    *deprecatedInner  `json:"nested,omitempty"`
+	Items     []deprecatedInner `json:"items,omitempty"`
+}
+
+type deprecatedResource struct {
+	Spec deprecatedSpec `json:"spec,omitempty"`
+}
+
+func TestDeprecatedFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		obj      interface{}
+		original interface{}
+		want     []string
+	}{{
+		name: "nil",
+	}, {
+		name: "nothing deprecated set",
+		obj: &deprecatedResource{
+			Spec: deprecatedSpec{
+				Name:   "foo",
+				Nested: &deprecatedInner{Current: "bar"},
+			},
+		},
+	}, {
+		name: "deprecated fields set on create",
+		obj: &deprecatedResource{
+			Spec: deprecatedSpec{
+				deprecatedInner: deprecatedInner{Old: "a"},
+				OldName:         "b",
+				OldPtr:          &deprecatedInner{Current: "x"},
+				NoJSONTag:       "c",
+				Nested:          &deprecatedInner{Old: "d"},
+				Items:           []deprecatedInner{{}, {Old: "e"}},
+			},
+		},
+		want: []string{
+			"spec.NoJSONTag",
+			"spec.items[1].old",
+			"spec.nested.old",
+			"spec.old",
+			"spec.oldName",
+			"spec.oldPtr",
+		},
+	}, {
+		name: "unchanged deprecated fields on update",
+		obj: &deprecatedResource{
+			Spec: deprecatedSpec{
+				OldName: "b",
+				Nested:  &deprecatedInner{Old: "d"},
+			},
+		},
+		original: &deprecatedResource{
+			Spec: deprecatedSpec{
+				OldName: "b",
+				Nested:  &deprecatedInner{Old: "d"},
+			},
+		},
+	}, {
+		name: "changed deprecated fields on update",
+		obj: &deprecatedResource{
+			Spec: deprecatedSpec{
+				OldName: "b",
+				Nested:  &deprecatedInner{Old: "changed"},
+				Items:   []deprecatedInner{{Old: "new"}},
+			},
+		},
+		original: &deprecatedResource{
+			Spec: deprecatedSpec{
+				OldName: "b",
+				Nested:  &deprecatedInner{Old: "d"},
+			},
+		},
+		want: []string{
+			"spec.items[0].old",
+			"spec.nested.old",
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := DeprecatedFields(test.obj, test.original)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("DeprecatedFields (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
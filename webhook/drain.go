@@ -0,0 +1,78 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// drain marks the webhook as draining and waits out the drain duration,
+// during which requests continue to be served.  Concurrent and repeated
+// calls wait for the same drain (e.g. the pre-stop hook and the stop
+// channel firing).
+func (ac *Webhook) drain() {
+	ac.drainOnce.Do(func() {
+		ac.Logger.Infof("Draining webhook for %v", ac.Options.DrainDuration)
+		atomic.StoreInt32(&ac.draining, 1)
+		time.Sleep(ac.Options.DrainDuration)
+	})
+}
+
+// isDraining returns whether drain has been called.
+func (ac *Webhook) isDraining() bool {
+	return atomic.LoadInt32(&ac.draining) == 1
+}
+
+// shutdown stops the server.  Without a drain duration the server is
+// closed immediately, otherwise we drain and then wait (at most the drain
+// duration again) for the in-flight requests to complete.
+func (ac *Webhook) shutdown(server *http.Server) error {
+	if ac.Options.DrainDuration == 0 {
+		return server.Close()
+	}
+	ac.drain()
+
+	ctx, cancel := context.WithTimeout(context.Background(), ac.Options.DrainDuration)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		return server.Close()
+	}
+	return nil
+}
+
+// serveProbe handles requests for the readiness and pre-stop paths,
+// returning whether the request was handled.
+func (ac *Webhook) serveProbe(w http.ResponseWriter, r *http.Request) bool {
+	switch {
+	case ac.Options.ReadinessProbePath != "" && r.URL.Path == ac.Options.ReadinessProbePath:
+		if ac.Options.FailReadinessBeforeDrain && ac.isDraining() {
+			http.Error(w, "webhook is draining", http.StatusServiceUnavailable)
+			return true
+		}
+		w.WriteHeader(http.StatusOK)
+		return true
+
+	case ac.Options.PreStopPath != "" && r.URL.Path == ac.Options.PreStopPath:
+		ac.drain()
+		w.WriteHeader(http.StatusOK)
+		return true
+	}
+	return false
+}
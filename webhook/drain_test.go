@@ -0,0 +1,99 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func probe(ac *Webhook, path string) int {
+	rec := httptest.NewRecorder()
+	ac.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec.Code
+}
+
+func TestReadinessProbe(t *testing.T) {
+	tests := []struct {
+		name          string
+		failReadiness bool
+		wantDraining  int
+	}{{
+		name:         "keep ready while draining",
+		wantDraining: http.StatusOK,
+	}, {
+		name:          "fail readiness before drain",
+		failReadiness: true,
+		wantDraining:  http.StatusServiceUnavailable,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := newDefaultOptions()
+			opts.ReadinessProbePath = "/ready"
+			opts.PreStopPath = "/prestop"
+			opts.FailReadinessBeforeDrain = test.failReadiness
+			opts.DrainDuration = 10 * time.Millisecond
+			_, ac := newNonRunningTestWebhook(t, opts)
+
+			if got := probe(ac, "/ready"); got != http.StatusOK {
+				t.Errorf("Readiness before drain = %d, wanted %d", got, http.StatusOK)
+			}
+
+			start := time.Now()
+			if got := probe(ac, "/prestop"); got != http.StatusOK {
+				t.Errorf("PreStop = %d, wanted %d", got, http.StatusOK)
+			}
+			if elapsed := time.Since(start); elapsed < opts.DrainDuration {
+				t.Errorf("PreStop returned after %v, wanted it to wait %v", elapsed, opts.DrainDuration)
+			}
+
+			if got := probe(ac, "/ready"); got != test.wantDraining {
+				t.Errorf("Readiness while draining = %d, wanted %d", got, test.wantDraining)
+			}
+		})
+	}
+}
+
+func TestShutdownWithoutDrain(t *testing.T) {
+	_, ac := newNonRunningTestWebhook(t, newDefaultOptions())
+	if err := ac.shutdown(&http.Server{}); err != nil {
+		t.Errorf("shutdown() = %v", err)
+	}
+	if ac.isDraining() {
+		t.Error("isDraining() = true, wanted no drain without a DrainDuration")
+	}
+}
+
+func TestShutdownDrains(t *testing.T) {
+	opts := newDefaultOptions()
+	opts.DrainDuration = 10 * time.Millisecond
+	_, ac := newNonRunningTestWebhook(t, opts)
+
+	start := time.Now()
+	if err := ac.shutdown(&http.Server{}); err != nil {
+		t.Errorf("shutdown() = %v", err)
+	}
+	if !ac.isDraining() {
+		t.Error("isDraining() = false, wanted a drain")
+	}
+	if elapsed := time.Since(start); elapsed < opts.DrainDuration {
+		t.Errorf("shutdown() returned after %v, wanted it to drain for %v", elapsed, opts.DrainDuration)
+	}
+}
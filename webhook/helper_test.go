@@ -98,7 +98,11 @@ func createTestConfigMap(t *testing.T, kubeClient kubernetes.Interface) error {
 
 func createSecureTLSClient(t *testing.T, kubeClient kubernetes.Interface, acOpts *ControllerOptions) (*http.Client, error) {
 	t.Helper()
-	tlsServerConfig, caCert, err := configureCerts(TestContextWithLogger(t), kubeClient, acOpts)
+	tlsServerConfig, source, err := configureCerts(TestContextWithLogger(t), kubeClient, acOpts)
+	if err != nil {
+		return nil, err
+	}
+	caCert, err := source.CABundle()
 	if err != nil {
 		return nil, err
 	}
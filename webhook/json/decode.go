@@ -0,0 +1,165 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"knative.dev/pkg/apis"
+)
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// Decode unmarshals the JSON in bts into the value pointed to by into.
+// In strict mode (disallowUnknownFields), fields that do not map onto
+// into are rejected with an *apis.FieldError naming each of their paths
+// (e.g. "spec.template.foo"), otherwise they are silently dropped.
+func Decode(bts []byte, into interface{}, disallowUnknownFields bool) error {
+	if !disallowUnknownFields {
+		return json.Unmarshal(bts, into)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(bts))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(into); err != nil {
+		if !strings.HasPrefix(err.Error(), "json: unknown field ") {
+			return err
+		}
+		// Go's error doesn't tell us where the field is, so work it out.
+		var raw interface{}
+		if json.Unmarshal(bts, &raw) != nil {
+			return err
+		}
+		if paths := UnknownFields(raw, reflect.TypeOf(into)); len(paths) > 0 {
+			return apis.ErrDisallowedFields(paths...)
+		}
+		return err
+	}
+	return nil
+}
+
+// UnknownFields returns the sorted paths of the fields within the
+// generic JSON value raw (as produced by json.Unmarshal into an
+// interface{}) that have no corresponding field in the type t.
+func UnknownFields(raw interface{}, t reflect.Type) []string {
+	var paths []string
+	walk(raw, t, "", &paths)
+	sort.Strings(paths)
+	return paths
+}
+
+func walk(raw interface{}, t reflect.Type, path string, paths *[]string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	// Types that decode themselves may accept anything.
+	if reflect.PtrTo(t).Implements(unmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := raw.(map[string]interface{})
+		if !ok {
+			return
+		}
+		fields := jsonFields(t)
+		for k, v := range obj {
+			ft, ok := fields[k]
+			if !ok {
+				// Unmarshal matches field names case-insensitively.
+				for name, f := range fields {
+					if strings.EqualFold(name, k) {
+						ft, ok = f, true
+						break
+					}
+				}
+			}
+			if !ok {
+				*paths = append(*paths, join(path, k))
+				continue
+			}
+			walk(v, ft, join(path, k), paths)
+		}
+
+	case reflect.Slice, reflect.Array:
+		arr, ok := raw.([]interface{})
+		if !ok {
+			return
+		}
+		for i, v := range arr {
+			walk(v, t.Elem(), path+"["+strconv.Itoa(i)+"]", paths)
+		}
+
+	case reflect.Map:
+		obj, ok := raw.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for k, v := range obj {
+			walk(v, t.Elem(), join(path, k), paths)
+		}
+	}
+}
+
+// jsonFields returns the types of the fields of struct type t keyed by
+// their JSON names, flattening embedded and inline structs as
+// encoding/json does.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if name == "" && f.Anonymous && ft.Kind() == reflect.Struct {
+			for k, v := range jsonFields(ft) {
+				if _, ok := fields[k]; !ok {
+					fields[k] = v
+				}
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			// Unexported.
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+func join(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
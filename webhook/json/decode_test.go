@@ -0,0 +1,130 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package json
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/apis"
+)
+
+type inner struct {
+	Value string `json:"value,omitempty"`
+}
+
+type testResource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec testSpec `json:"spec,omitempty"`
+}
+
+type testSpec struct {
+	inner `json:",inline"`
+
+	Name    string           `json:"name,omitempty"`
+	Ptr     *inner           `json:"ptr,omitempty"`
+	List    []inner          `json:"list,omitempty"`
+	Map     map[string]inner `json:"map,omitempty"`
+	URL     *apis.URL        `json:"url,omitempty"`
+	Any     interface{}      `json:"any,omitempty"`
+	Ignored string           `json:"-"`
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		strict  bool
+		want    *testResource
+		wantErr string
+	}{{
+		name:   "known fields",
+		json:   `{"apiVersion":"v1","metadata":{"name":"foo"},"spec":{"value":"a","name":"b","ptr":{"value":"c"},"list":[{"value":"d"}],"map":{"k":{"value":"e"}},"url":"http://x","any":{"whatever":1}}}`,
+		strict: true,
+		want: &testResource{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+			Spec: testSpec{
+				inner: inner{Value: "a"},
+				Name:  "b",
+				Ptr:   &inner{Value: "c"},
+				List:  []inner{{Value: "d"}},
+				Map:   map[string]inner{"k": {Value: "e"}},
+				URL:   &apis.URL{Scheme: "http", Host: "x"},
+				Any:   map[string]interface{}{"whatever": float64(1)},
+			},
+		},
+	}, {
+		name: "lenient",
+		json: `{"spec":{"name":"b","bogus":true}}`,
+		want: &testResource{
+			Spec: testSpec{Name: "b"},
+		},
+	}, {
+		name:    "unknown top level field",
+		json:    `{"bogus":true}`,
+		strict:  true,
+		wantErr: "must not set the field(s): bogus",
+	}, {
+		name:    "unknown nested fields",
+		json:    `{"metadata":{"nom":"foo"},"spec":{"ptr":{"bogus":1},"list":[{},{"bogus":2}],"map":{"k":{"bogus":3}},"Ignored":"x"}}`,
+		strict:  true,
+		wantErr: "must not set the field(s): metadata.nom, spec.Ignored, spec.list[1].bogus, spec.map.k.bogus, spec.ptr.bogus",
+	}, {
+		name:    "case insensitive match",
+		json:    `{"spec":{"NAME":"b","bogus":1}}`,
+		strict:  true,
+		wantErr: "must not set the field(s): spec.bogus",
+	}, {
+		name:    "malformed",
+		json:    `{"spec":`,
+		strict:  true,
+		wantErr: "unexpected EOF",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := &testResource{}
+			err := Decode([]byte(test.json), got, test.strict)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Fatalf("Decode() = %v, wanted %s", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() = %v", err)
+			}
+			if diff := cmp.Diff(test.want, got, cmp.AllowUnexported(testSpec{})); diff != "" {
+				t.Errorf("Decode (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestDecodeFieldError(t *testing.T) {
+	err := Decode([]byte(`{"spec":{"bogus":1}}`), &testResource{}, true)
+	if fe, ok := err.(*apis.FieldError); !ok {
+		t.Fatalf("Decode() = %T, wanted *apis.FieldError", err)
+	} else if diff := cmp.Diff([]string{"spec.bogus"}, fe.Paths); diff != "" {
+		t.Errorf("Paths (-want, +got) = %v", diff)
+	}
+}
@@ -17,7 +17,6 @@ limitations under the License.
 package webhook
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -40,6 +39,7 @@ import (
 	"knative.dev/pkg/apis/duck"
 	"knative.dev/pkg/kmp"
 	"knative.dev/pkg/logging"
+	webhookjson "knative.dev/pkg/webhook/json"
 )
 
 // ResourceCallback defines a signature for resource specific (Route, Configuration, etc.)
@@ -52,6 +52,12 @@ type ResourceCallback func(patches *[]jsonpatch.JsonPatchOperation, old GenericC
 // is denied. Mutations should be appended to the patches operations.
 type ResourceDefaulter func(patches *[]jsonpatch.JsonPatchOperation, crd GenericCRD) error
 
+// Wildcard may be used as the Version and/or Kind of the keys of the
+// handlers passed to NewResourceAdmissionController, to have the handler
+// serve all versions of the kind and/or all kinds of the group that have
+// no more specific handler.
+const Wildcard = "*"
+
 // GenericCRD is the interface definition that allows us to perform the generic
 // CRD actions like deciding whether to increment generation and so forth.
 type GenericCRD interface {
@@ -66,6 +72,9 @@ type ResourceAdmissionController struct {
 	options  ControllerOptions
 
 	disallowUnknownFields bool
+
+	// responses is nil unless ControllerOptions.ResponseCacheSize is set.
+	responses *responseCache
 }
 
 // NewResourceAdmissionController constructs a ResourceAdmissionController
@@ -73,29 +82,69 @@ func NewResourceAdmissionController(
 	handlers map[schema.GroupVersionKind]GenericCRD,
 	opts ControllerOptions,
 	disallowUnknownFields bool) AdmissionController {
-	return &ResourceAdmissionController{
+	ac := &ResourceAdmissionController{
 		handlers:              handlers,
 		options:               opts,
 		disallowUnknownFields: disallowUnknownFields,
 	}
+	if opts.ResponseCacheSize > 0 {
+		ac.responses = newResponseCache(opts.ResponseCacheSize, opts.ResponseCacheTTL)
+	}
+	return ac
 }
 
 func (ac *ResourceAdmissionController) Admit(ctx context.Context, request *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
 	logger := logging.FromContext(ctx)
 	switch request.Operation {
 	case admissionv1beta1.Create, admissionv1beta1.Update:
+	case admissionv1beta1.Delete:
+		if !ac.options.ValidateDeletes {
+			logger.Info("Not validating deletes, letting it through")
+			return &admissionv1beta1.AdmissionResponse{Allowed: true}
+		}
 	default:
 		logger.Infof("Unhandled webhook operation, letting it through %v", request.Operation)
 		return &admissionv1beta1.AdmissionResponse{Allowed: true}
 	}
 
-	patchBytes, err := ac.mutate(ctx, request)
+	if request.SubResource != "" && !ac.handles(request.Kind) {
+		// Some subresources (e.g. scale) are served using a different kind
+		// than the resource they belong to, so there is nothing for us to
+		// default or validate.
+		logger.Infof("Unhandled kind %v for subresource %q, letting it through", request.Kind, request.SubResource)
+		return &admissionv1beta1.AdmissionResponse{Allowed: true}
+	}
+
+	if ac.responses != nil {
+		if resp, ok := ac.responses.get(ctx, request); ok {
+			logger.Info("Serving cached admission response")
+			return resp
+		}
+	}
+
+	resp := ac.admit(ctx, request)
+	if ac.responses != nil {
+		ac.responses.add(request, resp)
+	}
+	return resp
+}
+
+func (ac *ResourceAdmissionController) admit(ctx context.Context, request *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
+	if request.Operation == admissionv1beta1.Delete {
+		if err := ac.validateDelete(ctx, request); err != nil {
+			return makeErrorStatus("validation failed: %v", err)
+		}
+		return &admissionv1beta1.AdmissionResponse{Allowed: true}
+	}
+
+	logger := logging.FromContext(ctx)
+	patchBytes, deprecated, err := ac.mutate(ctx, request)
 	if err != nil {
 		return makeErrorStatus("mutation failed: %v", err)
 	}
 	logger.Infof("Kind: %q PatchBytes: %v", request.Kind, string(patchBytes))
 
-	return &admissionv1beta1.AdmissionResponse{
+	resp := &admissionv1beta1.AdmissionResponse{
 		Patch:   patchBytes,
 		Allowed: true,
 		PatchType: func() *admissionv1beta1.PatchType {
@@ -103,6 +152,15 @@ func (ac *ResourceAdmissionController) Admit(ctx context.Context, request *admis
 			return &pt
 		}(),
 	}
+	if len(deprecated) > 0 {
+		// The admission API we serve has no notion of warnings, so surface
+		// these via the audit log for operators to chase down.
+		logger.Warnf("Request sets deprecated fields: %v", deprecated)
+		resp.AuditAnnotations = map[string]string{
+			DeprecatedFieldsAnnotation: strings.Join(deprecated, ","),
+		}
+	}
+	return resp
 }
 
 func (ac *ResourceAdmissionController) Register(ctx context.Context, kubeClient kubernetes.Interface, caCert []byte) error {
@@ -110,19 +168,27 @@ func (ac *ResourceAdmissionController) Register(ctx context.Context, kubeClient
 	logger := logging.FromContext(ctx)
 	failurePolicy := admissionregistrationv1beta1.Fail
 
+	operations := []admissionregistrationv1beta1.OperationType{
+		admissionregistrationv1beta1.Create,
+		admissionregistrationv1beta1.Update,
+	}
+	if ac.options.ValidateDeletes {
+		operations = append(operations, admissionregistrationv1beta1.Delete)
+	}
+
 	var rules []admissionregistrationv1beta1.RuleWithOperations
 	for gvk := range ac.handlers {
-		plural := strings.ToLower(inflect.Pluralize(gvk.Kind))
+		plural := Wildcard
+		if gvk.Kind != Wildcard {
+			plural = strings.ToLower(inflect.Pluralize(gvk.Kind))
+		}
 
 		rules = append(rules, admissionregistrationv1beta1.RuleWithOperations{
-			Operations: []admissionregistrationv1beta1.OperationType{
-				admissionregistrationv1beta1.Create,
-				admissionregistrationv1beta1.Update,
-			},
+			Operations: operations,
 			Rule: admissionregistrationv1beta1.Rule{
 				APIGroups:   []string{gvk.Group},
 				APIVersions: []string{gvk.Version},
-				Resources:   []string{plural + "/*"},
+				Resources:   []string{plural, plural + "/status", plural + "/scale"},
 			},
 		})
 	}
@@ -154,7 +220,9 @@ func (ac *ResourceAdmissionController) Register(ctx context.Context, kubeClient
 				},
 				CABundle: caCert,
 			},
-			FailurePolicy: &failurePolicy,
+			NamespaceSelector: ac.options.ResourceAdmissionNamespaceSelector,
+			ObjectSelector:    ac.options.ResourceAdmissionObjectSelector,
+			FailurePolicy:     &failurePolicy,
 		}},
 	}
 
@@ -195,7 +263,34 @@ func (ac *ResourceAdmissionController) Register(ctx context.Context, kubeClient
 	return nil
 }
 
-func (ac *ResourceAdmissionController) mutate(ctx context.Context, req *admissionv1beta1.AdmissionRequest) ([]byte, error) {
+// handles returns whether we have a handler for the given kind.
+func (ac *ResourceAdmissionController) handles(kind metav1.GroupVersionKind) bool {
+	_, ok := ac.handler(schema.GroupVersionKind{
+		Group:   kind.Group,
+		Version: kind.Version,
+		Kind:    kind.Kind,
+	})
+	return ok
+}
+
+// handler returns the handler registered for the given kind, preferring
+// an exact match over one for all versions of the kind, over one for
+// all kinds of the group.
+func (ac *ResourceAdmissionController) handler(gvk schema.GroupVersionKind) (GenericCRD, bool) {
+	for _, candidate := range []schema.GroupVersionKind{
+		gvk,
+		{Group: gvk.Group, Version: Wildcard, Kind: gvk.Kind},
+		{Group: gvk.Group, Version: gvk.Version, Kind: Wildcard},
+		{Group: gvk.Group, Version: Wildcard, Kind: Wildcard},
+	} {
+		if h, ok := ac.handlers[candidate]; ok {
+			return h, true
+		}
+	}
+	return nil, false
+}
+
+func (ac *ResourceAdmissionController) mutate(ctx context.Context, req *admissionv1beta1.AdmissionRequest) ([]byte, []string, error) {
 	kind := req.Kind
 	newBytes := req.Object.Raw
 	oldBytes := req.OldObject.Raw
@@ -207,10 +302,10 @@ func (ac *ResourceAdmissionController) mutate(ctx context.Context, req *admissio
 	}
 
 	logger := logging.FromContext(ctx)
-	handler, ok := ac.handlers[gvk]
+	handler, ok := ac.handler(gvk)
 	if !ok {
 		logger.Errorf("Unhandled kind: %v", gvk)
-		return nil, fmt.Errorf("unhandled kind: %v", gvk)
+		return nil, nil, fmt.Errorf("unhandled kind: %v", gvk)
 	}
 
 	// nil values denote absence of `old` (create) or `new` (delete) objects.
@@ -218,24 +313,20 @@ func (ac *ResourceAdmissionController) mutate(ctx context.Context, req *admissio
 
 	if len(newBytes) != 0 {
 		newObj = handler.DeepCopyObject().(GenericCRD)
-		newDecoder := json.NewDecoder(bytes.NewBuffer(newBytes))
-		if ac.disallowUnknownFields {
-			newDecoder.DisallowUnknownFields()
-		}
-		if err := newDecoder.Decode(&newObj); err != nil {
-			return nil, fmt.Errorf("cannot decode incoming new object: %v", err)
+		if err := webhookjson.Decode(newBytes, newObj, ac.disallowUnknownFields); err != nil {
+			return nil, nil, fmt.Errorf("cannot decode incoming new object: %v", err)
 		}
 	}
 	if len(oldBytes) != 0 {
 		oldObj = handler.DeepCopyObject().(GenericCRD)
-		oldDecoder := json.NewDecoder(bytes.NewBuffer(oldBytes))
-		if ac.disallowUnknownFields {
-			oldDecoder.DisallowUnknownFields()
-		}
-		if err := oldDecoder.Decode(&oldObj); err != nil {
-			return nil, fmt.Errorf("cannot decode incoming old object: %v", err)
+		if err := webhookjson.Decode(oldBytes, oldObj, ac.disallowUnknownFields); err != nil {
+			return nil, nil, fmt.Errorf("cannot decode incoming old object: %v", err)
 		}
 	}
+	// Report the deprecated fields newly set by this request, before
+	// defaulting sets any on its behalf.
+	deprecated := DeprecatedFields(newObj, oldObj)
+
 	var patches duck.JSONPatch
 
 	var err error
@@ -246,7 +337,7 @@ func (ac *ResourceAdmissionController) mutate(ctx context.Context, req *admissio
 		// because it expects the round tripped through Golang fields to be present already.
 		rtp, err := roundTripPatch(newBytes, newObj)
 		if err != nil {
-			return nil, fmt.Errorf("cannot create patch for round tripped newBytes: %v", err)
+			return nil, nil, fmt.Errorf("cannot create patch for round tripped newBytes: %v", err)
 		}
 		patches = append(patches, rtp...)
 	}
@@ -278,26 +369,54 @@ func (ac *ResourceAdmissionController) mutate(ctx context.Context, req *admissio
 		logger.Errorw("Failed the resource specific defaulter", zap.Error(err))
 		// Return the error message as-is to give the defaulter callback
 		// discretion over (our portion of) the message that the user sees.
-		return nil, err
+		return nil, nil, err
 	}
 
 	if patches, err = ac.setUserInfoAnnotations(ctx, patches, newObj, req.Resource.Group); err != nil {
 		logger.Errorw("Failed the resource user info annotator", zap.Error(err))
-		return nil, err
+		return nil, nil, err
 	}
 
 	// None of the validators will accept a nil value for newObj.
 	if newObj == nil {
-		return nil, errMissingNewObject
+		return nil, nil, errMissingNewObject
 	}
 	if err := validate(ctx, newObj); err != nil {
 		logger.Errorw("Failed the resource specific validation", zap.Error(err))
 		// Return the error message as-is to give the validation callback
 		// discretion over (our portion of) the message that the user sees.
-		return nil, err
+		return nil, nil, err
+	}
+
+	patchBytes, err := json.Marshal(patches)
+	return patchBytes, deprecated, err
+}
+
+// validateDelete validates the object being deleted within a delete
+// context, so that its Validate may reject the deletion.
+func (ac *ResourceAdmissionController) validateDelete(ctx context.Context, req *admissionv1beta1.AdmissionRequest) error {
+	if len(req.OldObject.Raw) == 0 {
+		// API servers before 1.15 don't send the object being deleted.
+		return nil
+	}
+	gvk := schema.GroupVersionKind{
+		Group:   req.Kind.Group,
+		Version: req.Kind.Version,
+		Kind:    req.Kind.Kind,
+	}
+	handler, ok := ac.handler(gvk)
+	if !ok {
+		logging.FromContext(ctx).Errorf("Unhandled kind: %v", gvk)
+		return fmt.Errorf("unhandled kind: %v", gvk)
 	}
 
-	return json.Marshal(patches)
+	oldObj := handler.DeepCopyObject().(GenericCRD)
+	// Don't disallow unknown fields, which this request doesn't set.
+	if err := webhookjson.Decode(req.OldObject.Raw, oldObj, false); err != nil {
+		return fmt.Errorf("cannot decode incoming old object: %v", err)
+	}
+	ctx = apis.WithUserInfo(apis.WithinDelete(ctx, oldObj), &req.UserInfo)
+	return validate(ctx, oldObj)
 }
 
 func (ac *ResourceAdmissionController) setUserInfoAnnotations(ctx context.Context, patches duck.JSONPatch, new GenericCRD, groupName string) (duck.JSONPatch, error) {
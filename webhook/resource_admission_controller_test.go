@@ -35,6 +35,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	authenticationv1 "k8s.io/api/authentication/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
 	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
@@ -156,7 +157,7 @@ func TestUnknownFieldFails(t *testing.T) {
 	req.Object.Raw = marshaled
 
 	expectFailsWith(t, ac.Admit(TestContextWithLogger(t), req),
-		`mutation failed: cannot decode incoming new object: json: unknown field "foo"`)
+		`mutation failed: cannot decode incoming new object: must not set the field(s): spec.foo`)
 }
 
 func TestAdmitCreates(t *testing.T) {
@@ -425,6 +426,55 @@ func TestAdmitUpdates(t *testing.T) {
 	}
 }
 
+func TestAdmitDeletes(t *testing.T) {
+	tests := []struct {
+		name            string
+		validateDeletes bool
+		setup           func(*Resource)
+		rejection       string
+	}{{
+		name:            "valid resource",
+		validateDeletes: true,
+		setup:           func(r *Resource) {},
+	}, {
+		name:            "invalid resource",
+		validateDeletes: true,
+		setup: func(r *Resource) {
+			r.Spec.FieldWithValidation = "not what's expected"
+		},
+		rejection: "invalid value",
+	}, {
+		name: "invalid resource without validating deletes",
+		setup: func(r *Resource) {
+			r.Spec.FieldWithValidation = "not what's expected"
+		},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			old := createResource("a name")
+			tc.setup(old)
+
+			ctx := apis.WithUserInfo(TestContextWithLogger(t),
+				&authenticationv1.UserInfo{Username: user1})
+			req := createUpdateResource(ctx, old, old)
+			req.Operation = admissionv1beta1.Delete
+			req.Object.Raw = nil
+
+			opts := newDefaultOptions()
+			opts.ValidateDeletes = tc.validateDeletes
+			_, ac := newNonRunningTestResourceAdmissionController(t, opts)
+			resp := ac.Admit(ctx, req)
+
+			if tc.rejection == "" {
+				expectAllowed(t, resp)
+			} else {
+				expectFailsWith(t, resp, tc.rejection)
+			}
+		})
+	}
+}
+
 func createUpdateResource(ctx context.Context, old, new *Resource) *admissionv1beta1.AdmissionRequest {
 	req := &admissionv1beta1.AdmissionRequest{
 		Operation: admissionv1beta1.Update,
@@ -449,6 +499,148 @@ func createUpdateResource(ctx context.Context, old, new *Resource) *admissionv1b
 	return req
 }
 
+type subResourceKey struct{}
+
+// subResourceResource records the subresource visible to validation
+// in the *string attached to the context under subResourceKey.
+type subResourceResource struct {
+	Resource
+}
+
+func (r *subResourceResource) DeepCopyObject() runtime.Object {
+	return &subResourceResource{Resource: *r.Resource.DeepCopy()}
+}
+
+func (r *subResourceResource) Validate(ctx context.Context) *apis.FieldError {
+	if sr, ok := ctx.Value(subResourceKey{}).(*string); ok {
+		*sr = apis.GetSubResource(ctx)
+	}
+	return nil
+}
+
+func (r *subResourceResource) CheckImmutableFields(context.Context, apis.Immutable) *apis.FieldError {
+	return nil
+}
+
+func TestSubResourceAdmission(t *testing.T) {
+	old := &Resource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testResourceName,
+			Namespace: testNamespace,
+		},
+	}
+	old.SetDefaults(context.Background())
+	new := old.DeepCopy()
+
+	tests := []struct {
+		name        string
+		subResource string
+		kind        metav1.GroupVersionKind
+	}{{
+		name: "main resource",
+		kind: metav1.GroupVersionKind{Group: "pkg.knative.dev", Version: "v1alpha1", Kind: "Resource"},
+	}, {
+		name:        "status",
+		subResource: "status",
+		kind:        metav1.GroupVersionKind{Group: "pkg.knative.dev", Version: "v1alpha1", Kind: "Resource"},
+	}, {
+		name:        "scale",
+		subResource: "scale",
+		kind:        metav1.GroupVersionKind{Group: "autoscaling", Version: "v1", Kind: "Scale"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got string
+			ctx := context.WithValue(TestContextWithLogger(t), subResourceKey{}, &got)
+			ctx = apis.WithUserInfo(ctx, &authenticationv1.UserInfo{Username: user1})
+
+			ac := NewResourceAdmissionController(map[schema.GroupVersionKind]GenericCRD{{
+				Group:   "pkg.knative.dev",
+				Version: "v1alpha1",
+				Kind:    "Resource",
+			}: &subResourceResource{}}, newDefaultOptions(), true)
+
+			req := createUpdateResource(ctx, old, new)
+			req.Kind = test.kind
+			req.SubResource = test.subResource
+			resp := ac.Admit(ctx, req)
+			expectAllowed(t, resp)
+
+			if test.subResource == "scale" {
+				if resp.Patch != nil {
+					t.Errorf("Patch = %s, wanted none for scale", resp.Patch)
+				}
+				return
+			}
+			if got != test.subResource {
+				t.Errorf("GetSubResource() = %q, wanted %q", got, test.subResource)
+			}
+		})
+	}
+}
+
+// deprecatedDefaultResource defaults a deprecated field, which should
+// not be reported as set by the request.
+type deprecatedDefaultResource struct {
+	Resource
+
+	OldField string `json:"oldField,omitempty" deprecated:"true"`
+}
+
+func (r *deprecatedDefaultResource) DeepCopyObject() runtime.Object {
+	return &deprecatedDefaultResource{Resource: *r.Resource.DeepCopy(), OldField: r.OldField}
+}
+
+func (r *deprecatedDefaultResource) SetDefaults(ctx context.Context) {
+	r.Resource.SetDefaults(ctx)
+	if r.OldField == "" {
+		r.OldField = "I'm a deprecated default."
+	}
+}
+
+func TestAdmitDeprecatedFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldField string
+		want     string
+	}{{
+		name: "set by defaulting",
+	}, {
+		name:     "set by the request",
+		oldField: "foo",
+		want:     "oldField",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := apis.WithUserInfo(TestContextWithLogger(t), &authenticationv1.UserInfo{Username: user1})
+			ac := NewResourceAdmissionController(map[schema.GroupVersionKind]GenericCRD{{
+				Group:   "pkg.knative.dev",
+				Version: "v1alpha1",
+				Kind:    "Resource",
+			}: &deprecatedDefaultResource{}}, newDefaultOptions(), true)
+
+			r := &deprecatedDefaultResource{
+				Resource: *createResource("a name"),
+				OldField: test.oldField,
+			}
+			req := createCreateResource(ctx, &r.Resource)
+			marshaled, err := json.Marshal(r)
+			if err != nil {
+				t.Fatalf("Marshal() = %v", err)
+			}
+			req.Object.Raw = marshaled
+
+			resp := ac.Admit(ctx, req)
+			expectAllowed(t, resp)
+			if got := resp.AuditAnnotations[DeprecatedFieldsAnnotation]; got != test.want {
+				t.Errorf("Deprecated fields = %q, wanted %q", got, test.want)
+			}
+		})
+	}
+}
+
 func TestValidCreateResourceSucceedsWithRoundTripAndDefaultPatch(t *testing.T) {
 	req := &admissionv1beta1.AdmissionRequest{
 		Operation: admissionv1beta1.Create,
@@ -529,6 +721,118 @@ func TestValidResourceController(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create webhook: %s", err)
 	}
+
+	wh, err := kubeClient.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Get(newDefaultOptions().ResourceMutatingWebhookName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get webhook: %v", err)
+	}
+	for _, rule := range wh.Webhooks[0].Rules {
+		plural := rule.Resources[0]
+		want := []string{plural, plural + "/status", plural + "/scale"}
+		if diff := cmp.Diff(want, rule.Resources); diff != "" {
+			t.Errorf("Resources (-want, +got) = %v", diff)
+		}
+	}
+}
+
+func TestResourceControllerSelectors(t *testing.T) {
+	opts := newDefaultOptions()
+	opts.ResourceAdmissionNamespaceSelector = &metav1.LabelSelector{
+		MatchLabels: map[string]string{"webhooks.knative.dev/enabled": "true"},
+	}
+	opts.ResourceAdmissionObjectSelector = &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{
+			Key:      "webhooks.knative.dev/exclude",
+			Operator: metav1.LabelSelectorOpDoesNotExist,
+		}},
+	}
+	kubeClient, ac := newNonRunningTestResourceAdmissionController(t, opts)
+	createDeployment(kubeClient)
+	if err := ac.Register(TestContextWithLogger(t), kubeClient, []byte{}); err != nil {
+		t.Fatalf("Failed to create webhook: %s", err)
+	}
+
+	got, err := kubeClient.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Get(opts.ResourceMutatingWebhookName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get webhook: %v", err)
+	}
+	if diff := cmp.Diff(opts.ResourceAdmissionNamespaceSelector, got.Webhooks[0].NamespaceSelector); diff != "" {
+		t.Errorf("NamespaceSelector (-want, +got) = %v", diff)
+	}
+	if diff := cmp.Diff(opts.ResourceAdmissionObjectSelector, got.Webhooks[0].ObjectSelector); diff != "" {
+		t.Errorf("ObjectSelector (-want, +got) = %v", diff)
+	}
+}
+
+func TestWildcardHandlers(t *testing.T) {
+	exact, allVersions, allKinds, everything := &Resource{}, &Resource{}, &InnerDefaultResource{}, &InnerDefaultResource{}
+	c := NewResourceAdmissionController(map[schema.GroupVersionKind]GenericCRD{
+		{Group: "pkg.knative.dev", Version: "v1alpha1", Kind: "Resource"}: exact,
+		{Group: "pkg.knative.dev", Version: Wildcard, Kind: "Resource"}:   allVersions,
+		{Group: "pkg.knative.dev", Version: "v1alpha1", Kind: Wildcard}:   allKinds,
+		{Group: "pkg.knative.io", Version: Wildcard, Kind: Wildcard}:      everything,
+	}, newDefaultOptions(), true)
+	ac := c.(*ResourceAdmissionController)
+
+	tests := []struct {
+		name string
+		gvk  schema.GroupVersionKind
+		want GenericCRD
+	}{{
+		name: "exact match",
+		gvk:  schema.GroupVersionKind{Group: "pkg.knative.dev", Version: "v1alpha1", Kind: "Resource"},
+		want: exact,
+	}, {
+		name: "any version of the kind",
+		gvk:  schema.GroupVersionKind{Group: "pkg.knative.dev", Version: "v1beta1", Kind: "Resource"},
+		want: allVersions,
+	}, {
+		name: "any kind of the version",
+		gvk:  schema.GroupVersionKind{Group: "pkg.knative.dev", Version: "v1alpha1", Kind: "Other"},
+		want: allKinds,
+	}, {
+		name: "any kind of the group",
+		gvk:  schema.GroupVersionKind{Group: "pkg.knative.io", Version: "v1", Kind: "Other"},
+		want: everything,
+	}, {
+		name: "unhandled",
+		gvk:  schema.GroupVersionKind{Group: "pkg.knative.dev", Version: "v1beta1", Kind: "Other"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := ac.handler(test.gvk)
+			if ok != (test.want != nil) {
+				t.Fatalf("handler(%v) = %v, wanted handled: %v", test.gvk, ok, test.want != nil)
+			}
+			if got != test.want {
+				t.Errorf("handler(%v) = %p, wanted %p", test.gvk, got, test.want)
+			}
+		})
+	}
+
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	createDeployment(kubeClient)
+	if err := ac.Register(TestContextWithLogger(t), kubeClient, []byte{}); err != nil {
+		t.Fatalf("Failed to create webhook: %s", err)
+	}
+	wh, err := kubeClient.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Get(newDefaultOptions().ResourceMutatingWebhookName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get webhook: %v", err)
+	}
+	var got []string
+	for _, rule := range wh.Webhooks[0].Rules {
+		got = append(got, fmt.Sprintf("%s/%s/%s", rule.APIGroups[0], rule.APIVersions[0], rule.Resources[0]))
+	}
+	want := []string{
+		"pkg.knative.dev/*/resources",
+		"pkg.knative.dev/v1alpha1/*",
+		"pkg.knative.dev/v1alpha1/resources",
+		"pkg.knative.io/*/*",
+	}
+	if diff := cmp.Diff(want, got, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+		t.Errorf("Rules (-want, +got) = %v", diff)
+	}
 }
 
 func TestUpdatingResourceController(t *testing.T) {
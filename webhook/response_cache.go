@@ -0,0 +1,141 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/util/cache"
+
+	"knative.dev/pkg/metrics"
+)
+
+// defaultResponseCacheTTL is how long cached admission responses are
+// served when ControllerOptions.ResponseCacheTTL is not specified.
+const defaultResponseCacheTTL = 30 * time.Second
+
+var responseCacheLookupsM = stats.Int64(
+	"response_cache_lookups",
+	"The number of admission response cache lookups",
+	stats.UnitDimensionless)
+
+// cacheHitKey tags response cache lookups with whether they were served
+// from the cache.
+var cacheHitKey = tag.MustNewKey("cache_hit")
+
+// responseCache memoizes admission responses for identical requests,
+// which are common when the API server retries a call or an informer
+// relists.  Requests are only considered identical when they agree on
+// the kind, operation, subresource, requesting user (with their groups and
+// extra attributes) and the exact bytes of the new and old objects (and
+// with it their resourceVersion).
+type responseCache struct {
+	cache *cache.LRUExpireCache
+	ttl   time.Duration
+}
+
+func newResponseCache(size int, ttl time.Duration) *responseCache {
+	if ttl <= 0 {
+		ttl = defaultResponseCacheTTL
+	}
+	return &responseCache{
+		cache: cache.NewLRUExpireCache(size),
+		ttl:   ttl,
+	}
+}
+
+// responseCacheKey returns the key under which the response to req is
+// cached.
+func responseCacheKey(req *admissionv1beta1.AdmissionRequest) string {
+	h := sha256.New()
+	for _, s := range []string{
+		req.Kind.Group,
+		req.Kind.Version,
+		req.Kind.Kind,
+		string(req.Operation),
+		req.SubResource,
+		req.Namespace,
+		req.Name,
+		req.UserInfo.Username,
+		req.UserInfo.UID,
+	} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	// The groups and extra attributes may grant different permissions.
+	for _, g := range req.UserInfo.Groups {
+		h.Write([]byte(g))
+		h.Write([]byte{0})
+	}
+	h.Write([]byte{0})
+	keys := make([]string, 0, len(req.UserInfo.Extra))
+	for k := range req.UserInfo.Extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write([]byte{0})
+		for _, v := range req.UserInfo.Extra[k] {
+			h.Write([]byte(v))
+			h.Write([]byte{0})
+		}
+		h.Write([]byte{0})
+	}
+	h.Write(req.Object.Raw)
+	h.Write([]byte{0})
+	h.Write(req.OldObject.Raw)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// get returns a copy of the response cached for req, if any.
+func (rc *responseCache) get(ctx context.Context, req *admissionv1beta1.AdmissionRequest) (*admissionv1beta1.AdmissionResponse, bool) {
+	v, ok := rc.cache.Get(responseCacheKey(req))
+	recordCacheLookup(ctx, req, ok)
+	if !ok {
+		return nil, false
+	}
+	// The caller owns the response it is handed (e.g. it sets the UID).
+	return v.(*admissionv1beta1.AdmissionResponse).DeepCopy(), true
+}
+
+// add caches a copy of resp as the response to req.
+func (rc *responseCache) add(req *admissionv1beta1.AdmissionRequest, resp *admissionv1beta1.AdmissionResponse) {
+	rc.cache.Add(responseCacheKey(req), resp.DeepCopy(), rc.ttl)
+}
+
+func recordCacheLookup(ctx context.Context, req *admissionv1beta1.AdmissionRequest, hit bool) {
+	ctx, err := tag.New(
+		ctx,
+		tag.Insert(kindGroupKey, req.Kind.Group),
+		tag.Insert(kindVersionKey, req.Kind.Version),
+		tag.Insert(kindKindKey, req.Kind.Kind),
+		tag.Insert(cacheHitKey, strconv.FormatBool(hit)),
+	)
+	if err != nil {
+		return
+	}
+	metrics.Record(ctx, responseCacheLookupsM.M(1))
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"knative.dev/pkg/apis"
+	. "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/metrics/metricstest"
+	. "knative.dev/pkg/testing"
+)
+
+type validationCountKey struct{}
+
+// countingResource counts its validations in the *int attached to the
+// context under validationCountKey.
+type countingResource struct {
+	Resource
+}
+
+func (r *countingResource) DeepCopyObject() runtime.Object {
+	return &countingResource{Resource: *r.Resource.DeepCopy()}
+}
+
+func (r *countingResource) Validate(ctx context.Context) *apis.FieldError {
+	if n, ok := ctx.Value(validationCountKey{}).(*int); ok {
+		*n++
+	}
+	return nil
+}
+
+func (r *countingResource) CheckImmutableFields(context.Context, apis.Immutable) *apis.FieldError {
+	return nil
+}
+
+func TestResponseCache(t *testing.T) {
+	old := &Resource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            testResourceName,
+			Namespace:       testNamespace,
+			ResourceVersion: "1",
+		},
+	}
+	old.SetDefaults(context.Background())
+	new := old.DeepCopy()
+	changed := old.DeepCopy()
+	changed.Spec.FieldWithValidation = "magic value"
+
+	tests := []struct {
+		name      string
+		cacheSize int
+		updates   []*Resource
+		want      int
+	}{{
+		name:    "disabled",
+		updates: []*Resource{new, new},
+		want:    2,
+	}, {
+		name:      "identical requests",
+		cacheSize: 10,
+		updates:   []*Resource{new, new, new},
+		want:      1,
+	}, {
+		name:      "different objects",
+		cacheSize: 10,
+		updates:   []*Resource{new, changed, new},
+		want:      2,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got int
+			ctx := context.WithValue(TestContextWithLogger(t), validationCountKey{}, &got)
+			ctx = apis.WithUserInfo(ctx, &authenticationv1.UserInfo{Username: user1})
+
+			opts := newDefaultOptions()
+			opts.ResponseCacheSize = test.cacheSize
+			ac := NewResourceAdmissionController(map[schema.GroupVersionKind]GenericCRD{{
+				Group:   "pkg.knative.dev",
+				Version: "v1alpha1",
+				Kind:    "Resource",
+			}: &countingResource{}}, opts, true)
+
+			var first []byte
+			for i, u := range test.updates {
+				resp := ac.Admit(ctx, createUpdateResource(ctx, old, u))
+				expectAllowed(t, resp)
+				if i == 0 {
+					first = resp.Patch
+					// Callers may modify the response they are handed.
+					resp.UID = "mutated"
+				} else if u == test.updates[0] {
+					if diff := cmp.Diff(first, resp.Patch); diff != "" {
+						t.Errorf("Patch (-want, +got) = %v", diff)
+					}
+					if resp.UID != "" {
+						t.Errorf("UID = %q, wanted the cached response to be unaffected", resp.UID)
+					}
+				}
+			}
+			if got != test.want {
+				t.Errorf("Validations = %d, wanted %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestResponseCacheKeyUserInfo(t *testing.T) {
+	base := authenticationv1.UserInfo{
+		Username: user1,
+		Groups:   []string{"system:authenticated"},
+		Extra:    map[string]authenticationv1.ExtraValue{"scopes": {"read"}},
+	}
+	withGroups := *base.DeepCopy()
+	withGroups.Groups = append(withGroups.Groups, "system:masters")
+	withExtra := *base.DeepCopy()
+	withExtra.Extra["scopes"] = authenticationv1.ExtraValue{"read", "write"}
+
+	key := func(ui authenticationv1.UserInfo) string {
+		return responseCacheKey(&admissionv1beta1.AdmissionRequest{
+			Operation: admissionv1beta1.Update,
+			UserInfo:  ui,
+		})
+	}
+	if key(base) != key(*base.DeepCopy()) {
+		t.Error("Identical users got different keys")
+	}
+	if key(base) == key(withGroups) {
+		t.Error("Users with different groups got the same key")
+	}
+	if key(base) == key(withExtra) {
+		t.Error("Users with different extra attributes got the same key")
+	}
+}
+
+func TestResponseCacheMetrics(t *testing.T) {
+	resetMetrics()
+
+	req := &admissionv1beta1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Group: "pkg.knative.dev", Version: "v1alpha1", Kind: "Resource"},
+		Operation: admissionv1beta1.Update,
+	}
+	rc := newResponseCache(10, 0)
+	rc.add(req, &admissionv1beta1.AdmissionResponse{Allowed: true})
+
+	for i := 0; i < 2; i++ {
+		if _, ok := rc.get(context.Background(), req); !ok {
+			t.Fatal("Expected a cached response")
+		}
+	}
+
+	metricstest.CheckCountData(t, "response_cache_lookups", map[string]string{
+		kindGroupKey.Name():   "pkg.knative.dev",
+		kindVersionKey.Name(): "v1alpha1",
+		kindKindKey.Name():    "Resource",
+		cacheHitKey.Name():    "true",
+	}, 2)
+}
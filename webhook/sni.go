@@ -0,0 +1,52 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"crypto/tls"
+	"strings"
+)
+
+// sniCertificates selects the certificate to serve based on the server name
+// the client requested via SNI.  Each server name is backed by its own
+// CertificateSource, so that certificates may be rotated independently of
+// one another.
+type sniCertificates struct {
+	// sources maps lower-cased server names to their certificate source.
+	sources map[string]CertificateSource
+	// fallback serves the server names without a dedicated source.
+	fallback CertificateSource
+}
+
+func newSNICertificates(fallback CertificateSource, sources map[string]CertificateSource) *sniCertificates {
+	lower := make(map[string]CertificateSource, len(sources))
+	for name, source := range sources {
+		lower[strings.ToLower(name)] = source
+	}
+	return &sniCertificates{
+		sources:  lower,
+		fallback: fallback,
+	}
+}
+
+// GetCertificate implements tls.Config.GetCertificate.
+func (s *sniCertificates) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if source, ok := s.sources[strings.ToLower(hello.ServerName)]; ok {
+		return source.Certificate()
+	}
+	return s.fallback.Certificate()
+}
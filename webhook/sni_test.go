@@ -0,0 +1,107 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"crypto/tls"
+	"errors"
+	"testing"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+type staticCertificateSource struct {
+	cert *tls.Certificate
+	err  error
+}
+
+func (s *staticCertificateSource) Certificate() (*tls.Certificate, error) {
+	return s.cert, s.err
+}
+
+func (s *staticCertificateSource) CABundle() ([]byte, error) {
+	return nil, s.err
+}
+
+func TestSNICertificates(t *testing.T) {
+	fallback := &staticCertificateSource{cert: &tls.Certificate{}}
+	conversion := &staticCertificateSource{cert: &tls.Certificate{}}
+	broken := &staticCertificateSource{err: errors.New("broken")}
+
+	sni := newSNICertificates(fallback, map[string]CertificateSource{
+		"Conversion.knative-something.svc": conversion,
+		"broken.knative-something.svc":     broken,
+	})
+
+	tests := []struct {
+		name       string
+		serverName string
+		want       *tls.Certificate
+		wantErr    bool
+	}{{
+		name:       "unknown name uses the fallback",
+		serverName: "webhook.knative-something.svc",
+		want:       fallback.cert,
+	}, {
+		name: "no server name uses the fallback",
+		want: fallback.cert,
+	}, {
+		name:       "names are case insensitive",
+		serverName: "conversion.knative-something.svc",
+		want:       conversion.cert,
+	}, {
+		name:       "errors are propagated",
+		serverName: "broken.knative-something.svc",
+		wantErr:    true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := sni.GetCertificate(&tls.ClientHelloInfo{ServerName: test.serverName})
+			if (err != nil) != test.wantErr {
+				t.Fatalf("GetCertificate() = %v, wanted error: %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("GetCertificate() = %p, wanted %p", got, test.want)
+			}
+		})
+	}
+}
+
+func TestConfigureCertsWithSNI(t *testing.T) {
+	opts := newDefaultOptions()
+	opts.SNISecrets = map[string]string{
+		"conversion.knative-something.svc": "conversion-certs",
+	}
+	kubeClient, ac := newNonRunningTestWebhook(t, opts)
+
+	tlsConfig, _, err := configureCerts(TestContextWithLogger(t), kubeClient, &ac.Options)
+	if err != nil {
+		t.Fatalf("configureCerts() = %v", err)
+	}
+	if len(tlsConfig.Certificates) != 1 {
+		t.Errorf("Expected the default certificate to be set, got %d", len(tlsConfig.Certificates))
+	}
+
+	// The SNI secret doesn't exist, so this should fail.
+	if _, err := tlsConfig.GetCertificate(&tls.ClientHelloInfo{ServerName: "conversion.knative-something.svc"}); err == nil {
+		t.Error("GetCertificate() = nil, wanted error")
+	}
+	if _, err := tlsConfig.GetCertificate(&tls.ClientHelloInfo{ServerName: "webhook.knative-something.svc"}); err != nil {
+		t.Errorf("GetCertificate() = %v", err)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
 	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"knative.dev/pkg/metrics"
 )
 
@@ -43,6 +44,15 @@ var (
 		"The response time in milliseconds",
 		stats.UnitMilliseconds)
 
+	conversionCountM = stats.Int64(
+		"conversion_count",
+		"The number of objects converted by the conversion webhook",
+		stats.UnitDimensionless)
+	conversionLatenciesM = stats.Float64(
+		"conversion_latencies",
+		"The time taken to convert a single object in milliseconds",
+		stats.UnitMilliseconds)
+
 	// Create the tag keys that will be used to add tags to our measurements.
 	// Tag keys must conform to the restrictions described in
 	// go.opencensus.io/tag/validate.go. Currently those restrictions are:
@@ -58,6 +68,9 @@ var (
 	resourceNameKey      = tag.MustNewKey("resource_name")
 	resourceNamespaceKey = tag.MustNewKey("resource_namespace")
 	admissionAllowedKey  = tag.MustNewKey("admission_allowed")
+	fromVersionKey       = tag.MustNewKey("from_version")
+	toVersionKey         = tag.MustNewKey("to_version")
+	conversionSuccessKey = tag.MustNewKey("conversion_success")
 )
 
 func init() {
@@ -111,6 +124,24 @@ func (r *reporter) ReportRequest(req *admissionv1beta1.AdmissionRequest, resp *a
 	return nil
 }
 
+// reportConversion records the conversion of a single object of the
+// given kind between the given versions.
+func reportConversion(gk schema.GroupKind, from, to string, success bool, d time.Duration) {
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(kindGroupKey, gk.Group),
+		tag.Insert(kindKindKey, gk.Kind),
+		tag.Insert(fromVersionKey, from),
+		tag.Insert(toVersionKey, to),
+		tag.Insert(conversionSuccessKey, strconv.FormatBool(success)),
+	)
+	if err != nil {
+		return
+	}
+	metrics.Record(ctx, conversionCountM.M(1))
+	metrics.Record(ctx, conversionLatenciesM.M(float64(d)/float64(time.Millisecond)))
+}
+
 func register() {
 	tagKeys := []tag.Key{
 		requestOperationKey,
@@ -124,6 +155,13 @@ func register() {
 		resourceNameKey,
 		admissionAllowedKey}
 
+	conversionTagKeys := []tag.Key{
+		kindGroupKey,
+		kindKindKey,
+		fromVersionKey,
+		toVersionKey,
+		conversionSuccessKey}
+
 	if err := view.Register(
 		&view.View{
 			Description: requestCountM.Description(),
@@ -137,6 +175,36 @@ func register() {
 			Aggregation: view.Distribution(metrics.Buckets125(1, 100000)...), // [1 2 5 10 20 50 100 200 500 1000 2000 5000 10000 20000 50000 100000]ms
 			TagKeys:     tagKeys,
 		},
+		&view.View{
+			Description: responseCacheLookupsM.Description(),
+			Measure:     responseCacheLookupsM,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{kindGroupKey, kindVersionKey, kindKindKey, cacheHitKey},
+		},
+		&view.View{
+			Description: conversionCountM.Description(),
+			Measure:     conversionCountM,
+			Aggregation: view.Count(),
+			TagKeys:     conversionTagKeys,
+		},
+		&view.View{
+			Description: conversionLatenciesM.Description(),
+			Measure:     conversionLatenciesM,
+			Aggregation: view.Distribution(metrics.Buckets125(1, 100000)...),
+			TagKeys:     conversionTagKeys,
+		},
+		&view.View{
+			Description: requestShedCountM.Description(),
+			Measure:     requestShedCountM,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{admissionPathKey},
+		},
+		&view.View{
+			Description: requestBodyTooLargeCountM.Description(),
+			Measure:     requestBodyTooLargeCountM,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{admissionPathKey},
+		},
 	); err != nil {
 		panic(err)
 	}
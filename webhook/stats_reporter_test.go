@@ -71,6 +71,7 @@ func setup() {
 
 // opencensus metrics carry global state that need to be reset between unit tests
 func resetMetrics() {
-	metricstest.Unregister(requestCountName, requestLatenciesName)
+	metricstest.Unregister(requestCountName, requestLatenciesName, "response_cache_lookups", "request_shed_count",
+		"conversion_count", "conversion_latencies", "request_body_too_large_count")
 	register()
 }
@@ -17,15 +17,21 @@ limitations under the License.
 package webhook
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"go.opencensus.io/trace"
 	"go.uber.org/zap"
 
 	"knative.dev/pkg/logging"
@@ -34,8 +40,11 @@ import (
 	admissionv1beta1 "k8s.io/api/admission/v1beta1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apixv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -45,6 +54,10 @@ const (
 	secretCACert     = "ca-cert.pem"
 )
 
+// defaultTLSMinVersion is used when ControllerOptions.TLSMinVersion
+// is not specified.
+const defaultTLSMinVersion = tls.VersionTLS12
+
 var (
 	deploymentKind      = appsv1.SchemeGroupVersion.WithKind("Deployment")
 	errMissingNewObject = errors.New("the new object may not be nil")
@@ -76,6 +89,26 @@ type ControllerOptions struct {
 	// Namespace is the namespace in which everything above lives.
 	Namespace string
 
+	// CertificateSource provides the certificate served by the webhook and
+	// the CA bundle registered with the API server.  When unset, the
+	// certificates are read from (or generated into) the secret named by
+	// SecretName.
+	CertificateSource CertificateSource
+
+	// SNISecrets maps server names to the names of secrets (in Namespace)
+	// holding the server key/cert to present to clients that request that
+	// server name via SNI, e.g. when the conversion webhook is reached
+	// through a different Service than admission.  These secrets are
+	// managed externally and are re-read periodically, so that they may be
+	// rotated independently.  Other server names are served with the
+	// certificate from CertificateSource.
+	SNISecrets map[string]string
+
+	// SNICertificateSources is like SNISecrets, but allows any
+	// CertificateSource to back a server name.  Entries here take
+	// precedence over SNISecrets.
+	SNICertificateSources map[string]CertificateSource
+
 	// Port where the webhook is served. Per k8s admission
 	// registration requirements this should be 443 unless there is
 	// only a single port for the service.
@@ -87,11 +120,46 @@ type ControllerOptions struct {
 	// invokes the webhook before the HTTP server is started.
 	RegistrationDelay time.Duration
 
+	// DrainDuration is how long the webhook keeps serving requests once
+	// it has been asked to stop (or PreStopPath was hit), to give the
+	// endpoint time to be removed from the Service before the server is
+	// shut down.  Zero closes the server immediately.
+	DrainDuration time.Duration
+
+	// ReadinessProbePath, when set, is served as a readiness probe.
+	ReadinessProbePath string
+
+	// FailReadinessBeforeDrain makes the readiness probe fail once
+	// draining has started, which speeds up the endpoint's removal.
+	FailReadinessBeforeDrain bool
+
+	// PreStopPath, when set, is served for a preStop httpGet hook.  It
+	// starts draining and returns once DrainDuration has elapsed.
+	PreStopPath string
+
 	// ClientAuthType declares the policy the webhook server will follow for
 	// TLS Client Authentication.
 	// The default value is tls.NoClientCert.
 	ClientAuth tls.ClientAuthType
 
+	// ClientCACert holds the PEM encoded CA certificates that client
+	// certificates are verified against when ClientAuth calls for it.
+	// When empty, the API server's requestheader-client-ca-file from the
+	// extension-apiserver-authentication ConfigMap is used.
+	ClientCACert []byte
+
+	// TLSMinVersion is the minimum TLS version the webhook server accepts.
+	// Defaults to TLS 1.2.
+	TLSMinVersion uint16
+
+	// TLSCipherSuites restricts the cipher suites offered for TLS 1.2 and
+	// below.  Left empty, Go's default suites are used.
+	TLSCipherSuites []uint16
+
+	// TLSCurvePreferences restricts the elliptic curves used during the
+	// TLS handshake.  Left empty, Go's default curves are used.
+	TLSCurvePreferences []tls.CurveID
+
 	// StatsReporter reports metrics about the webhook.
 	// This will be automatically initialized by the constructor if left uninitialized.
 	StatsReporter StatsReporter
@@ -106,6 +174,68 @@ type ControllerOptions struct {
 
 	// NamespaceLabel is the label for the Namespace we bind ConfigValidationController to
 	ConfigValidationNamespaceLabel string
+
+	// ResponseCacheSize is the number of responses the
+	// ResourceAdmissionController remembers for repeated identical
+	// requests, which then skip defaulting and validation.  Zero disables
+	// the cache.  Cached responses are not invalidated when configuration
+	// changes, so only enable it when defaulting and validation depend on
+	// nothing but the request, e.g. not on ConfigMaps attached to the
+	// context by Webhook.WithContext.
+	ResponseCacheSize int
+
+	// ResponseCacheTTL is how long cached responses are served for.
+	// Defaults to 30s when ResponseCacheSize is set.
+	ResponseCacheTTL time.Duration
+
+	// ValidateDeletes has the ResourceAdmissionController intercept DELETE
+	// requests and validate the object being deleted within
+	// apis.WithinDelete.  The Validate of every handled type must then
+	// check apis.IsInDelete, lest it block the deletion of objects whose
+	// spec has become invalid.
+	ValidateDeletes bool
+
+	// ConcurrencyLimits bounds the concurrent requests handled by the
+	// admission controllers, keyed by their path, so that a slow
+	// controller cannot starve the others sharing this server.
+	ConcurrencyLimits map[string]ConcurrencyLimit
+
+	// MaxRequestBodyBytes bounds the size of the admission and conversion
+	// requests the webhook accepts, larger ones are rejected with a 413.
+	// Zero means unlimited.
+	MaxRequestBodyBytes int64
+
+	// ResourceAdmissionNamespaceSelector and ResourceAdmissionObjectSelector
+	// are written into the webhook configuration of the
+	// ResourceAdmissionController to restrict the namespaces and objects
+	// it is invoked for.  Left nil, the webhook applies to everything.
+	ResourceAdmissionNamespaceSelector *metav1.LabelSelector
+	ResourceAdmissionObjectSelector    *metav1.LabelSelector
+
+	// ConfigValidationNamespaceSelector overrides the namespace selector
+	// derived from ConfigValidationNamespaceLabel for the
+	// ConfigValidationController's webhook configuration.
+	ConfigValidationNamespaceSelector *metav1.LabelSelector
+
+	// ConfigValidationObjectSelector is written into the webhook
+	// configuration of the ConfigValidationController to restrict the
+	// ConfigMaps it is invoked for.
+	ConfigValidationObjectSelector *metav1.LabelSelector
+
+	// ConversionCRDSelector selects the CustomResourceDefinitions whose
+	// conversion webhook caBundle is kept in sync with our CA certificate,
+	// including those created or labeled to match it later.  Only CRDs
+	// using the Webhook conversion strategy are patched.  This requires
+	// ConversionCRDClient to be set.
+	ConversionCRDSelector labels.Selector
+
+	// ConversionCRDClient is the client with which the CRDs selected by
+	// ConversionCRDSelector are watched and patched.
+	ConversionCRDClient apiextensionsclientset.Interface
+
+	// RequestLogging, when set, logs the requests served by the webhook
+	// with logging.NewRequestLogHandler, instead of dumping each request.
+	RequestLogging *logging.RequestLogOptions
 }
 
 // AdmissionController provides the interface for different admission controllers
@@ -117,10 +247,22 @@ type AdmissionController interface {
 // Webhook implements the external webhook for validation of
 // resources and configuration.
 type Webhook struct {
-	Client               kubernetes.Interface
+	Client kubernetes.Interface
+
+	// ConversionControllers are the conversion webhooks served, keyed
+	// by their path.
+	ConversionControllers map[string]ConversionController
+
 	Options              ControllerOptions
 	Logger               *zap.SugaredLogger
 	admissionControllers map[string]AdmissionController
+	limiters             map[string]*limiter
+
+	drainOnce sync.Once
+	draining  int32
+
+	// caBundle holds the CA bundle last registered with the API server.
+	caBundle atomic.Value
 
 	WithContext func(context.Context) context.Context
 }
@@ -134,6 +276,9 @@ func New(
 	ctx func(context.Context) context.Context,
 ) (*Webhook, error) {
 
+	if err := validateConversionOptions(opts); err != nil {
+		return nil, err
+	}
 	if opts.StatsReporter == nil {
 		reporter, err := NewStatsReporter()
 		if err != nil {
@@ -142,27 +287,57 @@ func New(
 		opts.StatsReporter = reporter
 	}
 
+	limiters := make(map[string]*limiter, len(opts.ConcurrencyLimits))
+	for path, limit := range opts.ConcurrencyLimits {
+		if l := newLimiter(limit); l != nil {
+			limiters[path] = l
+		}
+	}
+
 	return &Webhook{
 		Client:               client,
 		Options:              opts,
 		admissionControllers: admissionControllers,
+		limiters:             limiters,
 		Logger:               logger,
 		WithContext:          ctx,
 	}, nil
 }
 
+// validateConversionOptions checks that the CRDs selected for caBundle
+// patching can be patched.
+func validateConversionOptions(opts ControllerOptions) error {
+	if opts.ConversionCRDSelector != nil && opts.ConversionCRDClient == nil {
+		return errors.New("ConversionCRDSelector requires ConversionCRDClient to be set")
+	}
+	return nil
+}
+
 // Run implements the admission controller run loop.
 func (ac *Webhook) Run(stop <-chan struct{}) error {
 	logger := ac.Logger
 	ctx := logging.WithLogger(context.TODO(), logger)
-	tlsConfig, caCert, err := configureCerts(ctx, ac.Client, &ac.Options)
+	if err := validateConversionOptions(ac.Options); err != nil {
+		logger.Errorw("invalid conversion webhook options", zap.Error(err))
+		return err
+	}
+	tlsConfig, source, err := configureCerts(ctx, ac.Client, &ac.Options)
+	if err != nil {
+		logger.Errorw("could not configure admission webhook certs", zap.Error(err))
+		return err
+	}
+	caCert, err := source.CABundle()
 	if err != nil {
 		logger.Errorw("could not configure admission webhook certs", zap.Error(err))
 		return err
 	}
 
+	var handler http.Handler = ac
+	if ac.Options.RequestLogging != nil {
+		handler = logging.NewRequestLogHandler(ac, logger, *ac.Options.RequestLogging)
+	}
 	server := &http.Server{
-		Handler:   ac,
+		Handler:   handler,
 		Addr:      fmt.Sprintf(":%v", ac.Options.Port),
 		TLSConfig: tlsConfig,
 	}
@@ -174,16 +349,17 @@ func (ac *Webhook) Run(stop <-chan struct{}) error {
 
 	select {
 	case <-time.After(ac.Options.RegistrationDelay):
-		for _, c := range ac.admissionControllers {
-			if err := c.Register(ctx, ac.Client, caCert); err != nil {
-				logger.Errorw("failed to register webhook", zap.Error(err))
-				return err
-			}
+		if err := ac.register(ctx, caCert); err != nil {
+			return err
 		}
 		logger.Info("Successfully registered webhook")
 	case <-stop:
 		return nil
 	}
+	go ac.watchCABundle(ctx, source, caCert, stop)
+	if ac.Options.ConversionCRDSelector != nil {
+		ac.watchConversionCRDs(ctx, stop)
+	}
 
 	serverBootstrapErrCh := make(chan struct{})
 	go func() {
@@ -195,18 +371,78 @@ func (ac *Webhook) Run(stop <-chan struct{}) error {
 
 	select {
 	case <-stop:
-		return server.Close()
+		return ac.shutdown(server)
 	case <-serverBootstrapErrCh:
 		return errors.New("webhook server bootstrap failed")
 	}
 }
 
+// register registers the admission controllers and patches the caBundles
+// of the conversion webhooks with caCert.
+func (ac *Webhook) register(ctx context.Context, caCert []byte) error {
+	logger := logging.FromContext(ctx)
+	for _, c := range ac.admissionControllers {
+		if err := c.Register(ctx, ac.Client, caCert); err != nil {
+			logger.Errorw("failed to register webhook", zap.Error(err))
+			return err
+		}
+	}
+	ac.caBundle.Store(caCert)
+	if ac.Options.ConversionCRDSelector != nil {
+		if err := PatchConversionCABundles(ctx, ac.Options.ConversionCRDClient, ac.Options.ConversionCRDSelector, caCert); err != nil {
+			logger.Errorw("failed to patch conversion webhook caBundles", zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// caBundleCheckInterval is how often the CA bundle of the certificate
+// source is checked for rotations.
+var caBundleCheckInterval = time.Minute
+
+// watchCABundle registers the webhook again whenever the CA bundle of the
+// source changes from caCert, e.g. when the CA is rotated, until stop is
+// closed.
+func (ac *Webhook) watchCABundle(ctx context.Context, source CertificateSource, caCert []byte, stop <-chan struct{}) {
+	logger := logging.FromContext(ctx)
+	ticker := time.NewTicker(caBundleCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+
+		latest, err := source.CABundle()
+		if err != nil {
+			logger.Errorw("failed to fetch the CA bundle", zap.Error(err))
+			continue
+		}
+		if bytes.Equal(latest, caCert) {
+			continue
+		}
+		logger.Info("The CA bundle changed, registering webhook again")
+		// On failure, retry with the next check.
+		if err := ac.register(ctx, latest); err == nil {
+			caCert = latest
+		}
+	}
+}
+
 // ServeHTTP implements the external admission webhook for mutating
 // serving resources.
 func (ac *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	var ttStart = time.Now()
 	logger := ac.Logger
-	logger.Infof("Webhook ServeHTTP request=%#v", r)
+	if ac.Options.RequestLogging == nil {
+		logger.Infof("Webhook ServeHTTP request=%#v", r)
+	}
+
+	if ac.serveProbe(w, r) {
+		return
+	}
 
 	// Verify the content type is accurate.
 	contentType := r.Header.Get("Content-Type")
@@ -215,6 +451,24 @@ func (ac *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !ac.limitBody(w, r) {
+		return
+	}
+
+	if l, ok := ac.limiters[r.URL.Path]; ok {
+		if !l.acquire(r.Context()) {
+			recordShed(r.URL.Path)
+			http.Error(w, fmt.Sprintf("too many requests for: %s", r.URL.Path), http.StatusTooManyRequests)
+			return
+		}
+		defer l.release()
+	}
+
+	if cc, ok := ac.ConversionControllers[r.URL.Path]; ok {
+		ac.serveConversion(w, r, cc)
+		return
+	}
+
 	var review admissionv1beta1.AdmissionReview
 	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
 		http.Error(w, fmt.Sprintf("could not decode body: %v", err), http.StatusBadRequest)
@@ -241,7 +495,10 @@ func (ac *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	c := ac.admissionControllers[r.URL.Path]
+	ctx, span := trace.StartSpan(ctx, "admission"+r.URL.Path)
+	ctx = logging.WithTrace(ctx)
 	reviewResponse := c.Admit(ctx, review.Request)
+	span.End()
 	var response admissionv1beta1.AdmissionReview
 	if reviewResponse != nil {
 		response.Response = reviewResponse
@@ -262,6 +519,65 @@ func (ac *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// limitBody enforces ControllerOptions.MaxRequestBodyBytes, replying with
+// a 413 and returning false when the request body exceeds it.
+func (ac *Webhook) limitBody(w http.ResponseWriter, r *http.Request) bool {
+	limit := ac.Options.MaxRequestBodyBytes
+	if limit <= 0 {
+		return true
+	}
+	tooLarge := func() bool {
+		recordBodyTooLarge(r.URL.Path)
+		http.Error(w, fmt.Sprintf("request body exceeds %d bytes", limit), http.StatusRequestEntityTooLarge)
+		return false
+	}
+
+	if r.ContentLength > limit {
+		return tooLarge()
+	}
+	// The Content-Length may be absent (or lie), so read at most one byte
+	// past the limit to find out.
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, limit+1))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("could not read body: %v", err), http.StatusBadRequest)
+		return false
+	}
+	if int64(len(body)) > limit {
+		return tooLarge()
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return true
+}
+
+// serveConversion handles a ConversionReview using the given controller.
+func (ac *Webhook) serveConversion(w http.ResponseWriter, r *http.Request, cc ConversionController) {
+	var review apixv1beta1.ConversionReview
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		http.Error(w, fmt.Sprintf("could not decode body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "conversion review has no request", http.StatusBadRequest)
+		return
+	}
+
+	logger := ac.Logger.With(
+		zap.String(logkey.DesiredAPIVersion, review.Request.DesiredAPIVersion))
+	ctx := logging.WithLogger(r.Context(), logger)
+	if ac.WithContext != nil {
+		ctx = ac.WithContext(ctx)
+	}
+
+	response := apixv1beta1.ConversionReview{
+		TypeMeta: review.TypeMeta,
+		Response: cc.Convert(ctx, review.Request),
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, fmt.Sprintf("could encode response: %v", err), http.StatusInternalServerError)
+		return
+	}
+}
+
 // GetAPIServerExtensionCACert gets the Kubernetes aggregate apiserver
 // client CA cert used by validator.
 //
@@ -282,62 +598,31 @@ func getAPIServerExtensionCACert(cl kubernetes.Interface) ([]byte, error) {
 }
 
 // MakeTLSConfig makes a TLS configuration suitable for use with the server
-func makeTLSConfig(serverCert, serverKey, caCert []byte, clientAuthType tls.ClientAuthType) (*tls.Config, error) {
+func makeTLSConfig(source CertificateSource, sni map[string]CertificateSource, caCert []byte, options *ControllerOptions) (*tls.Config, error) {
 	caCertPool := x509.NewCertPool()
 	caCertPool.AppendCertsFromPEM(caCert)
-	cert, err := tls.X509KeyPair(serverCert, serverKey)
+	cert, err := source.Certificate()
 	if err != nil {
 		return nil, err
 	}
+	minVersion := options.TLSMinVersion
+	if minVersion == 0 {
+		minVersion = defaultTLSMinVersion
+	}
 	return &tls.Config{
-		Certificates: []tls.Certificate{cert},
-		ClientCAs:    caCertPool,
-		ClientAuth:   clientAuthType,
+		Certificates:     []tls.Certificate{*cert},
+		GetCertificate:   newSNICertificates(source, sni).GetCertificate,
+		ClientCAs:        caCertPool,
+		ClientAuth:       options.ClientAuth,
+		MinVersion:       minVersion,
+		CipherSuites:     options.TLSCipherSuites,
+		CurvePreferences: options.TLSCurvePreferences,
 	}, nil
 }
 
-func getOrGenerateKeyCertsFromSecret(ctx context.Context, client kubernetes.Interface,
-	options *ControllerOptions) (serverKey, serverCert, caCert []byte, err error) {
-	logger := logging.FromContext(ctx)
-	secret, err := client.CoreV1().Secrets(options.Namespace).Get(options.SecretName, metav1.GetOptions{})
-	if err != nil {
-		if !apierrors.IsNotFound(err) {
-			return nil, nil, nil, err
-		}
-		logger.Info("Did not find existing secret, creating one")
-		newSecret, err := generateSecret(ctx, options)
-		if err != nil {
-			return nil, nil, nil, err
-		}
-		secret, err = client.CoreV1().Secrets(newSecret.Namespace).Create(newSecret)
-		if err != nil {
-			if !apierrors.IsAlreadyExists(err) {
-				return nil, nil, nil, err
-			}
-			// OK, so something else might have created, try fetching it instead.
-			secret, err = client.CoreV1().Secrets(options.Namespace).Get(options.SecretName, metav1.GetOptions{})
-			if err != nil {
-				return nil, nil, nil, err
-			}
-		}
-	}
-
-	var ok bool
-	if serverKey, ok = secret.Data[secretServerKey]; !ok {
-		return nil, nil, nil, errors.New("server key missing")
-	}
-	if serverCert, ok = secret.Data[secretServerCert]; !ok {
-		return nil, nil, nil, errors.New("server cert missing")
-	}
-	if caCert, ok = secret.Data[secretCACert]; !ok {
-		return nil, nil, nil, errors.New("ca cert missing")
-	}
-	return serverKey, serverCert, caCert, nil
-}
-
-func configureCerts(ctx context.Context, client kubernetes.Interface, options *ControllerOptions) (*tls.Config, []byte, error) {
-	var apiServerCACert []byte
-	if options.ClientAuth >= tls.VerifyClientCertIfGiven {
+func configureCerts(ctx context.Context, client kubernetes.Interface, options *ControllerOptions) (*tls.Config, CertificateSource, error) {
+	apiServerCACert := options.ClientCACert
+	if options.ClientAuth >= tls.VerifyClientCertIfGiven && len(apiServerCACert) == 0 {
 		var err error
 		apiServerCACert, err = getAPIServerExtensionCACert(client)
 		if err != nil {
@@ -345,15 +630,23 @@ func configureCerts(ctx context.Context, client kubernetes.Interface, options *C
 		}
 	}
 
-	serverKey, serverCert, caCert, err := getOrGenerateKeyCertsFromSecret(ctx, client, options)
-	if err != nil {
-		return nil, nil, err
+	source := options.CertificateSource
+	if source == nil {
+		source = NewSecretCertificateSource(ctx, client, options.Namespace, options.SecretName, options.ServiceName)
 	}
-	tlsConfig, err := makeTLSConfig(serverCert, serverKey, apiServerCACert, options.ClientAuth)
+	sni := make(map[string]CertificateSource, len(options.SNISecrets)+len(options.SNICertificateSources))
+	for name, secret := range options.SNISecrets {
+		sni[name] = newSecretCertificateSource(client, options.Namespace, secret, secretServerKey, secretServerCert, secretCACert)
+	}
+	for name, s := range options.SNICertificateSources {
+		sni[name] = s
+	}
+
+	tlsConfig, err := makeTLSConfig(source, sni, apiServerCACert, options)
 	if err != nil {
 		return nil, nil, err
 	}
-	return tlsConfig, caCert, nil
+	return tlsConfig, source, nil
 }
 
 func makeErrorStatus(reason string, args ...interface{}) *admissionv1beta1.AdmissionResponse {
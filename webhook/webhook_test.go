@@ -18,6 +18,7 @@ package webhook
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/pem"
 	"fmt"
 	"net"
@@ -194,10 +195,14 @@ func TestCertConfigurationForAlreadyGeneratedSecret(t *testing.T) {
 	createNamespace(t, kubeClient, metav1.NamespaceSystem)
 	createTestConfigMap(t, kubeClient)
 
-	tlsConfig, caCert, err := configureCerts(ctx, kubeClient, &ac.Options)
+	tlsConfig, source, err := configureCerts(ctx, kubeClient, &ac.Options)
 	if err != nil {
 		t.Fatalf("Failed to configure secret: %v", err)
 	}
+	caCert, err := source.CABundle()
+	if err != nil {
+		t.Fatalf("CABundle() = %v", err)
+	}
 	expectedCert, err := tls.X509KeyPair(newSecret.Data[secretServerCert], newSecret.Data[secretServerKey])
 	if err != nil {
 		t.Fatalf("Failed to create cert from x509 key pair: %v", err)
@@ -230,10 +235,14 @@ func TestCertConfigurationForGeneratedSecret(t *testing.T) {
 	createNamespace(t, kubeClient, metav1.NamespaceSystem)
 	createTestConfigMap(t, kubeClient)
 
-	tlsConfig, caCert, err := configureCerts(ctx, kubeClient, &ac.Options)
+	tlsConfig, source, err := configureCerts(ctx, kubeClient, &ac.Options)
 	if err != nil {
 		t.Fatalf("Failed to configure certificates: %v", err)
 	}
+	caCert, err := source.CABundle()
+	if err != nil {
+		t.Fatalf("CABundle() = %v", err)
+	}
 
 	if tlsConfig == nil {
 		t.Fatal("Expected TLS config not to be nil")
@@ -251,6 +260,85 @@ func TestCertConfigurationForGeneratedSecret(t *testing.T) {
 	}
 }
 
+func TestCertConfigurationWithClientCACert(t *testing.T) {
+	ctx := TestContextWithLogger(t)
+	_, _, clientCA, err := CreateCerts(ctx, "apiserver", "kube-system")
+	if err != nil {
+		t.Fatalf("Failed to create certs: %v", err)
+	}
+
+	opts := newDefaultOptions()
+	opts.ClientAuth = tls.RequireAndVerifyClientCert
+	opts.ClientCACert = clientCA
+	kubeClient, ac := newNonRunningTestWebhook(t, opts)
+
+	// Note that we don't create the extension-apiserver-authentication
+	// ConfigMap, so this would fail if it were consulted.
+	tlsConfig, _, err := configureCerts(ctx, kubeClient, &ac.Options)
+	if err != nil {
+		t.Fatalf("Failed to configure certificates: %v", err)
+	}
+	if tlsConfig.ClientAuth != tls.RequireAndVerifyClientCert {
+		t.Errorf("ClientAuth = %v, wanted %v", tlsConfig.ClientAuth, tls.RequireAndVerifyClientCert)
+	}
+
+	p, _ := pem.Decode(clientCA)
+	ca, err := x509.ParseCertificate(p.Bytes)
+	if err != nil {
+		t.Fatalf("Failed to parse CA certificate: %v", err)
+	}
+	if diff := cmp.Diff([][]byte{ca.RawSubject}, tlsConfig.ClientCAs.Subjects()); diff != "" {
+		t.Errorf("ClientCAs.Subjects() (-want, +got) = %v", diff)
+	}
+}
+
+func TestTLSConfiguration(t *testing.T) {
+	tests := []struct {
+		name           string
+		minVersion     uint16
+		cipherSuites   []uint16
+		curves         []tls.CurveID
+		wantMinVersion uint16
+		wantSuites     []uint16
+		wantCurves     []tls.CurveID
+	}{{
+		name:           "defaults",
+		wantMinVersion: tls.VersionTLS12,
+	}, {
+		name:           "restricted",
+		minVersion:     tls.VersionTLS13,
+		cipherSuites:   []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384},
+		curves:         []tls.CurveID{tls.CurveP256, tls.CurveP384},
+		wantMinVersion: tls.VersionTLS13,
+		wantSuites:     []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384},
+		wantCurves:     []tls.CurveID{tls.CurveP256, tls.CurveP384},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := newDefaultOptions()
+			opts.TLSMinVersion = test.minVersion
+			opts.TLSCipherSuites = test.cipherSuites
+			opts.TLSCurvePreferences = test.curves
+			kubeClient, ac := newNonRunningTestWebhook(t, opts)
+
+			tlsConfig, _, err := configureCerts(TestContextWithLogger(t), kubeClient, &ac.Options)
+			if err != nil {
+				t.Fatalf("Failed to configure certificates: %v", err)
+			}
+			if tlsConfig.MinVersion != test.wantMinVersion {
+				t.Errorf("MinVersion = %x, wanted %x", tlsConfig.MinVersion, test.wantMinVersion)
+			}
+			if diff := cmp.Diff(test.wantSuites, tlsConfig.CipherSuites); diff != "" {
+				t.Errorf("CipherSuites (-want, +got) = %v", diff)
+			}
+			if diff := cmp.Diff(test.wantCurves, tlsConfig.CurvePreferences); diff != "" {
+				t.Errorf("CurvePreferences (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
 func TestSettingWebhookClientAuth(t *testing.T) {
 	opts := newDefaultOptions()
 	if opts.ClientAuth != tls.NoClientCert {